@@ -1,6 +1,6 @@
 module github.com/Rehtt/Kit
 
-go 1.22
+go 1.23
 
 require (
 	golang.org/x/sys v0.0.0-20220722155257-8c9f86f7a55f
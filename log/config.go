@@ -1,6 +1,9 @@
 package log
 
 import (
+	"io"
+	"os"
+
 	"github.com/Rehtt/Kit/vt/color"
 )
 
@@ -12,20 +15,68 @@ const (
 	WARN
 	FATAL
 	PANIC
+	// ERROR 排在 WARN 与 FATAL 之间，为保持已有级别的取值不变放在最后
+	ERROR
 )
 
+// severity 返回级别的严重程度，比较级别时使用它而不是 Level 的取值。
+func (l Level) severity() int {
+	switch l {
+	case ERROR:
+		return 3
+	case FATAL, PANIC:
+		return int(l) + 1
+	}
+	return int(l)
+}
+
+// enabled 最低级别为 min 时 l 是否输出。
+func (l Level) enabled(min Level) bool {
+	return l.severity() >= min.severity()
+}
+
 var LevelStr = map[Level]string{
 	DEBUG: "debug",
 	INFO:  "info",
 	WARN:  "warn",
+	ERROR: "error",
 	FATAL: "fatal",
 	PANIC: "panic",
 }
 
+// Format 输出格式
+type Format int
+
+const (
+	// TextFormat 带颜色的文本：时间 [级别] 消息 key=value
+	TextFormat = Format(iota)
+	// JSONFormat 每行一个 JSON 对象
+	JSONFormat
+	// LogfmtFormat 每行一组 key=value
+	LogfmtFormat
+)
+
+// Config 日志配置。Level、Format、时间与颜色相关字段在每次输出时读取，可以随时修改；
+// InfoOutFile、ErrorOutFile、Rotate、Output、Sinks、Async、AsyncBuffer 只在 NewLog 时读取，
+// 之后修改不会生效，需要切换输出目标时请创建新的 Log（默认 Logger 使用 logs.Apply）。
 type Config struct {
-	InfoOutFile  string
+	// DEBUG~WARN 级别的输出文件，ErrorOutFile 为空时所有级别都写入该文件
+	InfoOutFile string
+	// ERROR 及以上级别的输出文件
 	ErrorOutFile string
-	Level        Level
+	// InfoOutFile、ErrorOutFile 的切割与保留配置
+	Rotate FileOption
+	Level  Level
+	// 输出格式，默认：TextFormat
+	Format Format
+	// 输出目标，未设置 InfoOutFile、ErrorOutFile、Sinks 时默认：os.Stdout
+	Output io.Writer
+	// 额外的输出目标及其级别路由
+	Sinks []Sink
+	// 异步写入，Fatal、Panic、Flush、Close 时等待队列写完
+	Async bool
+	// 异步队列长度，队列满时阻塞，默认：1024
+	AsyncBuffer int
 	// 不显示时间
 	NotShowTime bool
 	// 时间格式，默认：YYYY-MM-DD hh:mm:ss
@@ -39,6 +90,8 @@ type Config struct {
 	InfoColor color.Colors
 	// Warn 颜色，默认：FgYellow
 	WarnColor color.Colors
+	// Error 颜色，默认：FgMagenta
+	ErrorColor color.Colors
 	// Fatal 颜色，默认：FgRed
 	FatalColor color.Colors
 	// Panic 颜色，默认：FgHiRed
@@ -60,6 +113,9 @@ func (c *Config) Apply(config *Config) {
 }
 
 func (c *Config) init() {
+	if c.Output == nil && c.InfoOutFile == "" && c.ErrorOutFile == "" && len(c.Sinks) == 0 {
+		c.Output = os.Stdout
+	}
 	if !c.NotShowTime && c.TimeLayout == "" {
 		c.TimeLayout = "2006-01-02 15:04:05"
 	}
@@ -70,6 +126,9 @@ func (c *Config) init() {
 		if !c.WarnColor.HasColors() {
 			c.WarnColor = color.NewColors(color.FgYellow)
 		}
+		if !c.ErrorColor.HasColors() {
+			c.ErrorColor = color.NewColors(color.FgMagenta)
+		}
 		if !c.FatalColor.HasColors() {
 			c.FatalColor = color.NewColors(color.FgRed)
 		}
@@ -0,0 +1,212 @@
+package log
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Rehtt/Kit/buf"
+)
+
+// machineTimeLayout JSON、logfmt 使用的时间格式，便于日志系统解析。
+const machineTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// encode 编码一条日志，color 只对 TextFormat 生效。
+func (l Log) encode(level Level, t time.Time, msg string, attrs []slog.Attr, color bool) []byte {
+	switch l.Format {
+	case JSONFormat:
+		return encodeJSON(level, t, msg, attrs)
+	case LogfmtFormat:
+		return encodeLogfmt(level, t, msg, attrs)
+	}
+	return []byte(l.encodeText(level, t, msg, attrs, color) + "\n")
+}
+
+func (l Log) encodeText(level Level, t time.Time, msg string, attrs []slog.Attr, color bool) string {
+	var tmp = buf.NewBuf()
+	if !t.IsZero() {
+		tmp.WriteString(t.Format(l.TimeLayout))
+	}
+	tmp.WriteString(" [")
+	tmp.WriteString(LevelStr[level])
+	tmp.WriteString("] ")
+	tmp.WriteString(msg)
+	var sb strings.Builder
+	writeKV(&sb, "", attrs)
+	tmp.WriteString(sb.String())
+
+	if !color {
+		return tmp.ToString(true)
+	}
+	switch level {
+	case DEBUG:
+		return tmp.ToColorString(l.DebugColor, true)
+	case INFO:
+		return tmp.ToColorString(l.InfoColor, true)
+	case WARN:
+		return tmp.ToColorString(l.WarnColor, true)
+	case ERROR:
+		return tmp.ToColorString(l.ErrorColor, true)
+	case FATAL:
+		return tmp.ToColorString(l.FatalColor, true)
+	case PANIC:
+		return tmp.ToColorString(l.PanicColor, true)
+	}
+	return tmp.ToString(true)
+}
+
+func encodeLogfmt(level Level, t time.Time, msg string, attrs []slog.Attr) []byte {
+	var sb strings.Builder
+	if !t.IsZero() {
+		sb.WriteString("time=")
+		sb.WriteString(t.Format(machineTimeLayout))
+		sb.WriteByte(' ')
+	}
+	sb.WriteString("level=")
+	sb.WriteString(LevelStr[level])
+	sb.WriteString(" msg=")
+	sb.WriteString(quote(msg))
+	writeKV(&sb, "", attrs)
+	sb.WriteByte('\n')
+	return []byte(sb.String())
+}
+
+// writeKV 以 " key=value" 形式写出字段，分组展开为 group.key。
+func writeKV(sb *strings.Builder, prefix string, attrs []slog.Attr) {
+	for _, a := range attrs {
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			writeKV(sb, prefix+a.Key+".", a.Value.Group())
+			continue
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(quote(prefix + a.Key))
+		sb.WriteByte('=')
+		sb.WriteString(quote(textValue(a.Value)))
+	}
+}
+
+func textValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(machineTimeLayout)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error()
+		case []byte:
+			return string(x)
+		}
+	}
+	return v.String()
+}
+
+// quote 空串或含空格、=、引号、控制字符时加引号。
+func quote(s string) string {
+	if s == "" || needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsControl(r) || unicode.IsSpace(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func encodeJSON(level Level, t time.Time, msg string, attrs []slog.Attr) []byte {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	if !t.IsZero() {
+		sb.WriteString(`"time":`)
+		writeJSONString(&sb, t.Format(machineTimeLayout))
+		sb.WriteByte(',')
+	}
+	sb.WriteString(`"level":`)
+	writeJSONString(&sb, LevelStr[level])
+	sb.WriteString(`,"msg":`)
+	writeJSONString(&sb, msg)
+	writeJSONAttrs(&sb, attrs, true)
+	sb.WriteString("}\n")
+	return []byte(sb.String())
+}
+
+// writeJSONAttrs 写出对象成员；leadingComma 表示前面已有成员。返回是否写出了成员。
+func writeJSONAttrs(sb *strings.Builder, attrs []slog.Attr, leadingComma bool) bool {
+	wrote := false
+	for _, a := range attrs {
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if a.Value.Kind() == slog.KindGroup && len(a.Value.Group()) == 0 {
+			continue
+		}
+		if leadingComma || wrote {
+			sb.WriteByte(',')
+		}
+		wrote = true
+		writeJSONString(sb, a.Key)
+		sb.WriteByte(':')
+		writeJSONValue(sb, a.Value)
+	}
+	return wrote
+}
+
+func writeJSONValue(sb *strings.Builder, v slog.Value) {
+	switch v.Kind() {
+	case slog.KindGroup:
+		sb.WriteByte('{')
+		writeJSONAttrs(sb, v.Group(), false)
+		sb.WriteByte('}')
+	case slog.KindString:
+		writeJSONString(sb, v.String())
+	case slog.KindInt64:
+		sb.WriteString(strconv.FormatInt(v.Int64(), 10))
+	case slog.KindUint64:
+		sb.WriteString(strconv.FormatUint(v.Uint64(), 10))
+	case slog.KindFloat64:
+		// NaN、Inf 不是合法 JSON，与 slog 一样写成字符串
+		f := v.Float64()
+		if b, err := json.Marshal(f); err == nil {
+			sb.Write(b)
+		} else {
+			writeJSONString(sb, strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case slog.KindBool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+	case slog.KindDuration:
+		// 与 slog.JSONHandler 一致，使用纳秒整数
+		sb.WriteString(strconv.FormatInt(int64(v.Duration()), 10))
+	case slog.KindTime:
+		writeJSONString(sb, v.Time().Format(machineTimeLayout))
+	default:
+		x := v.Any()
+		if err, ok := x.(error); ok {
+			if _, isMarshaler := x.(json.Marshaler); !isMarshaler {
+				writeJSONString(sb, err.Error())
+				return
+			}
+		}
+		b, err := json.Marshal(x)
+		if err != nil {
+			writeJSONString(sb, "!ERROR:"+err.Error())
+			return
+		}
+		sb.Write(b)
+	}
+}
+
+func writeJSONString(sb *strings.Builder, s string) {
+	b, _ := json.Marshal(s)
+	sb.Write(b)
+}
@@ -0,0 +1,96 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormat(t *testing.T) {
+	var b bytes.Buffer
+	l := NewLog(&Config{Format: JSONFormat, Output: &b, NotShowTime: true})
+	l.With("user", "bob").WithGroup("req").With("id", 7).Log(context.Background(), ERROR, "failed", Err(errors.New("boom")))
+
+	var got map[string]any
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatal(err, b.String())
+	}
+	req, _ := got["req"].(map[string]any)
+	if got["level"] != "error" || got["msg"] != "failed" || got["user"] != "bob" || req["id"] != 7.0 || req["error"] != "boom" {
+		t.Fatal(b.String())
+	}
+	if _, ok := got["time"]; ok {
+		t.Fatal("time should be omitted")
+	}
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	var b bytes.Buffer
+	l := NewLog(&Config{Format: LogfmtFormat, Output: &b, NotShowTime: true})
+	l.With("name", "a b").Slog().WithGroup("g").Info("hi there", "ok", true)
+
+	want := `level=info msg="hi there" name="a b" g.ok=true` + "\n"
+	if b.String() != want {
+		t.Fatalf("got %q, want %q", b.String(), want)
+	}
+}
+
+type countStringer struct{ n *int }
+
+func (c countStringer) String() string { *c.n++; return "s" }
+
+func TestLevelFilter(t *testing.T) {
+	// 已有级别的取值不变
+	if DEBUG != 0 || INFO != 1 || WARN != 2 || FATAL != 3 || PANIC != 4 {
+		t.Fatal("existing Level values changed")
+	}
+	tests := []struct {
+		min  Level
+		want string
+	}{
+		{DEBUG, "debug info warn error "},
+		{WARN, "warn error "},
+		{ERROR, "error "},
+		{FATAL, ""},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		l := NewLog(&Config{Format: LogfmtFormat, Output: &b, NotShowTime: true, Level: tt.min})
+		var formatted int
+		s := countStringer{&formatted}
+		l.Debug("debug%v", s)
+		l.Info("info%v", s)
+		l.Warn("warn%v", s)
+		l.Error("error%v", s)
+
+		var got string
+		for _, name := range []string{"debug", "info", "warn", "error"} {
+			if strings.Contains(b.String(), "msg="+name+"s") {
+				got += name + " "
+			}
+		}
+		if got != tt.want {
+			t.Fatalf("min %s: got %q, want %q", LevelStr[tt.min], got, tt.want)
+		}
+		// 未输出的级别不格式化消息
+		if n := len(strings.Fields(tt.want)); formatted != n {
+			t.Fatalf("min %s: formatted %d times, want %d", LevelStr[tt.min], formatted, n)
+		}
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	var b bytes.Buffer
+	l := NewLog(&Config{Format: LogfmtFormat, Output: &b, NotShowTime: true})
+	ctx := ContextWith(context.Background(), "request_id", "r1")
+	l.Log(ctx, INFO, "a", "k", 1)
+	l.Slog().InfoContext(ctx, "b")
+
+	want := "level=info msg=a request_id=r1 k=1\nlevel=info msg=b request_id=r1\n"
+	if b.String() != want {
+		t.Fatalf("got %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,103 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Field 结构化字段，即 slog.Attr，可与 slog 互通。
+type Field = slog.Attr
+
+func String(key, value string) Field { return slog.String(key, value) }
+
+func Int(key string, value int) Field { return slog.Int(key, value) }
+
+func Int64(key string, value int64) Field { return slog.Int64(key, value) }
+
+func Uint64(key string, value uint64) Field { return slog.Uint64(key, value) }
+
+func Float64(key string, value float64) Field { return slog.Float64(key, value) }
+
+func Bool(key string, value bool) Field { return slog.Bool(key, value) }
+
+func Duration(key string, value time.Duration) Field { return slog.Duration(key, value) }
+
+func Time(key string, value time.Time) Field { return slog.Time(key, value) }
+
+func Any(key string, value any) Field { return slog.Any(key, value) }
+
+// Err 以 "error" 为 key 记录错误。
+func Err(err error) Field { return slog.Any("error", err) }
+
+// Group 把多个字段放进 key 分组。
+func Group(key string, fields ...any) Field { return slog.Group(key, fields...) }
+
+type ctxKey struct{}
+
+// ContextWith 返回携带字段的 ctx，参数同 With。通过 Log.Log 或 slog 的 *Context 方法输出时会带上这些字段，
+// 适合在请求入口放入 request_id 等信息。
+func ContextWith(ctx context.Context, args ...any) context.Context {
+	attrs := append(slices.Clip(fromContext(ctx)), argsToAttrs(args)...)
+	return context.WithValue(ctx, ctxKey{}, attrs)
+}
+
+func fromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(ctxKey{}).([]slog.Attr)
+	return attrs
+}
+
+// argsToAttrs 按 slog 的规则把 key, value 交替的参数转换为字段，缺少 key 的值记为 !BADKEY。
+func argsToAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch x := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, x)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.String("!BADKEY", x))
+				return attrs
+			}
+			attrs = append(attrs, slog.Any(x, args[1]))
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any("!BADKEY", x))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+// mergeGroups 解析 LogValuer，并合并同名分组，避免多次 With 同一分组时 JSON 出现重复 key。
+func mergeGroups(attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	out := make([]slog.Attr, 0, len(attrs))
+	index := make(map[string]int)
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Value.Kind() == slog.KindGroup {
+			group := a.Value.Group()
+			if a.Key == "" {
+				// 空 key 的分组内联到上一层
+				out = append(out, mergeGroups(group)...)
+				continue
+			}
+			if i, ok := index[a.Key]; ok && out[i].Value.Kind() == slog.KindGroup {
+				out[i].Value = slog.GroupValue(mergeGroups(append(slices.Clip(out[i].Value.Group()), group...))...)
+				continue
+			}
+			a.Value = slog.GroupValue(mergeGroups(group)...)
+		}
+		index[a.Key] = len(out)
+		out = append(out, a)
+	}
+	return out
+}
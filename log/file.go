@@ -0,0 +1,310 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeLayout 切割后文件名中的时间，如 app-2006-01-02T15-04-05.000.log。
+const backupTimeLayout = "2006-01-02T15-04-05.000"
+
+// FileOption 日志文件的切割与保留配置，零值表示不切割、不清理。
+type FileOption struct {
+	// MaxSize 单个文件的最大字节数，超出后切割，0 表示不按大小切割
+	MaxSize int64
+	// RotateEvery 按时间切割的周期（按本地时间对齐），如 24*time.Hour 每天零点切割，0 表示不按时间切割
+	RotateEvery time.Duration
+	// MaxBackups 最多保留的切割文件数，0 表示不限制
+	MaxBackups int
+	// MaxAge 切割文件的最长保留时间，0 表示不限制
+	MaxAge time.Duration
+	// Compress 使用 gzip 压缩切割后的文件
+	Compress bool
+	// Perm 新建文件的权限，默认 0644
+	Perm os.FileMode
+}
+
+// File 支持切割的日志文件，可并发写入。首次写入时才打开（必要时创建目录）。
+// 切割文件命名为 name-时间.ext，压缩与清理在后台进行。
+type File struct {
+	path string
+	opt  FileOption
+
+	mu     sync.Mutex
+	f      *os.File
+	size   int64
+	next   time.Time
+	closed bool
+
+	millOnce sync.Once
+	millCh   chan struct{}
+	millDone chan struct{}
+}
+
+func NewFile(path string, opts ...FileOption) *File {
+	opt := FileOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Perm == 0 {
+		opt.Perm = 0o644
+	}
+	return &File{path: path, opt: opt}
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	now := time.Now()
+	if f.f == nil {
+		if err := f.open(now); err != nil {
+			return 0, err
+		}
+	}
+	if (f.opt.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opt.MaxSize) ||
+		(!f.next.IsZero() && !now.Before(f.next)) {
+		if err := f.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即切割当前文件。
+func (f *File) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.f == nil {
+		if err := f.open(time.Now()); err != nil {
+			return err
+		}
+	}
+	return f.rotate(time.Now())
+}
+
+// Sync 把已写入的内容刷到磁盘。
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return nil
+	}
+	return f.f.Sync()
+}
+
+// Close 关闭文件并等待后台压缩、清理结束。
+func (f *File) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	var err error
+	if f.f != nil {
+		err = f.f.Close()
+		f.f = nil
+	}
+	f.mu.Unlock()
+	if f.millCh != nil {
+		close(f.millCh)
+		<-f.millDone
+	}
+	return err
+}
+
+func (f *File) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, f.opt.Perm)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.f, f.size = file, info.Size()
+	if f.opt.RotateEvery > 0 {
+		f.next = f.nextRotate(now)
+		// 上次运行留下的旧周期文件
+		if f.size > 0 && info.ModTime().Before(f.next.Add(-f.opt.RotateEvery)) {
+			f.next = now
+		}
+	}
+	return nil
+}
+
+func (f *File) rotate(now time.Time) error {
+	if err := f.f.Close(); err != nil {
+		return err
+	}
+	f.f = nil
+	if f.size > 0 {
+		if err := os.Rename(f.path, f.backupName(now)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := f.open(now); err != nil {
+		return err
+	}
+	f.mill()
+	return nil
+}
+
+// nextRotate 返回 now 之后下一个按本地时间对齐的切割时间。
+func (f *File) nextRotate(now time.Time) time.Time {
+	_, offset := now.Zone()
+	shift := time.Duration(offset) * time.Second
+	return now.Add(shift).Truncate(f.opt.RotateEvery).Add(f.opt.RotateEvery).Add(-shift)
+}
+
+// backupName 返回切割文件名，同一毫秒内多次切割时顺延，避免覆盖。
+func (f *File) backupName(t time.Time) string {
+	dir, prefix, ext := f.parts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeLayout)+ext)
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			if _, err := os.Stat(name + ".gz"); errors.Is(err, os.ErrNotExist) {
+				return name
+			}
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func (f *File) parts() (dir, prefix, ext string) {
+	dir, name := filepath.Split(f.path)
+	ext = filepath.Ext(name)
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// mill 通知后台协程压缩、清理切割文件。
+func (f *File) mill() {
+	if !f.opt.Compress && f.opt.MaxBackups <= 0 && f.opt.MaxAge <= 0 {
+		return
+	}
+	f.millOnce.Do(func() {
+		f.millCh = make(chan struct{}, 1)
+		f.millDone = make(chan struct{})
+		go func() {
+			defer close(f.millDone)
+			for range f.millCh {
+				f.millRun()
+			}
+		}()
+	})
+	select {
+	case f.millCh <- struct{}{}:
+	default:
+	}
+}
+
+type backup struct {
+	path string
+	t    time.Time
+	gz   bool
+}
+
+func (f *File) millRun() {
+	backups := f.backups()
+	// 新的在前
+	slices.SortFunc(backups, func(a, b backup) int { return b.t.Compare(a.t) })
+
+	var keep []backup
+	for i, b := range backups {
+		if (f.opt.MaxBackups > 0 && i >= f.opt.MaxBackups) ||
+			(f.opt.MaxAge > 0 && time.Since(b.t) > f.opt.MaxAge) {
+			os.Remove(b.path)
+			continue
+		}
+		keep = append(keep, b)
+	}
+	if !f.opt.Compress {
+		return
+	}
+	for _, b := range keep {
+		if !b.gz {
+			compressFile(b.path, f.opt.Perm)
+		}
+	}
+}
+
+func (f *File) backups() []backup {
+	dir, prefix, ext := f.parts()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts, gz := strings.TrimPrefix(name, prefix), false
+		if strings.HasSuffix(ts, ".gz") {
+			ts, gz = strings.TrimSuffix(ts, ".gz"), true
+		}
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeLayout, strings.TrimSuffix(ts, ext), time.Local)
+		if err != nil {
+			continue
+		}
+		out = append(out, backup{path: filepath.Join(dir, name), t: t, gz: gz})
+	}
+	return out
+}
+
+// compressFile 把 path 压缩为 path.gz，成功后删除原文件。
+func compressFile(path string, perm os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -1,15 +1,25 @@
 package log
 
 import (
+	"cmp"
+	"context"
 	"fmt"
-	"github.com/Rehtt/Kit/buf"
+	"io"
+	"log/slog"
 	"os"
 	"runtime/debug"
+	"slices"
 	"time"
 )
 
 type Log struct {
 	*Config
+	// attrs 已按打开的分组嵌套好的字段
+	attrs []slog.Attr
+	// groups 当前打开的分组，之后添加的字段都放在分组内
+	groups []string
+	// out 输出路由，子 Logger 共享
+	out *output
 }
 
 type Option interface {
@@ -25,72 +35,127 @@ func NewLog(option ...Option) *Log {
 	}
 	return &Log{
 		Config: c,
+		out:    newOutput(c),
 	}
 }
 
-func (l Log) Debug(format string, a ...any) {
-	if l.Level > DEBUG {
-		return
+// With 返回带上字段的子 Logger，参数与 slog 一致：key, value 交替，或直接传 Field。
+// 子 Logger 与父 Logger 共享配置。
+func (l Log) With(args ...any) *Log {
+	if attrs := argsToAttrs(args); len(attrs) > 0 {
+		l.attrs = append(slices.Clip(l.attrs), l.nest(attrs)...)
 	}
-	fmt.Println(l.sprintf(DEBUG, format, a...))
+	return &l
 }
 
-func (l Log) Info(format string, a ...any) {
-	if l.Level > INFO {
-		return
+// WithGroup 返回子 Logger，之后添加的字段都放在 name 分组内（JSON 中为嵌套对象，文本中为 name.key）。
+func (l Log) WithGroup(name string) *Log {
+	if name != "" {
+		l.groups = append(slices.Clip(l.groups), name)
 	}
-	fmt.Println(l.sprintf(INFO, format, a...))
+	return &l
+}
+
+func (l Log) Debug(format string, a ...any) {
+	l.logf(DEBUG, format, a)
+}
+
+func (l Log) Info(format string, a ...any) {
+	l.logf(INFO, format, a)
 }
 
 func (l Log) Warn(format string, a ...any) {
-	if l.Level > WARN {
-		return
-	}
-	fmt.Println(l.sprintf(WARN, format, a...))
+	l.logf(WARN, format, a)
+}
+
+func (l Log) Error(format string, a ...any) {
+	l.logf(ERROR, format, a)
 }
 
 func (l Log) Fatal(format string, a ...any) {
-	if l.Level > FATAL {
+	if !FATAL.enabled(l.Level) {
 		return
 	}
-	fmt.Println(l.sprintf(FATAL, format, a...))
+	l.logf(FATAL, format, a)
+	l.Close()
 	debug.PrintStack()
 	os.Exit(1)
 }
 
 func (l Log) Panic(format string, a ...any) {
-	if l.Level > PANIC {
+	if !PANIC.enabled(l.Level) {
+		return
+	}
+	msg := fmt.Sprintf(format, a...)
+	l.write(PANIC, time.Now(), msg, nil)
+	l.Flush()
+	panic(msg)
+}
+
+// Log 以结构化方式输出一条日志，args 同 With，ctx 中由 ContextWith 附加的字段排在 args 之前。
+// FATAL、PANIC 级别在这里只输出，不会退出或 panic。
+func (l Log) Log(ctx context.Context, level Level, msg string, args ...any) {
+	if !level.enabled(l.Level) {
+		return
+	}
+	l.write(level, time.Now(), msg, append(slices.Clip(fromContext(ctx)), argsToAttrs(args)...))
+}
+
+// logf 级别未开启时不格式化消息。
+func (l Log) logf(level Level, format string, a []any) {
+	if !level.enabled(l.Level) {
 		return
 	}
-	fmt.Println(l.sprintf(PANIC, format, a...))
-	panic(fmt.Sprintf(format, a...))
+	l.write(level, time.Now(), fmt.Sprintf(format, a...), nil)
 }
 
-func (l Log) sprintf(leve Level, format string, a ...any) string {
-	var tmp = buf.NewBuf()
-	if !l.NotShowTime {
-		tmp.WriteString(time.Now().Format(l.TimeLayout))
+func (l Log) write(level Level, t time.Time, msg string, attrs []slog.Attr) error {
+	if !level.enabled(l.Level) {
+		return nil
+	}
+	if l.NotShowTime {
+		t = time.Time{}
+	}
+	fields := l.attrs
+	if len(attrs) > 0 {
+		fields = append(slices.Clip(fields), l.nest(attrs)...)
 	}
-	tmp.WriteString(" [")
-	tmp.WriteString(LevelStr[leve])
-	tmp.WriteString("] ")
+	fields = mergeGroups(fields)
+	out := l.output()
+	e := entry{level: level}
+	plain, colored := out.needs(level)
+	if plain {
+		e.plain = l.encode(level, t, msg, fields, false)
+	}
+	if colored {
+		e.colored = l.encode(level, t, msg, fields, !l.NotShowColor)
+	}
+	return out.write(e)
+}
+
+// Flush 等待异步队列写完，并把文件内容刷到磁盘。
+func (l Log) Flush() error {
+	return l.output().flush()
+}
 
-	tmp.WriteString(fmt.Sprintf(format, a...))
+// Close 写完剩余日志并关闭 InfoOutFile、ErrorOutFile，Sinks 中的输出目标由调用方关闭。
+// 关闭后的日志改为同步写入，写入已关闭的文件会失败。
+func (l Log) Close() error {
+	return l.output().close()
+}
 
-	if l.NotShowColor {
-		return tmp.ToString(true)
+// output 未通过 NewLog 创建的 Log 只同步写入 Output。
+func (l Log) output() *output {
+	if l.out != nil {
+		return l.out
 	}
-	switch leve {
-	case DEBUG:
-		return tmp.ToColorString(l.DebugColor, true)
-	case INFO:
-		return tmp.ToColorString(l.InfoColor, true)
-	case WARN:
-		return tmp.ToColorString(l.WarnColor, true)
-	case FATAL:
-		return tmp.ToColorString(l.FatalColor, true)
-	case PANIC:
-		return tmp.ToColorString(l.PanicColor, true)
+	return &output{sinks: []Sink{{Writer: cmp.Or[io.Writer](l.Output, os.Stdout)}}}
+}
+
+// nest 把 attrs 放进当前打开的分组。
+func (l Log) nest(attrs []slog.Attr) []slog.Attr {
+	for i := len(l.groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: l.groups[i], Value: slog.GroupValue(attrs...)}}
 	}
-	return ""
+	return attrs
 }
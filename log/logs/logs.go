@@ -1,31 +1,82 @@
 package logs
 
-import "github.com/Rehtt/Kit/log"
+import (
+	"sync"
+	"sync/atomic"
 
-var logs *log.Log
+	"github.com/Rehtt/Kit/log"
+)
+
+// logger 默认 Logger 及其读写锁：输出时持有读锁，Apply 替换后持有写锁等待正在进行的输出结束再关闭。
+type logger struct {
+	l      *log.Log
+	mu     sync.RWMutex
+	closed bool
+}
+
+var current atomic.Pointer[logger]
 
 func init() {
-	logs = log.NewLog()
+	current.Store(&logger{l: log.NewLog()})
+}
+
+// use 以当前的默认 Logger 调用 f，期间该 Logger 不会被 Apply 关闭。
+func use(f func(l *log.Log)) {
+	for {
+		h := current.Load()
+		h.mu.RLock()
+		if !h.closed {
+			defer h.mu.RUnlock()
+			f(h.l)
+			return
+		}
+		// 已被 Apply 替换，改用新的 Logger
+		h.mu.RUnlock()
+	}
 }
 
 func Debug(format string, a ...any) {
-	logs.Debug(format, a...)
+	use(func(l *log.Log) { l.Debug(format, a...) })
 }
 
 func Info(format string, a ...any) {
-	logs.Info(format, a...)
+	use(func(l *log.Log) { l.Info(format, a...) })
 }
 func Warn(format string, a ...any) {
-	logs.Warn(format, a...)
+	use(func(l *log.Log) { l.Warn(format, a...) })
+}
+func Error(format string, a ...any) {
+	use(func(l *log.Log) { l.Error(format, a...) })
 }
 func Fatal(format string, a ...any) {
-	logs.Fatal(format, a...)
+	use(func(l *log.Log) { l.Fatal(format, a...) })
 }
 func Panic(format string, a ...any) {
-	logs.Panic(format, a...)
+	use(func(l *log.Log) { l.Panic(format, a...) })
+}
+
+// With 返回带上字段的子 Logger。子 Logger 绑定调用时的配置，Apply 之后需重新获取
+func With(args ...any) *log.Log {
+	return current.Load().l.With(args...)
+}
+
+// Apply 以 config 替换默认 Logger 的配置，等待正在进行的输出结束后关闭原来打开的日志文件
+func Apply(config *log.Config) error {
+	old := current.Swap(&logger{l: log.NewLog(config)})
+	old.mu.Lock()
+	defer old.mu.Unlock()
+	old.closed = true
+	return old.l.Close()
+}
+
+// Flush 等待异步日志写完
+func Flush() (err error) {
+	use(func(l *log.Log) { err = l.Flush() })
+	return
 }
 
-// Apply 更新配置
-func Apply(config *log.Config) {
-	logs.Apply(config)
+// Close 写完剩余日志并关闭日志文件
+func Close() (err error) {
+	use(func(l *log.Log) { err = l.Close() })
+	return
 }
@@ -1,6 +1,15 @@
 package logs
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Rehtt/Kit/log"
+)
 
 func TestLog(t *testing.T) {
 	Debug("debug")
@@ -9,3 +18,42 @@ func TestLog(t *testing.T) {
 	Fatal("fatal")
 	Panic("panic")
 }
+
+func TestApplyConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					Info("x")
+				}
+			}
+		}()
+	}
+	for i := range 10 {
+		err := Apply(&log.Config{InfoOutFile: filepath.Join(dir, fmt.Sprintf("%d.log", i)), Async: i%2 == 0, NotShowTime: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每次 Apply 之前的日志都已写入对应文件，没有写入已关闭文件而丢失
+	for i := range 10 {
+		data, _ := os.ReadFile(filepath.Join(dir, fmt.Sprintf("%d.log", i)))
+		if strings.Count(string(data), "[info] x\n")*len(" [info] x\n") != len(data) {
+			t.Fatalf("%d.log corrupted: %q", i, data)
+		}
+	}
+}
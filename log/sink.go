@@ -0,0 +1,221 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Sink 输出目标及其级别路由。
+type Sink struct {
+	// Writer 输出目标；实现 LevelWriter 时按级别写入（如 Syslog）
+	Writer io.Writer
+	// Levels 只输出这些级别，为空表示全部
+	Levels []Level
+}
+
+// LevelWriter 需要知道日志级别的输出目标。
+type LevelWriter interface {
+	WriteLevel(level Level, p []byte) (int, error)
+}
+
+// Stderr 输出 ERROR 及以上级别到标准错误的 Sink。
+func Stderr() Sink {
+	return Sink{Writer: os.Stderr, Levels: []Level{ERROR, FATAL, PANIC}}
+}
+
+func (s *Sink) accept(level Level) bool {
+	return len(s.Levels) == 0 || slices.Contains(s.Levels, level)
+}
+
+// color 只有终端输出才带颜色。
+func (s *Sink) color() bool {
+	return s.Writer == os.Stdout || s.Writer == os.Stderr
+}
+
+func (s *Sink) write(level Level, p []byte) error {
+	var err error
+	if lw, ok := s.Writer.(LevelWriter); ok {
+		_, err = lw.WriteLevel(level, p)
+	} else {
+		_, err = s.Writer.Write(p)
+	}
+	return err
+}
+
+// entry 一条已编码的日志，plain、colored 分别供普通与终端输出使用。
+type entry struct {
+	level   Level
+	plain   []byte
+	colored []byte
+	// flushed 非空时为 Flush 请求，写完之前的日志后关闭
+	flushed chan struct{}
+}
+
+// output 按配置把日志路由到各个 Sink，被同一 Logger 派生出的子 Logger 共享。
+type output struct {
+	sinks []Sink
+	// owned 由 InfoOutFile、ErrorOutFile 打开的文件，Close 时关闭
+	owned []*File
+
+	mu     sync.RWMutex
+	queue  chan entry
+	closed bool
+	done   chan struct{}
+}
+
+func newOutput(c *Config) *output {
+	o := &output{}
+	if c.Output != nil {
+		o.sinks = append(o.sinks, Sink{Writer: c.Output})
+	}
+	switch {
+	case c.InfoOutFile != "" && c.ErrorOutFile != "" && c.InfoOutFile != c.ErrorOutFile:
+		info, errFile := NewFile(c.InfoOutFile, c.Rotate), NewFile(c.ErrorOutFile, c.Rotate)
+		o.owned = append(o.owned, info, errFile)
+		o.sinks = append(o.sinks,
+			Sink{Writer: info, Levels: []Level{DEBUG, INFO, WARN}},
+			Sink{Writer: errFile, Levels: []Level{ERROR, FATAL, PANIC}},
+		)
+	case c.InfoOutFile != "" || c.ErrorOutFile != "":
+		path := c.InfoOutFile
+		if path == "" {
+			path = c.ErrorOutFile
+		}
+		f := NewFile(path, c.Rotate)
+		o.owned = append(o.owned, f)
+		s := Sink{Writer: f}
+		if c.InfoOutFile == "" {
+			s.Levels = []Level{ERROR, FATAL, PANIC}
+		}
+		o.sinks = append(o.sinks, s)
+	}
+	for _, s := range c.Sinks {
+		if s.Writer != nil {
+			o.sinks = append(o.sinks, s)
+		}
+	}
+	if c.Async {
+		size := c.AsyncBuffer
+		if size <= 0 {
+			size = 1024
+		}
+		o.queue = make(chan entry, size)
+		o.done = make(chan struct{})
+		go o.loop()
+	}
+	return o
+}
+
+// needs 返回该级别是否需要普通、带颜色的编码结果。
+func (o *output) needs(level Level) (plain, colored bool) {
+	for i := range o.sinks {
+		if !o.sinks[i].accept(level) {
+			continue
+		}
+		if o.sinks[i].color() {
+			colored = true
+		} else {
+			plain = true
+		}
+	}
+	return
+}
+
+func (o *output) write(e entry) error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.queue != nil && !o.closed {
+		o.queue <- e
+		return nil
+	}
+	return o.emit(e)
+}
+
+func (o *output) emit(e entry) error {
+	var errs []error
+	for i := range o.sinks {
+		s := &o.sinks[i]
+		if !s.accept(e.level) {
+			continue
+		}
+		p := e.plain
+		if s.color() {
+			p = e.colored
+		}
+		if err := s.write(e.level, p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (o *output) loop() {
+	defer close(o.done)
+	for e := range o.queue {
+		if e.flushed != nil {
+			o.sync()
+			close(e.flushed)
+			continue
+		}
+		// 异步模式下无法把错误返回给调用方，写入失败的日志被丢弃
+		o.emit(e)
+	}
+}
+
+// sync 把文件缓冲写入磁盘。
+func (o *output) sync() error {
+	var errs []error
+	for i := range o.sinks {
+		if o.sinks[i].color() {
+			continue
+		}
+		if s, ok := o.sinks[i].Writer.(interface{ Sync() error }); ok {
+			if err := s.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (o *output) flush() error {
+	o.mu.RLock()
+	if o.queue != nil && !o.closed {
+		e := entry{flushed: make(chan struct{})}
+		o.queue <- e
+		o.mu.RUnlock()
+		<-e.flushed
+		return nil
+	}
+	o.mu.RUnlock()
+	return o.sync()
+}
+
+func (o *output) close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	if o.queue != nil {
+		close(o.queue)
+	}
+	o.mu.Unlock()
+	if o.done != nil {
+		<-o.done
+	}
+	var errs []error
+	if err := o.sync(); err != nil {
+		errs = append(errs, err)
+	}
+	for _, f := range o.owned {
+		if err := f.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
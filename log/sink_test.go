@@ -0,0 +1,176 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutFileRouting(t *testing.T) {
+	dir := t.TempDir()
+	var warn bytes.Buffer
+	l := NewLog(&Config{
+		InfoOutFile:  filepath.Join(dir, "info.log"),
+		ErrorOutFile: filepath.Join(dir, "error.log"),
+		Sinks:        []Sink{{Writer: &warn, Levels: []Level{WARN}}},
+		Async:        true,
+		NotShowTime:  true,
+	})
+	l.Info("a")
+	l.Warn("b")
+	l.Error("c")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, _ := os.ReadFile(filepath.Join(dir, "info.log"))
+	errLog, _ := os.ReadFile(filepath.Join(dir, "error.log"))
+	if string(info) != " [info] a\n [warn] b\n" || string(errLog) != " [error] c\n" || warn.String() != " [warn] b\n" {
+		t.Fatalf("info=%q error=%q warn=%q", info, errLog, warn.String())
+	}
+}
+
+func TestFileRotate(t *testing.T) {
+	dir := t.TempDir()
+	f := NewFile(filepath.Join(dir, "app.log"), FileOption{MaxSize: 10, MaxBackups: 2, Compress: true})
+	for range 5 {
+		if _, err := f.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var gz int
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "app-") && strings.HasSuffix(e.Name(), ".log.gz") {
+			gz++
+		}
+	}
+	if len(entries) != 3 || gz != 2 {
+		t.Fatal(entries)
+	}
+}
+
+func TestFileRotateEvery(t *testing.T) {
+	dir := t.TempDir()
+	f := NewFile(filepath.Join(dir, "app.log"), FileOption{RotateEvery: 100 * time.Millisecond})
+	defer f.Close()
+	if _, err := f.Write([]byte("a\n")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if _, err := f.Write([]byte("b\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatal(entries)
+	}
+	cur, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+	if string(cur) != "b\n" {
+		t.Fatalf("current = %q", cur)
+	}
+}
+
+// slowWriter 每次写入前等待，用于确认 Flush 等待异步队列写完。
+type slowWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncFlushClose(t *testing.T) {
+	w := &slowWriter{}
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := NewLog(&Config{Output: w, InfoOutFile: path, Async: true, AsyncBuffer: 4, NotShowTime: true, Format: LogfmtFormat})
+	for i := range 20 {
+		l.Info("%d", i)
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(w.String(), "\n"); n != 20 {
+		t.Fatalf("flushed %d lines, want 20", n)
+	}
+	file, _ := os.ReadFile(path)
+	if string(file) != w.String() {
+		t.Fatalf("file = %q, writer = %q", file, w.String())
+	}
+
+	l.Info("last")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(w.String(), "msg=last\n") {
+		t.Fatalf("Close dropped queued log: %q", w.String())
+	}
+	// 关闭后同步写入，自有文件已关闭
+	if err := l.output().write(entry{level: INFO, plain: []byte("x\n")}); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("write after Close = %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("second Close = %v", err)
+	}
+}
+
+func TestSyslog(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	s, err := NewSyslog(SyslogOption{Addr: addr, Tag: "app", Facility: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	l := NewLog(&Config{Sinks: []Sink{{Writer: s}}, NotShowTime: true, NotShowColor: true})
+
+	tests := []struct {
+		log  func(format string, a ...any)
+		want string
+	}{
+		// local0(16)<<3 | severity
+		{l.Debug, "<135>"},
+		{l.Info, "<134>"},
+		{l.Warn, "<132>"},
+		{l.Error, "<131>"},
+	}
+	buf := make([]byte, 1024)
+	for _, tt := range tests {
+		tt.log("hello")
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg := string(buf[:n])
+		if !strings.HasPrefix(msg, tt.want) || !strings.Contains(msg, " app[") || !strings.HasSuffix(msg, "] hello\n") {
+			t.Fatalf("syslog message = %q, want prefix %s", msg, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// Handler 返回以该 Logger 配置输出的 slog.Handler，便于接入使用 slog 的第三方库。
+// 通过 Handler 输出的 FATAL、PANIC 级别日志不会退出或 panic。
+func (l Log) Handler() slog.Handler {
+	return &handler{l: l}
+}
+
+// Slog 返回以该 Logger 输出的 *slog.Logger。
+func (l Log) Slog() *slog.Logger {
+	return slog.New(l.Handler())
+}
+
+type handler struct {
+	l Log
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return fromSlog(level).enabled(h.l.Level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := slices.Clip(fromContext(ctx))
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	return h.l.write(fromSlog(r.Level), r.Time, r.Message, attrs)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	args := make([]any, len(attrs))
+	for i, a := range attrs {
+		args[i] = a
+	}
+	return &handler{l: *h.l.With(args...)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{l: *h.l.WithGroup(name)}
+}
+
+// fromSlog 把 slog.Level 转换为 Level，高于 slog.LevelError 的依次映射到 FATAL、PANIC。
+func fromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	case level < slog.LevelError+4:
+		return ERROR
+	case level < slog.LevelError+8:
+		return FATAL
+	}
+	return PANIC
+}
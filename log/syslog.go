@@ -0,0 +1,126 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SyslogOption 本机 syslog 配置。
+type SyslogOption struct {
+	// Tag 消息标签，默认为程序名
+	Tag string
+	// Facility 默认 1（user），16~23 对应 local0~local7
+	Facility int
+	// Addr unix socket 路径，默认依次尝试 /dev/log、/var/run/syslog、/var/run/log
+	Addr string
+}
+
+// Syslog 通过本机 unix socket 写入 syslog，实现 LevelWriter，按日志级别设置 severity。
+// 建议配合 NotShowColor、NotShowTime 使用，时间由 syslog 记录。
+type Syslog struct {
+	opt SyslogOption
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewSyslog(opts ...SyslogOption) (*Syslog, error) {
+	opt := SyslogOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Tag == "" {
+		opt.Tag = filepath.Base(os.Args[0])
+	}
+	if opt.Facility == 0 {
+		opt.Facility = 1
+	}
+	s := &Syslog{opt: opt}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Syslog) connect() error {
+	addrs := []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+	if s.opt.Addr != "" {
+		addrs = []string{s.opt.Addr}
+	}
+	var errs []error
+	for _, addr := range addrs {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, addr)
+			if err == nil {
+				s.conn = conn
+				return nil
+			}
+			errs = append(errs, err)
+		}
+	}
+	return fmt.Errorf("log: syslog unavailable: %w", errors.Join(errs...))
+}
+
+// Write 以 INFO 级别写入。
+func (s *Syslog) Write(p []byte) (int, error) {
+	return s.WriteLevel(INFO, p)
+}
+
+func (s *Syslog) WriteLevel(level Level, p []byte) (int, error) {
+	msg := bytes.TrimSpace(p)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return 0, err
+		}
+	}
+	pri := s.opt.Facility<<3 | severity(level)
+	line := fmt.Appendf(nil, "<%d>%s %s[%d]: %s\n", pri, time.Now().Format(time.Stamp), s.opt.Tag, os.Getpid(), msg)
+	if _, err := s.conn.Write(line); err != nil {
+		// syslogd 重启后重连一次
+		s.conn.Close()
+		s.conn = nil
+		if err := s.connect(); err != nil {
+			return 0, err
+		}
+		if _, err := s.conn.Write(line); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// severity 对应 RFC 5424 的 severity。
+func severity(level Level) int {
+	switch level {
+	case DEBUG:
+		return 7
+	case INFO:
+		return 6
+	case WARN:
+		return 4
+	case ERROR:
+		return 3
+	case FATAL:
+		return 2
+	}
+	return 1
+}
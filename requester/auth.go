@@ -0,0 +1,288 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BasicAuth 为请求附加 HTTP Basic 认证。
+func BasicAuth(username, password string) Interceptor {
+	return OnBefore(func(req *http.Request) error {
+		req.SetBasicAuth(username, password)
+		return nil
+	})
+}
+
+// BearerAuth 为请求附加固定的 Bearer token。
+func BearerAuth(token string) Interceptor {
+	return OnBefore(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// TokenSource 提供访问令牌，实现需并发安全并自行缓存。
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// tokenInvalidator 收到 401 时让 TokenSource 丢弃缓存的令牌。
+type tokenInvalidator interface {
+	Invalidate(t *Token)
+}
+
+// TokenAuth 从 ts 获取令牌并附加到 Authorization。收到 401 且 ts 支持 Invalidate 时，换新令牌重发一次（请求体不可重放时不重发）。
+func TokenAuth(ts TokenSource) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		tok, err := ts.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", tok.authorization())
+		resp, err := next(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		inv, ok := ts.(tokenInvalidator)
+		if !ok {
+			return resp, nil
+		}
+		retry, ok := resendable(req)
+		if !ok {
+			return resp, nil
+		}
+		inv.Invalidate(tok)
+		if tok, err = ts.Token(req.Context()); err != nil {
+			return resp, nil
+		}
+		drain(resp)
+		retry.Header.Set("Authorization", tok.authorization())
+		return next(retry)
+	}
+}
+
+// DigestAuth 为请求附加 HTTP Digest 认证（RFC 7616，支持 MD5、SHA-256、SHA-512-256 及其 -sess 变体，qop=auth）。
+// 首次请求收到质询后重发；之后的请求复用质询直接带上认证，服务端标记 stale 时自动更新。
+func DigestAuth(username, password string) Interceptor {
+	d := &digestAuth{username: username, password: password}
+	return d.intercept
+}
+
+type digestAuth struct {
+	username, password string
+
+	mu   sync.Mutex
+	chal *digestChallenge
+	nc   uint32
+}
+
+type digestChallenge struct {
+	realm, nonce, opaque, algorithm string
+	qop                             bool
+	userhash                        bool
+}
+
+func (d *digestAuth) intercept(req *http.Request, next Doer) (*http.Response, error) {
+	authorized := false
+	if auth := d.authorize(req); auth != "" {
+		req.Header.Set("Authorization", auth)
+		authorized = true
+	}
+	resp, err := next(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	chal, stale, ok := parseDigestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok || (authorized && !stale) {
+		// 已带认证且 nonce 未过期，说明凭据错误
+		return resp, nil
+	}
+	d.setChallenge(chal)
+	retry, ok := resendable(req)
+	if !ok {
+		return resp, nil
+	}
+	auth := d.authorize(retry)
+	if auth == "" {
+		return resp, nil
+	}
+	drain(resp)
+	retry.Header.Set("Authorization", auth)
+	return next(retry)
+}
+
+func (d *digestAuth) setChallenge(c *digestChallenge) {
+	d.mu.Lock()
+	d.chal, d.nc = c, 0
+	d.mu.Unlock()
+}
+
+// authorize 用缓存的质询生成 Authorization，没有质询或算法不支持时返回空。
+func (d *digestAuth) authorize(req *http.Request) string {
+	d.mu.Lock()
+	c := d.chal
+	if c == nil {
+		d.mu.Unlock()
+		return ""
+	}
+	d.nc++
+	nc := fmt.Sprintf("%08x", d.nc)
+	d.mu.Unlock()
+
+	algo := strings.ToUpper(c.algorithm)
+	sess := strings.HasSuffix(algo, "-SESS")
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(algo, "-SESS") {
+	case "", "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	case "SHA-512-256":
+		newHash = sha512.New512_256
+	default:
+		return ""
+	}
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	var b [16]byte
+	rand.Read(b[:])
+	cnonce := hex.EncodeToString(b[:])
+	uri := req.URL.RequestURI()
+
+	ha1 := h(d.username + ":" + c.realm + ":" + d.password)
+	if sess {
+		ha1 = h(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := h(req.Method + ":" + uri)
+	var response string
+	if c.qop {
+		response = h(ha1 + ":" + c.nonce + ":" + nc + ":" + cnonce + ":auth:" + ha2)
+	} else {
+		response = h(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	username := d.username
+	if c.userhash {
+		username = h(d.username + ":" + c.realm)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `Digest username=%q, realm=%q, nonce=%q, uri=%q, response=%q`, username, c.realm, c.nonce, uri, response)
+	if c.algorithm != "" {
+		fmt.Fprintf(&sb, ", algorithm=%s", c.algorithm)
+	}
+	if c.opaque != "" {
+		fmt.Fprintf(&sb, ", opaque=%q", c.opaque)
+	}
+	if c.qop {
+		fmt.Fprintf(&sb, ", qop=auth, nc=%s, cnonce=%q", nc, cnonce)
+	}
+	if c.userhash {
+		sb.WriteString(", userhash=true")
+	}
+	return sb.String()
+}
+
+// parseDigestChallenge 从 WWW-Authenticate 中找出 Digest 质询。
+func parseDigestChallenge(values []string) (c *digestChallenge, stale, ok bool) {
+	for _, v := range values {
+		scheme, rest, _ := strings.Cut(strings.TrimSpace(v), " ")
+		if !strings.EqualFold(scheme, "Digest") {
+			continue
+		}
+		params := parseAuthParams(rest)
+		c = &digestChallenge{
+			realm:     params["realm"],
+			nonce:     params["nonce"],
+			opaque:    params["opaque"],
+			algorithm: params["algorithm"],
+			userhash:  strings.EqualFold(params["userhash"], "true"),
+		}
+		if qop, has := params["qop"]; has {
+			for _, q := range strings.Split(qop, ",") {
+				if strings.TrimSpace(q) == "auth" {
+					c.qop = true
+				}
+			}
+			if !c.qop {
+				// 仅支持 qop=auth
+				continue
+			}
+		}
+		if c.nonce == "" {
+			continue
+		}
+		return c, strings.EqualFold(params["stale"], "true"), true
+	}
+	return nil, false, false
+}
+
+// parseAuthParams 解析 key=value, key="quoted, value" 形式的参数。
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return params
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+		var val string
+		if strings.HasPrefix(s, `"`) {
+			var sb strings.Builder
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				sb.WriteByte(s[i])
+			}
+			val = sb.String()
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			val = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = val
+	}
+}
+
+// resendable 复制请求以便重发，请求体不可重放时返回 false。
+func resendable(req *http.Request) (*http.Request, bool) {
+	r := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false
+		}
+		r.Body = body
+	}
+	return r, true
+}
@@ -0,0 +1,111 @@
+package requester_test
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func TestStaticAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Header.Get("Authorization")))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		auth requester.Interceptor
+		want string
+	}{
+		{requester.BasicAuth("u", "p"), "Basic dTpw"},
+		{requester.BearerAuth("tk"), "Bearer tk"},
+	}
+	for _, tt := range tests {
+		r := requester.NewRequester().Get(srv.URL).Use(tt.auth)
+		if got := r.AsString(context.Background()); got != tt.want {
+			t.Errorf("Authorization = %q, want %q", got, tt.want)
+		}
+		r.Close()
+	}
+}
+
+// digestServer 按 RFC 7616 校验 qop=auth 的 Digest 认证。
+func digestServer(t *testing.T, algorithm string, newHash func() hash.Hash, calls *atomic.Int32) *httptest.Server {
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+	const realm, nonce, user, pass = "test", "n0nce", "alice", "secret"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		auth := r.Header.Get("Authorization")
+		if params, ok := strings.CutPrefix(auth, "Digest "); ok {
+			p := map[string]string{}
+			for _, kv := range strings.Split(params, ", ") {
+				k, v, _ := strings.Cut(kv, "=")
+				p[k] = strings.Trim(v, `"`)
+			}
+			ha1 := h(user + ":" + realm + ":" + pass)
+			ha2 := h(r.Method + ":" + p["uri"])
+			if p["username"] == user && p["response"] == h(ha1+":"+nonce+":"+p["nc"]+":"+p["cnonce"]+":auth:"+ha2) {
+				w.Write([]byte("ok"))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm=%q, nonce=%q, qop="auth", algorithm=%s`, realm, nonce, algorithm))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+}
+
+func TestDigestAuth(t *testing.T) {
+	tests := []struct {
+		algorithm string
+		newHash   func() hash.Hash
+	}{
+		{"MD5", md5.New},
+		{"SHA-256", sha256.New},
+	}
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := digestServer(t, tt.algorithm, tt.newHash, &calls)
+			defer srv.Close()
+
+			c, _ := requester.NewClient(requester.ClientOption{Interceptors: []requester.Interceptor{requester.DigestAuth("alice", "secret")}})
+			for i := 0; i < 2; i++ {
+				r := c.NewRequester().PostJSON(srv.URL+"/a?b=1", map[string]int{"n": i})
+				if got := r.AsString(context.Background()); got != "ok" {
+					t.Fatalf("request %d: body = %q, err = %v", i, got, r.GetErr())
+				}
+				r.Close()
+			}
+			// 首次质询一次，之后复用质询
+			if n := calls.Load(); n != 3 {
+				t.Fatalf("server calls = %d, want 3", n)
+			}
+		})
+	}
+}
+
+func TestDigestAuthWrongPassword(t *testing.T) {
+	var calls atomic.Int32
+	srv := digestServer(t, "MD5", md5.New, &calls)
+	defer srv.Close()
+
+	r := requester.NewRequester().Get(srv.URL).Use(requester.DigestAuth("alice", "wrong"))
+	defer r.Close()
+	resp, err := r.Response(context.Background())
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || calls.Load() != 2 {
+		t.Fatalf("resp = %v, err = %v, calls = %d", resp, err, calls.Load())
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("requester: circuit open")
+
+type BreakerState int
+
+const (
+	// StateClosed 正常放行
+	StateClosed BreakerState = iota
+	// StateOpen 熔断中，直接返回 ErrCircuitOpen
+	StateOpen
+	// StateHalfOpen 熔断超时后放行少量探测请求，成功则恢复，失败则重新熔断
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerOption 熔断策略，零值字段取默认值。
+type BreakerOption struct {
+	// FailureThreshold 连续失败多少次后熔断，默认 5
+	FailureThreshold int
+	// OpenTimeout 熔断持续时间，到期后进入半开，默认 30s
+	OpenTimeout time.Duration
+	// HalfOpenProbes 半开状态同时放行的探测请求数，默认 1
+	HalfOpenProbes int
+	// SuccessThreshold 半开状态连续成功多少次后恢复，默认 1
+	SuccessThreshold int
+	// IsFailure 判定一次请求是否计为失败，默认网络错误与 5xx
+	IsFailure func(resp *http.Response, err error) bool
+	// OnStateChange 状态变化回调，在锁外调用
+	OnStateChange func(host string, from, to BreakerState)
+}
+
+// Breakers 按 host 维护熔断器，需在多个 Requester 间共享同一个实例。
+type Breakers struct {
+	opt BreakerOption
+	mu  sync.Mutex
+	m   map[string]*breaker
+}
+
+func NewBreakers(opts ...BreakerOption) *Breakers {
+	opt := BreakerOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.FailureThreshold <= 0 {
+		opt.FailureThreshold = 5
+	}
+	if opt.OpenTimeout <= 0 {
+		opt.OpenTimeout = 30 * time.Second
+	}
+	if opt.HalfOpenProbes <= 0 {
+		opt.HalfOpenProbes = 1
+	}
+	if opt.SuccessThreshold <= 0 {
+		opt.SuccessThreshold = 1
+	}
+	if opt.IsFailure == nil {
+		opt.IsFailure = func(resp *http.Response, err error) bool {
+			return err != nil || resp.StatusCode >= 500
+		}
+	}
+	return &Breakers{opt: opt, m: make(map[string]*breaker)}
+}
+
+// Breaker 为请求挂上按 host 熔断的熔断器组。
+func (h *Requester) Breaker(b *Breakers) *Requester {
+	h.breakers = b
+	return h
+}
+
+// State 返回 host 当前的熔断状态。
+func (b *Breakers) State(host string) BreakerState {
+	br := b.get(host)
+	br.mu.Lock()
+	defer br.mu.Unlock()
+	if br.state == StateOpen && time.Since(br.openedAt) >= b.opt.OpenTimeout {
+		return StateHalfOpen
+	}
+	return br.state
+}
+
+// Reset 把 host 的熔断器恢复为关闭状态。
+func (b *Breakers) Reset(host string) {
+	b.mu.Lock()
+	delete(b.m, host)
+	b.mu.Unlock()
+}
+
+func (b *Breakers) get(host string) *breaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	br, ok := b.m[host]
+	if !ok {
+		br = &breaker{}
+		b.m[host] = br
+	}
+	return br
+}
+
+// allow 判断 host 是否可以发起请求；放行时返回的 done 必须以请求结果调用一次。
+func (b *Breakers) allow(host string) (done func(resp *http.Response, err error), err error) {
+	br := b.get(host)
+	br.mu.Lock()
+	from := br.state
+	if br.state == StateOpen {
+		if time.Since(br.openedAt) < b.opt.OpenTimeout {
+			br.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		br.state = StateHalfOpen
+		br.probes, br.successes = 0, 0
+	}
+	if br.state == StateHalfOpen {
+		if br.probes >= b.opt.HalfOpenProbes {
+			br.mu.Unlock()
+			b.notify(host, from, StateHalfOpen)
+			return nil, fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+		}
+		br.probes++
+	}
+	to, gen := br.state, br.gen
+	br.mu.Unlock()
+	b.notify(host, from, to)
+
+	return func(resp *http.Response, err error) {
+		if errors.Is(err, context.Canceled) {
+			// 调用方主动取消不代表上游故障，只归还探测名额
+			br.mu.Lock()
+			if gen == br.gen && br.state == StateHalfOpen {
+				br.probes--
+			}
+			br.mu.Unlock()
+			return
+		}
+		b.report(host, br, gen, !b.opt.IsFailure(resp, err))
+	}, nil
+}
+
+func (b *Breakers) report(host string, br *breaker, gen uint64, ok bool) {
+	br.mu.Lock()
+	if gen != br.gen {
+		// 状态已切换，过期结果不计入
+		br.mu.Unlock()
+		return
+	}
+	from := br.state
+	switch br.state {
+	case StateClosed:
+		if ok {
+			br.failures = 0
+		} else if br.failures++; br.failures >= b.opt.FailureThreshold {
+			br.trip()
+		}
+	case StateHalfOpen:
+		br.probes--
+		if !ok {
+			br.trip()
+		} else if br.successes++; br.successes >= b.opt.SuccessThreshold {
+			br.state = StateClosed
+			br.failures = 0
+			br.gen++
+		}
+	}
+	to := br.state
+	br.mu.Unlock()
+	b.notify(host, from, to)
+}
+
+func (b *Breakers) notify(host string, from, to BreakerState) {
+	if from != to && b.opt.OnStateChange != nil {
+		b.opt.OnStateChange(host, from, to)
+	}
+}
+
+type breaker struct {
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	// gen 每次状态切换递增，用于丢弃切换前发出请求的结果
+	gen uint64
+}
+
+func (br *breaker) trip() {
+	br.state = StateOpen
+	br.openedAt = time.Now()
+	br.gen++
+}
@@ -0,0 +1,108 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerStateMachine(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+	host := mustHost(t, srv.URL)
+
+	var changes []string
+	b := NewBreakers(BreakerOption{
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange: func(_ string, from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	send := func() error {
+		r := NewRequester().Get(srv.URL).Breaker(b)
+		defer r.Close()
+		resp, err := r.Response(context.Background())
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	steps := []struct {
+		name    string
+		fail    bool
+		wait    time.Duration
+		wantErr bool
+		want    BreakerState
+	}{
+		{"first failure", true, 0, false, StateClosed},
+		{"trips", true, 0, false, StateOpen},
+		{"rejected while open", true, 0, true, StateOpen},
+		{"probe fails", true, 30 * time.Millisecond, false, StateOpen},
+		{"probe succeeds", false, 30 * time.Millisecond, false, StateClosed},
+	}
+	for _, s := range steps {
+		fail.Store(s.fail)
+		time.Sleep(s.wait)
+		err := send()
+		if (err != nil) != s.wantErr || (err != nil && !errors.Is(err, ErrCircuitOpen)) {
+			t.Fatalf("%s: err = %v", s.name, err)
+		}
+		if got := b.State(host); got != s.want {
+			t.Fatalf("%s: state = %v, want %v", s.name, got, s.want)
+		}
+	}
+	want := "[closed->open open->half-open half-open->open open->half-open half-open->closed]"
+	if got := fmt.Sprint(changes); got != want {
+		t.Fatalf("state changes = %s, want %s", got, want)
+	}
+}
+
+func TestBreakerHalfOpenProbeLimit(t *testing.T) {
+	b := NewBreakers(BreakerOption{FailureThreshold: 1, OpenTimeout: time.Millisecond})
+	done, _ := b.allow("h")
+	done(nil, errors.New("down"))
+	time.Sleep(2 * time.Millisecond)
+
+	probe, err := b.allow("h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.allow("h"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second probe err = %v", err)
+	}
+	// 取消的探测归还名额，不计为失败
+	probe(nil, context.Canceled)
+	if b.State("h") != StateHalfOpen {
+		t.Fatalf("state = %v", b.State("h"))
+	}
+	if _, err := b.allow("h"); err != nil {
+		t.Fatalf("probe after cancel err = %v", err)
+	}
+	b.Reset("h")
+	if b.State("h") != StateClosed {
+		t.Fatal("Reset did not close breaker")
+	}
+}
+
+func mustHost(t *testing.T, u string) string {
+	t.Helper()
+	parsed, err := url.Parse(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Host
+}
@@ -0,0 +1,295 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrHTTP2Required = errors.New("requester: server did not negotiate HTTP/2")
+
+// ClientOption 客户端配置，零值字段取默认值（与 http.DefaultTransport 一致）。
+type ClientOption struct {
+	// Timeout 单次请求总超时（含读取响应体），0 表示不限制
+	Timeout time.Duration
+	// DialTimeout 建立连接超时，默认 30s
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout TLS 握手超时，默认 10s
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout 发送完请求后等待响应头的超时，0 表示不限制
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout 空闲连接保留时间，默认 90s
+	IdleConnTimeout time.Duration
+
+	// Proxy 代理地址，支持 http、https、socks5；为空时读取 HTTP_PROXY 等环境变量
+	Proxy string
+	// DisableProxy 不使用任何代理（包括环境变量）
+	DisableProxy bool
+
+	// TLSConfig 基础 TLS 配置，下面的 TLS 字段会覆盖到它的副本上
+	TLSConfig *tls.Config
+	// Certificates 客户端证书（mTLS），可用 tls.LoadX509KeyPair 加载
+	Certificates []tls.Certificate
+	// RootCAs 自定义根证书，为空时使用系统根证书
+	RootCAs *x509.CertPool
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于测试
+	InsecureSkipVerify bool
+
+	// Jar cookie 容器，为空时不保存 cookie
+	Jar http.CookieJar
+
+	// MaxIdleConns 所有 host 的最大空闲连接数，默认 100
+	MaxIdleConns int
+	// MaxIdleConnsPerHost 每个 host 的最大空闲连接数，默认 2
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个 host 的最大连接数，0 表示不限制
+	MaxConnsPerHost int
+	// DisableKeepAlives 禁用连接复用
+	DisableKeepAlives bool
+
+	// BaseURL 非绝对地址的请求会拼接在 BaseURL 之后
+	BaseURL string
+	// Header 默认请求头，Requester 上设置的同名 Header 会覆盖它
+	Header http.Header
+
+	// ForceHTTP2 要求使用 HTTP/2（需 HTTPS）：TLS 握手时只声明 h2，未协商成功则在发送请求前返回 ErrHTTP2Required。
+	// 开启后不使用代理，且不能与 Proxy、Transport 同时设置
+	ForceHTTP2 bool
+	// UnixSocket 所有请求都通过该 Unix socket 发送，URL 中的 host 仅用于 Host 头，如 http://unix/v1/info
+	UnixSocket string
+
+	// Interceptors 该客户端所有请求共用的拦截器，位于 Requester 自身拦截器之外
+	Interceptors []Interceptor
+
+	// Transport 自定义 RoundTripper；设置后上面的连接、代理、TLS 相关字段不再生效
+	Transport http.RoundTripper
+}
+
+// Client 持有独立的 http.Client 与连接池，可并发使用，应在多次请求间复用。
+type Client struct {
+	client       *http.Client
+	base         *url.URL
+	header       http.Header
+	interceptors []Interceptor
+}
+
+func NewClient(opts ...ClientOption) (*Client, error) {
+	opt := ClientOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	c := &Client{
+		header:       opt.Header.Clone(),
+		interceptors: slices.Clone(opt.Interceptors),
+	}
+	if opt.BaseURL != "" {
+		u, err := url.Parse(opt.BaseURL)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("requester: invalid base url %q", opt.BaseURL)
+		}
+		c.base = u
+	}
+
+	if opt.ForceHTTP2 && (opt.Proxy != "" || opt.Transport != nil) {
+		return nil, errors.New("requester: ForceHTTP2 cannot be combined with Proxy or Transport")
+	}
+
+	rt := opt.Transport
+	if rt == nil {
+		t, err := newTransport(&opt)
+		if err != nil {
+			return nil, err
+		}
+		rt = t
+	}
+	if opt.ForceHTTP2 {
+		rt = httpsOnly{rt}
+	}
+	c.client = &http.Client{
+		Transport: rt,
+		Jar:       opt.Jar,
+		Timeout:   opt.Timeout,
+	}
+	return c, nil
+}
+
+func newTransport(opt *ClientOption) (*http.Transport, error) {
+	if opt.DialTimeout <= 0 {
+		opt.DialTimeout = 30 * time.Second
+	}
+	if opt.TLSHandshakeTimeout <= 0 {
+		opt.TLSHandshakeTimeout = 10 * time.Second
+	}
+	if opt.IdleConnTimeout <= 0 {
+		opt.IdleConnTimeout = 90 * time.Second
+	}
+	if opt.MaxIdleConns <= 0 {
+		opt.MaxIdleConns = 100
+	}
+
+	dialer := &net.Dialer{Timeout: opt.DialTimeout, KeepAlive: 30 * time.Second}
+	t := &http.Transport{
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   opt.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opt.ResponseHeaderTimeout,
+		IdleConnTimeout:       opt.IdleConnTimeout,
+		ExpectContinueTimeout: time.Second,
+		MaxIdleConns:          opt.MaxIdleConns,
+		MaxIdleConnsPerHost:   opt.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opt.MaxConnsPerHost,
+		DisableKeepAlives:     opt.DisableKeepAlives,
+	}
+
+	switch {
+	case opt.DisableProxy:
+	case opt.Proxy != "":
+		u, err := url.Parse(opt.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("requester: invalid proxy %q", opt.Proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	default:
+		t.Proxy = http.ProxyFromEnvironment
+	}
+
+	if opt.UnixSocket != "" {
+		path := opt.UnixSocket
+		t.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+		t.Proxy = nil
+	}
+
+	if opt.TLSConfig != nil || opt.Certificates != nil || opt.RootCAs != nil || opt.InsecureSkipVerify {
+		cfg := &tls.Config{}
+		if opt.TLSConfig != nil {
+			cfg = opt.TLSConfig.Clone()
+		}
+		if opt.Certificates != nil {
+			cfg.Certificates = opt.Certificates
+		}
+		if opt.RootCAs != nil {
+			cfg.RootCAs = opt.RootCAs
+		}
+		if opt.InsecureSkipVerify {
+			cfg.InsecureSkipVerify = true
+		}
+		t.TLSClientConfig = cfg
+	}
+
+	if opt.ForceHTTP2 {
+		forceHTTP2(t)
+	}
+	return t, nil
+}
+
+// forceHTTP2 只通过 ALPN 声明 h2，并在握手后、发送请求前校验协商结果。
+// 经代理的 HTTPS 请求不走 DialTLSContext，无法在发送前校验，因此同时禁用代理。
+func forceHTTP2(t *http.Transport) {
+	cfg := &tls.Config{}
+	if t.TLSClientConfig != nil {
+		cfg = t.TLSClientConfig.Clone()
+	}
+	cfg.NextProtos = []string{"h2"}
+	t.TLSClientConfig = cfg
+	t.Proxy = nil
+
+	dial := t.DialContext
+	timeout := t.TLSHandshakeTimeout
+	t.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		c := cfg.Clone()
+		if c.ServerName == "" {
+			c.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		hctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		tc := tls.Client(conn, c)
+		if err := tc.HandshakeContext(hctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if p := tc.ConnectionState().NegotiatedProtocol; p != "h2" {
+			tc.Close()
+			return nil, fmt.Errorf("%w: negotiated %q", ErrHTTP2Required, p)
+		}
+		return tc, nil
+	}
+}
+
+// NewRequester 创建使用该客户端发送的 Requester，并带上默认请求头。
+func (c *Client) NewRequester() *Requester {
+	h := NewRequester()
+	h.client = c
+	for k, v := range c.header {
+		h.header[k] = append([]string(nil), v...)
+	}
+	return h
+}
+
+// HTTPClient 返回底层 http.Client。
+func (c *Client) HTTPClient() *http.Client {
+	return c.client
+}
+
+// CloseIdleConnections 关闭连接池中的空闲连接。
+func (c *Client) CloseIdleConnections() {
+	c.client.CloseIdleConnections()
+}
+
+// resolve 把相对地址拼接到 BaseURL 之后：路径追加在 BaseURL 的路径后，query 追加在 BaseURL 的 query 后。
+func (c *Client) resolve(u string) string {
+	if c.base == nil {
+		return u
+	}
+	ref, err := url.Parse(u)
+	if err != nil || ref.IsAbs() || ref.Host != "" {
+		// 解析失败时原样交给 http.NewRequest 报错
+		return u
+	}
+	out := *c.base
+	if p := strings.TrimLeft(ref.Path, "/"); p != "" {
+		out.Path = strings.TrimRight(out.Path, "/") + "/" + p
+		out.RawPath = ""
+	}
+	switch {
+	case out.RawQuery == "":
+		out.RawQuery = ref.RawQuery
+	case ref.RawQuery != "":
+		out.RawQuery += "&" + ref.RawQuery
+	}
+	out.Fragment = ref.Fragment
+	return out.String()
+}
+
+// httpsOnly 不支持 h2c，明文请求在发送前直接拒绝。
+type httpsOnly struct {
+	rt http.RoundTripper
+}
+
+func (t httpsOnly) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s is not https", ErrHTTP2Required, req.URL.Redacted())
+	}
+	return t.rt.RoundTrip(req)
+}
@@ -0,0 +1,176 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientResolve(t *testing.T) {
+	tests := []struct {
+		base, u, want string
+	}{
+		{"http://api.test/v1", "users", "http://api.test/v1/users"},
+		{"http://api.test/v1/", "/users", "http://api.test/v1/users"},
+		{"http://api.test/v1", "", "http://api.test/v1"},
+		{"http://api.test/v1", "?page=2", "http://api.test/v1?page=2"},
+		{"http://api.test/v1?key=k", "users?page=2", "http://api.test/v1/users?key=k&page=2"},
+		{"http://api.test", "/login?next=http://x", "http://api.test/login?next=http://x"},
+		{"http://api.test/v1", "https://other.test/a", "https://other.test/a"},
+		{"http://api.test/v1", "//other.test/a", "//other.test/a"},
+	}
+	for _, tt := range tests {
+		c, err := NewClient(ClientOption{BaseURL: tt.base})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.resolve(tt.u); got != tt.want {
+			t.Errorf("resolve(%q, %q) = %q, want %q", tt.base, tt.u, got, tt.want)
+		}
+	}
+}
+
+func TestNewClientInvalidOptions(t *testing.T) {
+	tests := []ClientOption{
+		{BaseURL: "/relative"},
+		{Proxy: "://bad"},
+		{ForceHTTP2: true, Proxy: "http://127.0.0.1:1"},
+		{ForceHTTP2: true, Transport: http.DefaultTransport},
+	}
+	for _, opt := range tests {
+		if _, err := NewClient(opt); err == nil {
+			t.Errorf("NewClient(%+v) succeeded", opt)
+		}
+	}
+}
+
+func TestClientDefaultsAndInterceptors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path + " " + r.Header.Get("X-Default") + " " + r.Header.Get("X-Hook")))
+	}))
+	defer srv.Close()
+
+	var order []string
+	c, err := NewClient(ClientOption{
+		BaseURL: srv.URL + "/api",
+		Header:  http.Header{"X-Default": {"d"}},
+		Interceptors: []Interceptor{OnBefore(func(r *http.Request) error {
+			order = append(order, "client")
+			r.Header.Set("X-Hook", "h")
+			return nil
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.NewRequester().Get("ping").Use(OnBefore(func(r *http.Request) error {
+		order = append(order, "requester")
+		return nil
+	}))
+	defer r.Close()
+	if got := r.AsString(context.Background()); got != "/api/ping d h" {
+		t.Fatalf("body = %q", got)
+	}
+	if len(order) != 2 || order[0] != "client" || order[1] != "requester" {
+		t.Fatalf("interceptor order = %v", order)
+	}
+}
+
+func TestClientForceHTTP2(t *testing.T) {
+	var calls atomic.Int32
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(r.Proto))
+	})
+
+	h2 := httptest.NewUnstartedServer(handler)
+	h2.EnableHTTP2 = true
+	h2.StartTLS()
+	defer h2.Close()
+	h1 := httptest.NewTLSServer(handler)
+	defer h1.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	c, err := NewClient(ClientOption{ForceHTTP2: true, RootCAs: h2.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.NewRequester().Get(h2.URL)
+	if got := r.AsString(context.Background()); got != "HTTP/2.0" {
+		t.Fatalf("proto = %q, err = %v", got, r.GetErr())
+	}
+	r.Close()
+
+	// 未协商 h2 或明文请求都必须在发送前失败
+	calls.Store(0)
+	for _, u := range []string{h1.URL, plain.URL} {
+		c, _ := NewClient(ClientOption{ForceHTTP2: true, InsecureSkipVerify: true})
+		r := c.NewRequester().Post(u, nil)
+		if _, err := r.Response(context.Background()); !errors.Is(err, ErrHTTP2Required) {
+			t.Fatalf("%s: err = %v", u, err)
+		}
+		r.Close()
+	}
+	if n := calls.Load(); n != 0 {
+		t.Fatalf("server handled %d requests", n)
+	}
+}
+
+func TestClientTransportOptions(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Write([]byte(r.Host + r.URL.Path))
+	})
+
+	sock := filepath.Join(t.TempDir(), "api.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skip("unix socket unavailable:", err)
+	}
+	unixSrv := &http.Server{Handler: handler}
+	go unixSrv.Serve(ln)
+	defer unixSrv.Close()
+
+	// 代理收到的是完整 URL
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+	tcp := httptest.NewServer(handler)
+	defer tcp.Close()
+
+	tests := []struct {
+		name    string
+		opt     ClientOption
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"unix socket", ClientOption{UnixSocket: sock, BaseURL: "http://docker"}, "/v1/info", "docker/v1/info", false},
+		{"proxy", ClientOption{Proxy: proxy.URL}, "http://upstream.test/a", "proxied http://upstream.test/a", false},
+		{"timeout", ClientOption{Timeout: 50 * time.Millisecond}, tcp.URL + "/slow", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient(tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := c.NewRequester().Get(tt.url)
+			defer r.Close()
+			got := r.AsString(context.Background())
+			if (r.GetErr() != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("body = %q, err = %v", got, r.GetErr())
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cookieSaveDelay 文件容器在 cookie 变化后延迟写回的时间，期间的多次变化合并为一次写入。
+const cookieSaveDelay = time.Second
+
+// CookieJar 可持久化的 cookie 容器。匹配规则交给 net/http/cookiejar，本类型额外记录收到的 cookie 以便保存到文件。
+type CookieJar struct {
+	mu      sync.Mutex
+	jar     *cookiejar.Jar
+	path    string
+	entries map[string]cookieEntry
+	// timer 待执行的延迟写回
+	timer *time.Timer
+
+	// saveMu 串行化写文件，保证后取的快照后写入
+	saveMu sync.Mutex
+}
+
+type cookieEntry struct {
+	URL      string        `json:"url"`
+	Name     string        `json:"name"`
+	Value    string        `json:"value"`
+	Domain   string        `json:"domain,omitempty"`
+	Path     string        `json:"path,omitempty"`
+	Expires  time.Time     `json:"expires,omitempty"`
+	Secure   bool          `json:"secure,omitempty"`
+	HttpOnly bool          `json:"http_only,omitempty"`
+	SameSite http.SameSite `json:"same_site,omitempty"`
+}
+
+// NewCookieJar 创建内存 cookie 容器。
+func NewCookieJar() *CookieJar {
+	jar, _ := cookiejar.New(nil)
+	return &CookieJar{jar: jar, entries: make(map[string]cookieEntry)}
+}
+
+// NewFileCookieJar 创建持久化到 path 的 cookie 容器：文件存在时先加载，cookie 变化后延迟约 1s 自动写回。
+// 会话 cookie（未设置过期时间）同样会被保存；退出前调用 Save 确保最新的 cookie 落盘。
+func NewFileCookieJar(path string) (*CookieJar, error) {
+	j := NewCookieJar()
+	j.path = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return j, nil
+		}
+		return nil, err
+	}
+	var entries []cookieEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, e := range entries {
+		if !e.Expires.IsZero() && !e.Expires.After(now) {
+			continue
+		}
+		u, err := url.Parse(e.URL)
+		if err != nil {
+			continue
+		}
+		c := e.cookie()
+		j.jar.SetCookies(u, []*http.Cookie{c})
+		j.entries[entryKey(u, c)] = e
+	}
+	return j, nil
+}
+
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+
+	now := time.Now()
+	origin := (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	for _, c := range cookies {
+		key := entryKey(u, c)
+		expires := c.Expires
+		switch {
+		case c.MaxAge < 0:
+			delete(j.entries, key)
+			continue
+		case c.MaxAge > 0:
+			expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		if !expires.IsZero() && !expires.After(now) {
+			delete(j.entries, key)
+			continue
+		}
+		j.entries[key] = cookieEntry{
+			URL:      origin,
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  expires,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+			SameSite: c.SameSite,
+		}
+	}
+	if j.path != "" && j.timer == nil {
+		j.timer = time.AfterFunc(cookieSaveDelay, func() { _ = j.Save() })
+	}
+}
+
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	jar := j.jar
+	j.mu.Unlock()
+	return jar.Cookies(u)
+}
+
+// Save 立即把当前 cookie 写入文件（并取消待执行的延迟写回）；内存容器调用时无操作。
+func (j *CookieJar) Save() error {
+	if j.path == "" {
+		return nil
+	}
+	j.saveMu.Lock()
+	defer j.saveMu.Unlock()
+
+	j.mu.Lock()
+	if j.timer != nil {
+		j.timer.Stop()
+		j.timer = nil
+	}
+	now := time.Now()
+	entries := make([]cookieEntry, 0, len(j.entries))
+	for key, e := range j.entries {
+		if !e.Expires.IsZero() && !e.Expires.After(now) {
+			delete(j.entries, key)
+			continue
+		}
+		entries = append(entries, e)
+	}
+	j.mu.Unlock()
+	return j.write(entries)
+}
+
+// Clear 清空所有 cookie，文件容器同时立即写回。
+func (j *CookieJar) Clear() error {
+	jar, _ := cookiejar.New(nil)
+	j.mu.Lock()
+	j.jar = jar
+	j.entries = make(map[string]cookieEntry)
+	j.mu.Unlock()
+	return j.Save()
+}
+
+// write 先写临时文件再改名，避免写到一半的文件。
+func (j *CookieJar) write(entries []cookieEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), j.path)
+}
+
+func (e *cookieEntry) cookie() *http.Cookie {
+	return &http.Cookie{
+		Name:     e.Name,
+		Value:    e.Value,
+		Domain:   e.Domain,
+		Path:     e.Path,
+		Expires:  e.Expires,
+		Secure:   e.Secure,
+		HttpOnly: e.HttpOnly,
+		SameSite: e.SameSite,
+	}
+}
+
+// entryKey 以 (name, domain, path) 区分 cookie，与 cookiejar 的覆盖规则一致。
+func entryKey(u *url.URL, c *http.Cookie) string {
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if domain == "" {
+		domain = "host:" + strings.ToLower(u.Hostname())
+	}
+	path := c.Path
+	if path == "" || path[0] != '/' {
+		path = u.Path
+		if i := strings.LastIndex(path, "/"); i > 0 {
+			path = path[:i]
+		} else {
+			path = "/"
+		}
+	}
+	return c.Name + ";" + domain + ";" + path
+}
@@ -0,0 +1,94 @@
+package requester_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func TestFileCookieJar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	jar, err := requester.NewFileCookieJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse("https://example.com/app/login")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "sid", Value: "1", Path: "/"},
+		{Name: "pref", Value: "dark", Path: "/", Expires: time.Now().Add(time.Hour)},
+		{Name: "gone", Value: "x", Path: "/", MaxAge: -1},
+	})
+	// 延迟写回：SetCookies 不同步写文件
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("cookie file written synchronously: %v", err)
+	}
+	if err := jar.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := requester.NewFileCookieJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, c := range loaded.Cookies(u) {
+		got[c.Name] = c.Value
+	}
+	if len(got) != 2 || got["sid"] != "1" || got["pref"] != "dark" {
+		t.Fatalf("loaded cookies = %v", got)
+	}
+
+	if err := loaded.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	cleared, _ := requester.NewFileCookieJar(path)
+	if n := len(cleared.Cookies(u)); n != 0 {
+		t.Fatalf("%d cookies after Clear", n)
+	}
+}
+
+func TestSessionKeepsCookies(t *testing.T) {
+	srv := newCookieServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "cookies.json")
+	s, err := requester.NewSession(requester.SessionOption{
+		ClientOption: requester.ClientOption{BaseURL: srv.URL},
+		CookieFile:   path,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/login", "/me"} {
+		r := s.NewRequester().Get(p)
+		if got := r.AsString(context.Background()); p == "/me" && got != "alice" {
+			t.Fatalf("/me = %q", got)
+		}
+		r.Close()
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) == 0 {
+		t.Fatalf("cookie file: %q, %v", data, err)
+	}
+}
+
+func newCookieServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "user", Value: "alice", Path: "/"})
+			return
+		}
+		if c, err := r.Cookie("user"); err == nil {
+			w.Write([]byte(c.Value))
+		}
+	}))
+}
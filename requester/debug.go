@@ -11,22 +11,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"text/template"
 
 	"github.com/Rehtt/Kit/bytes"
 )
 
+// Debug 开启后把请求与响应打印到标准输出，等同于在拦截器链最内层追加 DebugInterceptor(os.Stdout)。
 func (h *Requester) Debug(debug bool) *Requester {
 	h.debug = debug
 	return h
 }
 
-func (h *Requester) printRequestDebug(req *http.Request) {
-	if !h.debug {
-		return
-	}
-	templ := `
+var (
+	debugRequestTempl = template.Must(template.New("request").Parse(`
 
 Request
 	url:	{{.URL}}
@@ -42,7 +39,39 @@ Request
 	{{.Body}}
 	--- body end ---
 	{{- end}}
-	`
+	`))
+	debugResponseTempl = template.Must(template.New("response").Parse(`
+Response
+	{{- range $k,$v := .Header }}
+		{{- range $v }}
+	header:	{{$k}}:{{.}}
+		{{- end}}
+	{{- end }}
+	body:	{{if eq .Body ""}}<nil>
+	{{- else}}
+	--- body start ---
+	{{.Body}}
+	--- body end ---
+	{{- end}}
+
+	`))
+)
+
+// DebugInterceptor 把请求与响应（含 Header 与 Body）打印到 w；Body 读取后会回填，不影响后续读取。
+func DebugInterceptor(w io.Writer) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		printRequestDebug(w, req)
+		resp, err := next(req)
+		if err != nil {
+			fmt.Fprintf(w, "\nResponse\n\terror:\t%v\n\n", err)
+			return nil, err
+		}
+		printResponseDebug(w, resp)
+		return resp, nil
+	}
+}
+
+func printRequestDebug(w io.Writer, req *http.Request) {
 	data := struct {
 		*http.Request
 		Body string
@@ -56,31 +85,12 @@ Request
 		req.Body.Close()
 		req.Body = &buf
 	}
-	err := template.Must(template.New("debug").Parse(templ)).Execute(os.Stdout, data)
-	if err != nil {
-		fmt.Println(err)
+	if err := debugRequestTempl.Execute(w, data); err != nil {
+		fmt.Fprintln(w, err)
 	}
 }
 
-func (h *Requester) printResponseDebug(resp *http.Response) {
-	if !h.debug {
-		return
-	}
-	templ := `
-Response
-	{{- range $k,$v := .Header }}
-		{{- range $v }}
-	header:	{{$k}}:{{.}}
-		{{- end}}
-	{{- end }}
-	body:	{{if eq .Body ""}}<nil>
-	{{- else}}
-	--- body start ---
-	{{.Body}}
-	--- body end ---
-	{{- end}}
-
-	`
+func printResponseDebug(w io.Writer, resp *http.Response) {
 	data := struct {
 		*http.Response
 		Body string
@@ -94,9 +104,7 @@ Response
 		resp.Body.Close()
 		resp.Body = &buf
 	}
-
-	err := template.Must(template.New("debug").Parse(templ)).Execute(os.Stdout, data)
-	if err != nil {
-		fmt.Println(err)
+	if err := debugResponseTempl.Execute(w, data); err != nil {
+		fmt.Fprintln(w, err)
 	}
 }
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DownloadOption 下载配置。
+type DownloadOption struct {
+	// Resume 目标文件已存在时，通过 Range 从文件末尾继续下载；服务端不支持 Range 时从头下载
+	Resume bool
+	// Progress 进度回调，written 含续传前已有的部分，total 未知时为 -1
+	Progress func(written, total int64)
+	// Perm 新建文件的权限，默认 0644
+	Perm os.FileMode
+}
+
+// AsWriter 发送请求并把响应体写入 w，返回写入的字节数；progress 可为 nil。
+func (h *Requester) AsWriter(ctx context.Context, w io.Writer, progress func(written, total int64)) (int64, error) {
+	resp, err := h.Response(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, fmt.Errorf("requester: unexpected status %s", resp.Status)
+	}
+	return copyProgress(w, resp.Body, 0, resp.ContentLength, progress)
+}
+
+// Download 发送请求并把响应体保存到 path。开启 Resume 时中断后再次调用即可续传。
+func (h *Requester) Download(ctx context.Context, path string, opts ...DownloadOption) error {
+	opt := DownloadOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Perm == 0 {
+		opt.Perm = 0o644
+	}
+
+	var offset int64
+	if opt.Resume {
+		if fi, err := os.Stat(path); err == nil && fi.Size() > 0 {
+			offset = fi.Size()
+			h.SetHead("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		}
+	}
+
+	resp, err := h.Response(ctx)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	total := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return fmt.Errorf("requester: download: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		flag = os.O_WRONLY | os.O_APPEND
+		total = size
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// 已下载完整
+		if _, size, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && size == offset {
+			if opt.Progress != nil {
+				opt.Progress(offset, offset)
+			}
+			return nil
+		}
+		return fmt.Errorf("requester: download: unexpected status %s", resp.Status)
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		// 服务端忽略了 Range，从头下载
+		offset = 0
+	default:
+		return fmt.Errorf("requester: download: unexpected status %s", resp.Status)
+	}
+
+	f, err := os.OpenFile(path, flag, opt.Perm)
+	if err != nil {
+		return err
+	}
+	if _, err := copyProgress(f, resp.Body, offset, total, opt.Progress); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// parseContentRange 解析 "bytes start-end/size" 或 "bytes */size"，size 未知（*）时为 -1。
+func parseContentRange(v string) (start, size int64, ok bool) {
+	v, ok = strings.CutPrefix(v, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	rng, sz, ok := strings.Cut(v, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	size = -1
+	if sz != "*" {
+		n, err := strconv.ParseInt(sz, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		size = n
+	}
+	if rng == "*" {
+		return 0, size, true
+	}
+	s, _, ok := strings.Cut(rng, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return n, size, true
+}
+
+// copyProgress 从 r 复制到 w，written 从 offset 开始计数。
+func copyProgress(w io.Writer, r io.Reader, offset, total int64, progress func(written, total int64)) (int64, error) {
+	if progress == nil {
+		return io.Copy(w, r)
+	}
+	return io.Copy(w, &progressReader{r: r, sent: offset, total: total, f: progress})
+}
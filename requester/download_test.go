@@ -0,0 +1,95 @@
+package requester_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+const downloadContent = "0123456789abcdefghij"
+
+func TestDownloadResume(t *testing.T) {
+	ranged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "f", time.Time{}, strings.NewReader(downloadContent))
+	}))
+	defer ranged.Close()
+	// 忽略 Range 的服务端
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(downloadContent))
+	}))
+	defer plain.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		existing string
+		resume   bool
+	}{
+		{"fresh", ranged.URL, "", true},
+		{"partial 206", ranged.URL, downloadContent[:8], true},
+		{"complete 416", ranged.URL, downloadContent, true},
+		{"range ignored 200", plain.URL, downloadContent[:8], true},
+		{"no resume truncates", ranged.URL, "garbage-garbage-garbage", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out")
+			if tt.existing != "" {
+				os.WriteFile(path, []byte(tt.existing), 0o644)
+			}
+			var written, total int64
+			r := requester.NewRequester().Get(tt.url)
+			defer r.Close()
+			err := r.Download(context.Background(), path, requester.DownloadOption{
+				Resume:   tt.resume,
+				Progress: func(w, t int64) { written, total = w, t },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != downloadContent {
+				t.Fatalf("file = %q", got)
+			}
+			if written != int64(len(downloadContent)) || total != written {
+				t.Fatalf("progress written=%d total=%d", written, total)
+			}
+		})
+	}
+}
+
+func TestDownloadBadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "out")
+	r := requester.NewRequester().Get(srv.URL)
+	defer r.Close()
+	if err := r.Download(context.Background(), path); err == nil {
+		t.Fatal("expected error on 404")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file created on error: %v", err)
+	}
+}
+
+func TestAsWriter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(downloadContent))
+	}))
+	defer srv.Close()
+	var buf bytes.Buffer
+	r := requester.NewRequester().Get(srv.URL)
+	defer r.Close()
+	n, err := r.AsWriter(context.Background(), &buf, nil)
+	if err != nil || n != int64(len(downloadContent)) || buf.String() != downloadContent {
+		t.Fatalf("n=%d err=%v buf=%q", n, err, buf.String())
+	}
+}
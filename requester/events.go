@@ -0,0 +1,293 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultMaxLineSize = 4 << 20
+
+// ErrBodyNotReplayable 请求体只能读取一次，无法在重连时重新发送。
+var ErrBodyNotReplayable = errors.New("requester: request body is not replayable")
+
+// MaxLineSize 设置流式读取时单行的最大长度，默认 4MB，超出时返回 bufio.ErrTooLong。
+func (h *Requester) MaxLineSize(n int) *Requester {
+	h.maxLine = n
+	return h
+}
+
+func (h *Requester) maxLineSize() int {
+	if h.maxLine > 0 {
+		return h.maxLine
+	}
+	return defaultMaxLineSize
+}
+
+// Event SSE 事件，字段与 web/sse.Event 一致。
+type Event struct {
+	// Event 事件类型，服务端未指定时为 "message"
+	Event string
+	// ID 最近一次收到的事件 ID（Last-Event-ID）
+	ID string
+	// Retry 该事件携带的重连间隔，未携带时为 0
+	Retry time.Duration
+	Data  string
+}
+
+// EventsOption SSE 客户端配置。
+type EventsOption struct {
+	// LastEventID 首次连接时发送的 Last-Event-ID
+	LastEventID string
+	// RetryDelay 默认重连间隔，服务端 retry 字段会覆盖它，默认 3s
+	RetryDelay time.Duration
+	// NoReconnect 连接断开后不重连
+	NoReconnect bool
+	// MaxReconnects 连续重连失败的上限，超过后返回最后一次错误；0 表示不限制
+	MaxReconnects int
+	// OnReconnect 重连前回调，err 为导致断开的错误（正常结束时为 nil）
+	OnReconnect func(attempt int, lastEventID string, err error)
+}
+
+// Events 以 SSE 客户端方式读取事件流：解析 event/id/retry/data 字段，断开后携带 Last-Event-ID 自动重连。
+// 服务端返回 204 时正常结束；返回其他非 200 状态或非 text/event-stream 时产出错误并结束；ctx 取消时结束。
+func (h *Requester) Events(ctx context.Context, opts ...EventsOption) iter.Seq2[Event, error] {
+	opt := EventsOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.RetryDelay <= 0 {
+		opt.RetryDelay = 3 * time.Second
+	}
+
+	return func(yield func(Event, error) bool) {
+		if h.err != nil {
+			yield(Event{}, h.err)
+			return
+		}
+		h.SetHead("Accept", "text/event-stream")
+		h.SetHead("Cache-Control", "no-cache")
+		p := eventParser{id: opt.LastEventID, lastID: opt.LastEventID, retry: opt.RetryDelay}
+		failures := 0
+		for {
+			if p.lastID != "" {
+				h.SetHead("Last-Event-ID", p.lastID)
+			}
+			received, stop, err := h.readEvents(ctx, &p, yield)
+			if stop || ctx.Err() != nil {
+				return
+			}
+			if received {
+				failures = 0
+			} else {
+				failures++
+			}
+			if opt.NoReconnect || (opt.MaxReconnects > 0 && failures > opt.MaxReconnects) {
+				if err != nil {
+					yield(Event{}, err)
+				}
+				return
+			}
+			if !h.bodyReplayable() {
+				yield(Event{}, errors.Join(ErrBodyNotReplayable, err))
+				return
+			}
+			if opt.OnReconnect != nil {
+				opt.OnReconnect(failures, p.lastID, err)
+			}
+			if sleepCtx(ctx, p.retry) != nil {
+				return
+			}
+		}
+	}
+}
+
+// readEvents 建立一次连接并读取事件；received 表示本次连接至少收到过数据，stop 表示不应再重连。
+func (h *Requester) readEvents(ctx context.Context, p *eventParser, yield func(Event, error) bool) (received, stop bool, err error) {
+	resp, err := h.do(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, true, nil
+	case resp.StatusCode != http.StatusOK:
+		yield(Event{}, fmt.Errorf("requester: event stream: unexpected status %s", resp.Status))
+		return false, true, nil
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/event-stream" {
+		yield(Event{}, fmt.Errorf("requester: event stream: unexpected content-type %q", resp.Header.Get("Content-Type")))
+		return false, true, nil
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(nil, h.maxLineSize())
+	sc.Split(scanEventLines)
+	first := true
+	for sc.Scan() {
+		received = true
+		line := sc.Bytes()
+		if first {
+			line = bytes.TrimPrefix(line, []byte("\xef\xbb\xbf"))
+			first = false
+		}
+		if ev, ok := p.line(line); ok && !yield(ev, nil) {
+			return true, true, nil
+		}
+	}
+	// 未完成的事件丢弃，其 id 也不生效
+	p.reset()
+	p.id = p.lastID
+	return received, false, sc.Err()
+}
+
+// eventParser 按 HTML Living Standard 的 event stream 解析规则逐行解析。
+type eventParser struct {
+	// id 为 last event ID buffer，事件派发时才写入 lastID
+	id         string
+	lastID     string
+	retry      time.Duration
+	event      string
+	data       strings.Builder
+	hasData    bool
+	eventRetry time.Duration
+}
+
+func (p *eventParser) line(line []byte) (Event, bool) {
+	if len(line) == 0 {
+		return p.dispatch()
+	}
+	if line[0] == ':' {
+		return Event{}, false
+	}
+	field, value, _ := bytes.Cut(line, []byte(":"))
+	value = bytes.TrimPrefix(value, []byte(" "))
+	switch string(field) {
+	case "event":
+		p.event = string(value)
+	case "data":
+		if p.hasData {
+			p.data.WriteByte('\n')
+		}
+		p.data.Write(value)
+		p.hasData = true
+	case "id":
+		if bytes.IndexByte(value, 0) < 0 {
+			p.id = string(value)
+		}
+	case "retry":
+		if n, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+			p.retry = time.Duration(n) * time.Millisecond
+			p.eventRetry = p.retry
+		}
+	}
+	return Event{}, false
+}
+
+func (p *eventParser) dispatch() (Event, bool) {
+	defer p.reset()
+	p.lastID = p.id
+	if !p.hasData {
+		return Event{}, false
+	}
+	ev := Event{Event: p.event, ID: p.lastID, Retry: p.eventRetry, Data: p.data.String()}
+	if ev.Event == "" {
+		ev.Event = "message"
+	}
+	return ev, true
+}
+
+// reset 丢弃未完成的事件，lastID 与 retry 保留到下次连接。
+func (p *eventParser) reset() {
+	p.event = ""
+	p.data.Reset()
+	p.hasData = false
+	p.eventRetry = 0
+}
+
+// scanEventLines 按 \r\n、\n 或单独的 \r 分行。
+func scanEventLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// 需要下一个字节判断是否为 \r\n
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// JSONLines 把 NDJSON / JSON Lines 响应逐行解码为 T，跳过空行。
+// 某一行解码失败时产出该错误，调用方可以选择继续迭代；读取失败时产出错误并结束。
+func JSONLines[T any](ctx context.Context, r *Requester) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		if r.err != nil {
+			yield(zero, r.err)
+			return
+		}
+		r.SetHead("Accept", "application/x-ndjson, application/jsonl")
+		resp, err := r.do(ctx)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			yield(zero, fmt.Errorf("requester: json lines: unexpected status %s", resp.Status))
+			return
+		}
+
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(nil, r.maxLineSize())
+		for sc.Scan() {
+			line := bytes.TrimSpace(sc.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var v T
+			if err := json.Unmarshal(line, &v); err != nil {
+				if !yield(zero, err) {
+					return
+				}
+				continue
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := sc.Err(); err != nil && ctx.Err() == nil {
+			yield(zero, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package requester_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func collectEvents(t *testing.T, r *requester.Requester, opt requester.EventsOption) ([]requester.Event, []error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var events []requester.Event
+	var errs []error
+	for ev, err := range r.Events(ctx, opt) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events, errs
+}
+
+func TestEventsParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []requester.Event
+	}{
+		{
+			name:   "default event type",
+			stream: "data: hello\n\n",
+			want:   []requester.Event{{Event: "message", Data: "hello"}},
+		},
+		{
+			name:   "multiline data, id and event",
+			stream: "event: tick\nid: 7\ndata: a\ndata: b\n\n",
+			want:   []requester.Event{{Event: "tick", ID: "7", Data: "a\nb"}},
+		},
+		{
+			name:   "comments, CR and CRLF line endings, BOM",
+			stream: "\xef\xbb\xbf: ping\r\ndata:x\r\rdata: y\r\n\r\n",
+			want:   []requester.Event{{Event: "message", Data: "x"}, {Event: "message", Data: "y"}},
+		},
+		{
+			name:   "retry and id persist, unfinished event dropped",
+			stream: "retry: 1500\nid: 1\ndata: a\n\ndata: b\n\nid: 9\ndata: lost",
+			want: []requester.Event{
+				{Event: "message", ID: "1", Retry: 1500 * time.Millisecond, Data: "a"},
+				{Event: "message", ID: "1", Data: "b"},
+			},
+		},
+		{
+			name:   "event without data is not dispatched",
+			stream: "event: empty\n\ndata: z\n\n",
+			want:   []requester.Event{{Event: "message", Data: "z"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, tt.stream)
+			}))
+			defer srv.Close()
+
+			r := requester.NewRequester().Get(srv.URL)
+			got, errs := collectEvents(t, r, requester.EventsOption{NoReconnect: true})
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventsReconnectWithLastEventID(t *testing.T) {
+	var conns atomic.Int32
+	var lastIDs []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := conns.Add(1)
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		if n == 3 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "retry: 1\nid: %d\ndata: e%d\n\n", n, n)
+	}))
+	defer srv.Close()
+
+	var reconnects int
+	r := requester.NewRequester().Get(srv.URL)
+	got, errs := collectEvents(t, r, requester.EventsOption{
+		LastEventID: "0",
+		OnReconnect: func(int, string, error) { reconnects++ },
+	})
+	if len(errs) > 0 || len(got) != 2 || got[1].Data != "e2" {
+		t.Fatalf("events = %+v, errs = %v", got, errs)
+	}
+	if fmt.Sprint(lastIDs) != "[0 1 2]" || reconnects != 2 {
+		t.Fatalf("Last-Event-ID = %v, reconnects = %d", lastIDs, reconnects)
+	}
+}
+
+func TestEventsStopsOnBadResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+	}{
+		{"status", http.StatusInternalServerError, "text/event-stream"},
+		{"content type", http.StatusOK, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			_, errs := collectEvents(t, requester.NewRequester().Get(srv.URL), requester.EventsOption{})
+			if len(errs) != 1 {
+				t.Fatalf("errs = %v", errs)
+			}
+		})
+	}
+}
+
+func TestEventsBuilderError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) }))
+	defer srv.Close()
+
+	r := requester.NewRequester().PostJSON(srv.URL, func() {})
+	_, errs := collectEvents(t, r, requester.EventsOption{})
+	if len(errs) != 1 || calls.Load() != 0 {
+		t.Fatalf("errs = %v, calls = %d", errs, calls.Load())
+	}
+
+	r = requester.NewRequester().PostJSON(srv.URL, func() {})
+	for _, err := range requester.JSONLines[int](context.Background(), r) {
+		if err == nil {
+			t.Fatal("JSONLines ignored builder error")
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatal("request sent despite builder error")
+	}
+}
+
+func TestEventsNoReconnectWithOneShotBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "retry: 1\ndata: a\n\n")
+	}))
+	defer srv.Close()
+
+	r := requester.NewRequester().Post(srv.URL, io.MultiReader(strings.NewReader("q")))
+	got, errs := collectEvents(t, r, requester.EventsOption{})
+	if len(got) != 1 || len(errs) != 1 || !errors.Is(errs[0], requester.ErrBodyNotReplayable) || calls.Load() != 1 {
+		t.Fatalf("events = %v, errs = %v, calls = %d", got, errs, calls.Load())
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "{\"n\":1}\n\n{bad}\n{\"n\":2}\n")
+	}))
+	defer srv.Close()
+
+	type item struct{ N int }
+	var got []int
+	var bad int
+	for v, err := range requester.JSONLines[item](context.Background(), requester.NewRequester().Get(srv.URL)) {
+		if err != nil {
+			bad++
+			continue
+		}
+		got = append(got, v.N)
+	}
+	if fmt.Sprint(got) != "[1 2]" || bad != 1 {
+		t.Fatalf("got %v, bad lines %d", got, bad)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	stdbytes "bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// HAR HTTP Archive 1.2（http://www.softwareishard.com/blog/har-12-spec/）。
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData 请求体；非 UTF-8 内容以 base64 保存并设置 Encoding（常见扩展字段）。
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// LoadHAR 读取 HAR 文件。
+func LoadHAR(path string) (*HAR, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var h HAR
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// HARRecorderOption 录制配置。
+type HARRecorderOption struct {
+	// RedactHeaders 写入前替换为 "REDACTED" 的请求/响应头，默认 Authorization、Proxy-Authorization
+	RedactHeaders []string
+}
+
+// HARRecorder 以拦截器方式录制请求与响应。录制时会完整读取响应体（读取后回填），不适合长连接流式响应。
+type HARRecorder struct {
+	redact []string
+
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+func NewHARRecorder(opts ...HARRecorderOption) *HARRecorder {
+	opt := HARRecorderOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.RedactHeaders == nil {
+		opt.RedactHeaders = []string{"Authorization", "Proxy-Authorization"}
+	}
+	redact := make([]string, len(opt.RedactHeaders))
+	for i, k := range opt.RedactHeaders {
+		redact[i] = http.CanonicalHeaderKey(k)
+	}
+	return &HARRecorder{redact: redact}
+}
+
+// Interceptor 返回录制用的拦截器，挂在 Requester.Use 或 ClientOption.Interceptors 上。
+func (r *HARRecorder) Interceptor() Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		var reqBody []byte
+		if req.Body != nil && req.Body != http.NoBody {
+			data, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			reqBody = data
+			req.Body = io.NopCloser(stdbytes.NewReader(data))
+		}
+
+		start := time.Now()
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(stdbytes.NewReader(respBody))
+		if err != nil {
+			return resp, err
+		}
+		elapsed := float64(time.Since(start).Microseconds()) / 1000
+
+		r.mu.Lock()
+		r.entries = append(r.entries, HAREntry{
+			StartedDateTime: start,
+			Time:            elapsed,
+			Request:         r.harRequest(req, reqBody),
+			Response:        r.harResponse(resp, respBody),
+			Timings:         HARTimings{Send: 0, Wait: elapsed, Receive: 0},
+		})
+		r.mu.Unlock()
+		return resp, nil
+	}
+}
+
+// HAR 返回当前录制内容的快照。
+func (r *HARRecorder) HAR() *HAR {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "github.com/Rehtt/Kit/requester", Version: "1.0"},
+		Entries: slices.Clone(r.entries),
+	}}
+}
+
+// Save 把录制内容写入 HAR 文件。
+func (r *HARRecorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Reset 清空已录制的内容。
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+func (r *HARRecorder) harRequest(req *http.Request, body []byte) HARRequest {
+	hr := HARRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     r.headers(req.Header),
+		QueryString: []HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for _, c := range req.Cookies() {
+		hr.Cookies = append(hr.Cookies, HARNameValue{Name: c.Name, Value: c.Value})
+	}
+	for k, vs := range req.URL.Query() {
+		for _, v := range vs {
+			hr.QueryString = append(hr.QueryString, HARNameValue{Name: k, Value: v})
+		}
+	}
+	slices.SortStableFunc(hr.QueryString, func(a, b HARNameValue) int { return strings.Compare(a.Name, b.Name) })
+	if body != nil {
+		text, enc := encodeHARBody(body)
+		hr.PostData = &HARPostData{MimeType: req.Header.Get("Content-Type"), Text: text, Encoding: enc}
+	}
+	return hr
+}
+
+func (r *HARRecorder) harResponse(resp *http.Response, body []byte) HARResponse {
+	text, enc := encodeHARBody(body)
+	hr := HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     r.headers(resp.Header),
+		Content: HARContent{
+			Size:     int64(len(body)),
+			MimeType: resp.Header.Get("Content-Type"),
+			Text:     text,
+			Encoding: enc,
+		},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for _, c := range resp.Cookies() {
+		hr.Cookies = append(hr.Cookies, HARNameValue{Name: c.Name, Value: c.Value})
+	}
+	return hr
+}
+
+func (r *HARRecorder) headers(h http.Header) []HARNameValue {
+	out := []HARNameValue{}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range h[k] {
+			if slices.Contains(r.redact, k) {
+				v = "REDACTED"
+			}
+			out = append(out, HARNameValue{Name: k, Value: v})
+		}
+	}
+	return out
+}
+
+func encodeHARBody(body []byte) (text, encoding string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeHARBody(text, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(text)
+	}
+	return []byte(text), nil
+}
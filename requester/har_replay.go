@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	stdbytes "bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var ErrHARNoMatch = errors.New("requester: no recorded HAR entry matches request")
+
+// HARReplayOption 回放配置。
+type HARReplayOption struct {
+	// IgnoreBody 匹配时不比较请求体
+	IgnoreBody bool
+	// Match 自定义匹配，设置后替代默认的 method + URL + body 比较；body 为请求体内容
+	Match func(req *http.Request, body []byte, entry *HAREntry) bool
+	// Fallback 没有匹配记录时使用的 RoundTripper，为空时返回 ErrHARNoMatch
+	Fallback http.RoundTripper
+}
+
+// HARReplayer 按录制内容应答请求的 http.RoundTripper，可作为 ClientOption.Transport 使离线测试可重复。
+// 同一请求被录制多次时按录制顺序依次返回，用完后重复返回最后一条。
+type HARReplayer struct {
+	opt HARReplayOption
+
+	mu      sync.Mutex
+	entries []HAREntry
+	used    []bool
+}
+
+func NewHARReplayer(h *HAR, opts ...HARReplayOption) *HARReplayer {
+	opt := HARReplayOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	return &HARReplayer{
+		opt:     opt,
+		entries: h.Log.Entries,
+		used:    make([]bool, len(h.Log.Entries)),
+	}
+}
+
+func (p *HARReplayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	entry, err := p.find(req, body)
+	if err != nil {
+		if p.opt.Fallback != nil {
+			if body != nil {
+				req.Body = io.NopCloser(stdbytes.NewReader(body))
+			}
+			return p.opt.Fallback.RoundTrip(req)
+		}
+		return nil, err
+	}
+	return entry.response(req)
+}
+
+// find 返回第一条未使用的匹配记录，全部用过时返回最后一条匹配记录。
+func (p *HARReplayer) find(req *http.Request, body []byte) (*HAREntry, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last := -1
+	for i := range p.entries {
+		if !p.match(req, body, &p.entries[i]) {
+			continue
+		}
+		if !p.used[i] {
+			p.used[i] = true
+			return &p.entries[i], nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrHARNoMatch, req.Method, req.URL)
+	}
+	return &p.entries[last], nil
+}
+
+func (p *HARReplayer) match(req *http.Request, body []byte, e *HAREntry) bool {
+	if p.opt.Match != nil {
+		return p.opt.Match(req, body, e)
+	}
+	if !strings.EqualFold(req.Method, e.Request.Method) {
+		return false
+	}
+	u, err := url.Parse(e.Request.URL)
+	if err != nil || !sameURL(req.URL, u) {
+		return false
+	}
+	if p.opt.IgnoreBody {
+		return true
+	}
+	var recorded []byte
+	if e.Request.PostData != nil {
+		if recorded, err = decodeHARBody(e.Request.PostData.Text, e.Request.PostData.Encoding); err != nil {
+			return false
+		}
+	}
+	return stdbytes.Equal(body, recorded)
+}
+
+// sameURL 比较 scheme、host、path 与查询参数（忽略参数顺序）。
+func sameURL(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) &&
+		strings.EqualFold(a.Host, b.Host) &&
+		a.EscapedPath() == b.EscapedPath() &&
+		a.Query().Encode() == b.Query().Encode()
+}
+
+func (e *HAREntry) response(req *http.Request) (*http.Response, error) {
+	body, err := decodeHARBody(e.Response.Content.Text, e.Response.Content.Encoding)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	for _, h := range e.Response.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	proto := e.Response.HTTPVersion
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Response.Status, e.Response.StatusText),
+		StatusCode:    e.Response.Status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        header,
+		Body:          io.NopCloser(stdbytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
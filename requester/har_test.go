@@ -0,0 +1,150 @@
+package requester_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func TestHARRecordReplay(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bin":
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		case "/echo":
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		default:
+			fmt.Fprintf(w, "n=%d", n.Add(1))
+		}
+	}))
+
+	rec := requester.NewHARRecorder()
+	c, _ := requester.NewClient(requester.ClientOption{BaseURL: srv.URL, Interceptors: []requester.Interceptor{rec.Interceptor()}})
+	get := func(c *requester.Client, method, path, body string) (string, error) {
+		var b io.Reader
+		if body != "" {
+			b = strings.NewReader(body)
+		}
+		r := c.NewRequester().Post(path, b).SetHead("Authorization", "Bearer secret")
+		if method == http.MethodGet {
+			r.Get(path)
+		}
+		defer r.Close()
+		resp, err := r.Response(context.Background())
+		if err != nil {
+			return "", err
+		}
+		data, _ := io.ReadAll(resp.Body)
+		return string(data), nil
+	}
+
+	steps := []struct{ method, path, body string }{
+		{"GET", "/count?b=2&a=1", ""},
+		{"GET", "/count?b=2&a=1", ""},
+		{"GET", "/bin", ""},
+		{"POST", "/echo", "x"},
+		{"POST", "/echo", "y"},
+	}
+	var live []string
+	for _, s := range steps {
+		got, err := get(c, s.method, s.path, s.body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		live = append(live, got)
+	}
+	srv.Close()
+
+	har := rec.HAR()
+	if len(har.Log.Entries) != len(steps) {
+		t.Fatalf("recorded %d entries", len(har.Log.Entries))
+	}
+	for _, h := range har.Log.Entries[0].Request.Headers {
+		if h.Name == "Authorization" && h.Value != "REDACTED" {
+			t.Fatalf("Authorization not redacted: %q", h.Value)
+		}
+	}
+	if e := har.Log.Entries[2].Response.Content; e.Encoding != "base64" {
+		t.Fatalf("binary body encoding = %q", e.Encoding)
+	}
+
+	path := filepath.Join(t.TempDir(), "rec.har")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := requester.LoadHAR(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replay, _ := requester.NewClient(requester.ClientOption{BaseURL: srv.URL, Transport: requester.NewHARReplayer(loaded)})
+	// 查询参数顺序不同也能匹配；同一请求按录制顺序返回
+	order := []int{4, 3, 0, 1, 2}
+	for _, i := range order {
+		s := steps[i]
+		p := s.path
+		if i < 2 {
+			p = "/count?a=1&b=2"
+		}
+		got, err := get(replay, s.method, p, s.body)
+		if err != nil {
+			t.Fatalf("%s %s: %v", s.method, p, err)
+		}
+		if got != live[i] {
+			t.Fatalf("%s %s = %q, want %q", s.method, p, got, live[i])
+		}
+	}
+	// 用完后重复最后一条
+	if got, _ := get(replay, "GET", "/count?a=1&b=2", ""); got != live[1] {
+		t.Fatalf("exhausted replay = %q, want %q", got, live[1])
+	}
+	if _, err := get(replay, "POST", "/echo", "z"); !errors.Is(err, requester.ErrHARNoMatch) {
+		t.Fatalf("unmatched body err = %v", err)
+	}
+}
+
+func TestHARReplayOptions(t *testing.T) {
+	har := &requester.HAR{Log: requester.HARLog{Entries: []requester.HAREntry{{
+		Request:  requester.HARRequest{Method: "POST", URL: "http://x.test/a", PostData: &requester.HARPostData{Text: "rec"}},
+		Response: requester.HARResponse{Status: 201, Content: requester.HARContent{Text: "hit"}},
+	}}}}
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(append([]byte("live:"), body...))
+	}))
+	defer fallback.Close()
+
+	tests := []struct {
+		name string
+		opt  requester.HARReplayOption
+		url  string
+		want string
+	}{
+		{"ignore body", requester.HARReplayOption{IgnoreBody: true}, "http://x.test/a", "hit"},
+		{"custom match", requester.HARReplayOption{Match: func(r *http.Request, _ []byte, e *requester.HAREntry) bool {
+			return r.URL.Path == "/other"
+		}}, "http://x.test/other", "hit"},
+		{"fallback", requester.HARReplayOption{Fallback: http.DefaultTransport}, fallback.URL + "/a", "live:other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := requester.NewClient(requester.ClientOption{Transport: requester.NewHARReplayer(har, tt.opt)})
+			r := c.NewRequester().Post(tt.url, strings.NewReader("other"))
+			defer r.Close()
+			if got := r.AsString(context.Background()); got != tt.want {
+				t.Fatalf("body = %q, want %q (err %v)", got, tt.want, r.GetErr())
+			}
+		})
+	}
+}
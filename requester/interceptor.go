@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"net/http"
+)
+
+// Doer 发送一次请求。
+type Doer func(req *http.Request) (*http.Response, error)
+
+// Interceptor 拦截器，包裹一次请求的发送：可在调用 next 前修改请求、调用后处理响应或错误，
+// 也可以不调用 next 直接返回响应（mock）。
+type Interceptor func(req *http.Request, next Doer) (*http.Response, error)
+
+// Use 为请求追加拦截器。执行顺序：Client 的拦截器在外，Requester 的在内，按注册顺序由外向内。
+func (h *Requester) Use(interceptors ...Interceptor) *Requester {
+	h.interceptors = append(h.interceptors, interceptors...)
+	return h
+}
+
+// OnBefore 请求发送前回调，返回错误时中止请求。
+func OnBefore(f func(req *http.Request) error) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		if err := f(req); err != nil {
+			return nil, err
+		}
+		return next(req)
+	}
+}
+
+// OnAfter 收到响应后回调，返回错误时关闭响应体并以该错误结束请求。
+func OnAfter(f func(req *http.Request, resp *http.Response) error) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := f(req, resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// OnError 请求出错时回调，返回值替换原错误。
+func OnError(f func(req *http.Request, err error) error) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+		resp, err := next(req)
+		if err != nil {
+			return nil, f(req, err)
+		}
+		return resp, nil
+	}
+}
+
+// chain 把拦截器按顺序包裹在 do 之外。
+func chain(do Doer, interceptors ...[]Interceptor) Doer {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		for j := len(interceptors[i]) - 1; j >= 0; j-- {
+			ic, next := interceptors[i][j], do
+			do = func(req *http.Request) (*http.Response, error) {
+				return ic(req, next)
+			}
+		}
+	}
+	return do
+}
@@ -0,0 +1,110 @@
+package requester_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func TestInterceptorOrder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var trace []string
+	mark := func(name string) requester.Interceptor {
+		return func(req *http.Request, next requester.Doer) (*http.Response, error) {
+			trace = append(trace, name+">")
+			resp, err := next(req)
+			trace = append(trace, "<"+name)
+			return resp, err
+		}
+	}
+	c, _ := requester.NewClient(requester.ClientOption{Interceptors: []requester.Interceptor{mark("c1"), mark("c2")}})
+	r := c.NewRequester().Get(srv.URL).Use(mark("r1")).Use(mark("r2"))
+	defer r.Close()
+	r.AsString(context.Background())
+
+	want := "c1> c2> r1> r2> <r2 <r1 <c2 <c1"
+	if got := strings.Join(trace, " "); got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+}
+
+func TestInterceptorHelpers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Echo", r.Header.Get("X-In"))
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	errStop := errors.New("stop")
+	tests := []struct {
+		name    string
+		ic      requester.Interceptor
+		url     string
+		wantErr error
+	}{
+		{"before aborts", requester.OnBefore(func(*http.Request) error { return errStop }), srv.URL, errStop},
+		{"after rejects", requester.OnAfter(func(_ *http.Request, resp *http.Response) error {
+			if resp.StatusCode == http.StatusTeapot {
+				return errStop
+			}
+			return nil
+		}), srv.URL, errStop},
+		{"error replaced", requester.OnError(func(_ *http.Request, err error) error { return errStop }), "http://127.0.0.1:1", errStop},
+		{"before mutates", requester.OnBefore(func(r *http.Request) error { r.Header.Set("X-In", "v"); return nil }), srv.URL, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := requester.NewRequester().Get(tt.url).Use(tt.ic)
+			defer r.Close()
+			resp, err := r.Response(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && resp.Header.Get("X-Echo") != "v" {
+				t.Fatalf("X-Echo = %q", resp.Header.Get("X-Echo"))
+			}
+		})
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	fake := func(req *http.Request, next requester.Doer) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("mocked")), Header: http.Header{}, Request: req}, nil
+	}
+	r := requester.NewRequester().Get("http://unreachable.invalid/").Use(fake)
+	defer r.Close()
+	if got := r.AsString(context.Background()); got != "mocked" {
+		t.Fatalf("body = %q, err = %v", got, r.GetErr())
+	}
+}
+
+func TestDebugInterceptor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	r := requester.NewRequester().Post(srv.URL, strings.NewReader("ping-body")).Use(requester.DebugInterceptor(&out))
+	defer r.Close()
+	// 打印后请求体、响应体仍可正常读取
+	if got := r.AsString(context.Background()); got != "ping-body" {
+		t.Fatalf("body = %q", got)
+	}
+	for _, want := range []string{"Request", "method:\tPOST", "Response", "ping-body"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("debug output missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+// Expectation 一条请求期望及其应答，由 Server.On 创建，方法均可链式调用。
+// 默认要求至少被调用一次，应答 200 空响应体。
+type Expectation struct {
+	method  string
+	pattern string
+
+	headers  http.Header
+	query    url.Values
+	body     []byte
+	jsonBody any
+	hasJSON  bool
+	matchFn  func(r *http.Request, body []byte) bool
+	times    int
+	optional bool
+
+	status   int
+	statuses []int
+	header   http.Header
+	respBody []byte
+	delay    time.Duration
+	interval time.Duration
+	stream   func(w http.ResponseWriter, r *http.Request, interval time.Duration)
+	handler  http.HandlerFunc
+
+	mu    sync.Mutex
+	calls []Request
+}
+
+// Request 一次被记录的调用。
+type Request struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+	// Params 路径模式捕获的参数
+	Params map[string]string
+}
+
+func (e *Expectation) String() string {
+	method, pattern := e.method, e.pattern
+	if method == "" {
+		method = "*"
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	return method + " " + pattern
+}
+
+// WithHeader 要求请求头 key 的值等于 value。
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	if e.headers == nil {
+		e.headers = make(http.Header)
+	}
+	e.headers.Add(key, value)
+	return e
+}
+
+// WithQuery 要求查询参数 key 包含 value。
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	if e.query == nil {
+		e.query = make(url.Values)
+	}
+	e.query.Add(key, value)
+	return e
+}
+
+// WithBody 要求请求体与 body 完全相同。
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = []byte(body)
+	return e
+}
+
+// WithJSON 要求请求体与 v 的 JSON 语义相等（忽略字段顺序与空白）。
+func (e *Expectation) WithJSON(v any) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("mock: WithJSON: %v", err))
+	}
+	json.Unmarshal(data, &e.jsonBody)
+	e.hasJSON = true
+	return e
+}
+
+// Match 追加自定义匹配条件，body 为请求体内容。
+func (e *Expectation) Match(f func(r *http.Request, body []byte) bool) *Expectation {
+	e.matchFn = f
+	return e
+}
+
+// Times 要求恰好被调用 n 次；用完次数后同一请求会交给后面声明的期望。
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Once 等同 Times(1)。
+func (e *Expectation) Once() *Expectation {
+	return e.Times(1)
+}
+
+// Optional 不要求被调用。
+func (e *Expectation) Optional() *Expectation {
+	e.optional = true
+	return e
+}
+
+// Reply 以 status 与 body 应答。
+func (e *Expectation) Reply(status int, body string) *Expectation {
+	e.status = status
+	e.respBody = []byte(body)
+	return e
+}
+
+// ReplyJSON 以 status 与 v 的 JSON 编码应答。
+func (e *Expectation) ReplyJSON(status int, v any) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("mock: ReplyJSON: %v", err))
+	}
+	e.status = status
+	e.respBody = data
+	e.header.Set("Content-Type", "application/json")
+	return e
+}
+
+// ReplyHeader 添加应答头。
+func (e *Expectation) ReplyHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// Statuses 依次以这些状态码应答，用完后重复最后一个，例如 Statuses(503, 503, 200) 模拟恢复中的服务。
+func (e *Expectation) Statuses(codes ...int) *Expectation {
+	e.statuses = codes
+	return e
+}
+
+// Delay 应答前等待 d，客户端取消时提前结束。
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+// Interval 流式应答中相邻两条数据之间的间隔。
+func (e *Expectation) Interval(d time.Duration) *Expectation {
+	e.interval = d
+	return e
+}
+
+// ReplySSE 以 text/event-stream 逐条发送事件后结束连接。
+func (e *Expectation) ReplySSE(events ...requester.Event) *Expectation {
+	e.header.Set("Content-Type", "text/event-stream")
+	e.header.Set("Cache-Control", "no-cache")
+	e.stream = func(w http.ResponseWriter, r *http.Request, interval time.Duration) {
+		for i, ev := range events {
+			if i > 0 && !sleep(r, interval) {
+				return
+			}
+			writeEvent(w, ev)
+		}
+	}
+	return e
+}
+
+// ReplyNDJSON 以 application/x-ndjson 逐行发送 values 的 JSON 编码后结束连接。
+func (e *Expectation) ReplyNDJSON(values ...any) *Expectation {
+	lines := make([][]byte, len(values))
+	for i, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			panic(fmt.Sprintf("mock: ReplyNDJSON: %v", err))
+		}
+		lines[i] = data
+	}
+	e.header.Set("Content-Type", "application/x-ndjson")
+	e.stream = func(w http.ResponseWriter, r *http.Request, interval time.Duration) {
+		for i, line := range lines {
+			if i > 0 && !sleep(r, interval) {
+				return
+			}
+			w.Write(line)
+			w.Write([]byte("\n"))
+			flush(w)
+		}
+	}
+	return e
+}
+
+// Handle 使用自定义处理器应答，Reply 等设置不再生效（Delay 仍然有效）。
+func (e *Expectation) Handle(h http.HandlerFunc) *Expectation {
+	e.handler = h
+	return e
+}
+
+// Calls 返回已被调用的次数。
+func (e *Expectation) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.calls)
+}
+
+// Requests 返回已记录的调用。
+func (e *Expectation) Requests() []Request {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Request(nil), e.calls...)
+}
+
+func (e *Expectation) matches(r *http.Request, body []byte) (map[string]string, bool) {
+	if e.method != "" && e.method != "*" && e.method != r.Method {
+		return nil, false
+	}
+	params, ok := matchPath(e.pattern, r.URL.Path)
+	if !ok {
+		return nil, false
+	}
+	for k, vs := range e.headers {
+		for _, v := range vs {
+			if !contains(r.Header.Values(k), v) {
+				return nil, false
+			}
+		}
+	}
+	q := r.URL.Query()
+	for k, vs := range e.query {
+		for _, v := range vs {
+			if !contains(q[k], v) {
+				return nil, false
+			}
+		}
+	}
+	if e.body != nil && !bytes.Equal(e.body, body) {
+		return nil, false
+	}
+	if e.hasJSON {
+		var got any
+		if json.Unmarshal(body, &got) != nil || !reflect.DeepEqual(got, e.jsonBody) {
+			return nil, false
+		}
+	}
+	if e.matchFn != nil && !e.matchFn(r, body) {
+		return nil, false
+	}
+	return params, true
+}
+
+// record 记录一次调用并返回其序号。
+func (e *Expectation) record(r *http.Request, body []byte, params map[string]string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, Request{
+		Method: r.Method,
+		URL:    r.URL,
+		Header: r.Header.Clone(),
+		Body:   body,
+		Params: params,
+	})
+	return len(e.calls) - 1
+}
+
+func (e *Expectation) serve(w http.ResponseWriter, r *http.Request, call int) {
+	if !sleep(r, e.delay) {
+		return
+	}
+	if e.handler != nil {
+		e.handler(w, r)
+		return
+	}
+	for k, vs := range e.header {
+		w.Header()[k] = vs
+	}
+	status := e.status
+	if len(e.statuses) > 0 {
+		status = e.statuses[min(call, len(e.statuses)-1)]
+	}
+	w.WriteHeader(status)
+	if e.stream != nil {
+		flush(w)
+		e.stream(w, r, e.interval)
+		return
+	}
+	w.Write(e.respBody)
+}
+
+// matchPath 按段匹配路径模式，返回捕获的参数。
+func matchPath(pattern, path string) (map[string]string, bool) {
+	if pattern == "" || pattern == "*" {
+		return nil, true
+	}
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	ss := strings.Split(strings.Trim(path, "/"), "/")
+	var params map[string]string
+	for i, p := range ps {
+		if name, ok := strings.CutPrefix(p, "{"); ok && strings.HasSuffix(name, "}") {
+			name = strings.TrimSuffix(name, "}")
+			if params == nil {
+				params = make(map[string]string)
+			}
+			if rest, ok := strings.CutSuffix(name, "..."); ok {
+				params[rest] = strings.Join(ss[min(i, len(ss)):], "/")
+				return params, true
+			}
+			if i >= len(ss) || ss[i] == "" {
+				return nil, false
+			}
+			params[name] = ss[i]
+			continue
+		}
+		if i >= len(ss) || ss[i] != p {
+			return nil, false
+		}
+	}
+	return params, len(ps) == len(ss)
+}
+
+func contains(vs []string, v string) bool {
+	for _, x := range vs {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func writeEvent(w http.ResponseWriter, ev requester.Event) {
+	var sb strings.Builder
+	if ev.Event != "" && ev.Event != "message" {
+		sb.WriteString("event: " + ev.Event + "\n")
+	}
+	if ev.ID != "" {
+		sb.WriteString("id: " + ev.ID + "\n")
+	}
+	if ev.Retry > 0 {
+		fmt.Fprintf(&sb, "retry: %d\n", ev.Retry.Milliseconds())
+	}
+	for _, line := range strings.Split(ev.Data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	w.Write([]byte(sb.String()))
+	flush(w)
+}
+
+func flush(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// sleep 等待 d，请求被取消时返回 false。
+func sleep(r *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-r.Context().Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package mock 为基于 requester 的客户端提供假 HTTP 服务：声明式地描述期望的请求与应答，
+// 测试结束时自动校验调用次数。
+package mock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+// Server 假服务。NewServer 监听本地端口；NewInMemory 不监听端口，只能通过 Transport / Client 访问。
+type Server struct {
+	t   testing.TB
+	srv *httptest.Server
+
+	mu         sync.Mutex
+	expects    []*Expectation
+	unexpected []string
+}
+
+// NewServer 启动本地 httptest 服务，测试结束时关闭并调用 Verify。
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{t: t}
+	s.srv = httptest.NewServer(s)
+	t.Cleanup(func() {
+		s.srv.Close()
+		s.Verify()
+	})
+	return s
+}
+
+// NewInMemory 创建不监听端口的假服务，请求通过内存管道直接交给处理器，测试结束时调用 Verify。
+func NewInMemory(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{t: t}
+	t.Cleanup(s.Verify)
+	return s
+}
+
+const inMemoryURL = "http://mock.local"
+
+// URL 返回服务地址；内存模式下为 http://mock.local（仅对 Transport / Client 有效）。
+func (s *Server) URL() string {
+	if s.srv != nil {
+		return s.srv.URL
+	}
+	return inMemoryURL
+}
+
+// Transport 返回访问该服务的 RoundTripper。
+func (s *Server) Transport() http.RoundTripper {
+	if s.srv != nil {
+		return s.srv.Client().Transport
+	}
+	return transport{s}
+}
+
+// Client 返回以该服务为 BaseURL 的 requester.Client，opts 中的 BaseURL 与 Transport 会被覆盖。
+func (s *Server) Client(opts ...requester.ClientOption) *requester.Client {
+	s.t.Helper()
+	opt := requester.ClientOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	opt.BaseURL = s.URL()
+	opt.Transport = s.Transport()
+	c, err := requester.NewClient(opt)
+	if err != nil {
+		s.t.Fatalf("mock: %v", err)
+	}
+	return c
+}
+
+// On 声明一条期望。method 为空或 "*" 时匹配任意方法；pattern 为路径模式，
+// 支持 {name} 匹配一段、{name...} 匹配剩余部分，为空或 "*" 时匹配任意路径，捕获的值可用 r.PathValue 读取。
+// 多条期望都能匹配时按声明顺序选取第一条未用完次数的期望。
+func (s *Server) On(method, pattern string) *Expectation {
+	e := &Expectation{
+		method:  strings.ToUpper(method),
+		pattern: pattern,
+		times:   -1,
+		status:  http.StatusOK,
+		header:  make(http.Header),
+	}
+	s.mu.Lock()
+	s.expects = append(s.expects, e)
+	s.mu.Unlock()
+	return e
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+
+	e, call, params := s.match(r, body)
+	if e == nil {
+		msg := fmt.Sprintf("%s %s", r.Method, r.URL.RequestURI())
+		s.mu.Lock()
+		s.unexpected = append(s.unexpected, msg)
+		s.mu.Unlock()
+		http.Error(w, "mock: unexpected request "+msg, http.StatusNotImplemented)
+		return
+	}
+	for k, v := range params {
+		r.SetPathValue(k, v)
+	}
+	e.serve(w, r, call)
+}
+
+// match 找到匹配的期望并记录本次调用，返回调用序号（从 0 开始）与路径参数。
+func (s *Server) match(r *http.Request, body []byte) (*Expectation, int, map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var exhausted *Expectation
+	for _, e := range s.expects {
+		params, ok := e.matches(r, body)
+		if !ok {
+			continue
+		}
+		e.mu.Lock()
+		full := e.times >= 0 && len(e.calls) >= e.times
+		e.mu.Unlock()
+		if full {
+			if exhausted == nil {
+				exhausted = e
+			}
+			continue
+		}
+		return e, e.record(r, body, params), params
+	}
+	if exhausted != nil {
+		// 超出次数的调用仍按该期望应答，由 Verify 报告
+		params, _ := exhausted.matches(r, body)
+		return exhausted, exhausted.record(r, body, params), params
+	}
+	return nil, 0, nil
+}
+
+// Verify 检查每条期望的调用次数以及是否有未声明的请求；NewServer / NewInMemory 会在测试结束时自动调用。
+func (s *Server) Verify() {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.expects {
+		n := e.Calls()
+		switch {
+		case e.times >= 0 && n != e.times:
+			s.t.Errorf("mock: %s called %d times, want %d", e, n, e.times)
+		case e.times < 0 && n == 0 && !e.optional:
+			s.t.Errorf("mock: %s was never called", e)
+		}
+	}
+	for _, u := range s.unexpected {
+		s.t.Errorf("mock: unexpected request %s", u)
+	}
+}
@@ -0,0 +1,189 @@
+package mock_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+	"github.com/Rehtt/Kit/requester/mock"
+)
+
+// recordT 收集 Verify 报告的错误，代替真实 t 以便断言失败场景。
+type recordT struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (r *recordT) Helper()          {}
+func (r *recordT) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+func (r *recordT) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+func (r *recordT) Fatalf(format string, args ...any) { r.Errorf(format, args...) }
+
+func (r *recordT) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestServerModes(t *testing.T) {
+	modes := map[string]func(testing.TB) *mock.Server{"listen": mock.NewServer, "in-memory": mock.NewInMemory}
+	for name, newServer := range modes {
+		t.Run(name, func(t *testing.T) {
+			s := newServer(t)
+			users := s.On("GET", "/users/{id}").WithHeader("X-Token", "tk").WithQuery("v", "1").
+				ReplyJSON(200, map[string]string{"name": "alice"}).ReplyHeader("X-Req", "1").Once()
+			s.On("POST", "/users").WithJSON(map[string]any{"name": "bob"}).Reply(201, "created")
+			s.On("*", "/files/{path...}").Handle(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(r.PathValue("path")))
+			})
+
+			c := s.Client()
+			ctx := context.Background()
+
+			r := c.NewRequester().Get("/users/7?v=1").SetHead("X-Token", "tk")
+			type user struct{ Name string }
+			u, err := requester.AsJSONG[user](ctx, r)
+			r.Close()
+			if err != nil || u.Name != "alice" {
+				t.Fatalf("user = %+v, err = %v", u, err)
+			}
+			if got := users.Requests()[0].Params["id"]; got != "7" {
+				t.Fatalf("id param = %q", got)
+			}
+
+			r = c.NewRequester().PostJSON("/users", map[string]string{"name": "bob"})
+			resp, err := r.Response(ctx)
+			if err != nil || resp.StatusCode != 201 {
+				t.Fatalf("POST status = %v, err = %v", resp, err)
+			}
+			r.Close()
+
+			r = c.NewRequester().Delete("/files/a/b.txt", nil)
+			if got := r.AsString(ctx); got != "a/b.txt" {
+				t.Fatalf("files = %q", got)
+			}
+			r.Close()
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s *mock.Server)
+		send  []string
+		want  []string
+	}{
+		{"satisfied", func(s *mock.Server) {
+			s.On("GET", "/a").Times(2)
+			s.On("GET", "/b").Optional()
+		}, []string{"/a", "/a"}, nil},
+		{"never called", func(s *mock.Server) { s.On("GET", "/a") }, nil, []string{"never called"}},
+		{"too many", func(s *mock.Server) { s.On("GET", "/a").Once() }, []string{"/a", "/a"}, []string{"called 2 times, want 1"}},
+		{"unexpected", func(s *mock.Server) {}, []string{"/x"}, []string{"unexpected request GET /x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &recordT{TB: t}
+			s := mock.NewInMemory(rt)
+			tt.setup(s)
+			c := s.Client()
+			for _, p := range tt.send {
+				r := c.NewRequester().Get(p)
+				r.AsBytes(context.Background())
+				r.Close()
+			}
+			rt.finish()
+			if len(rt.errs) != len(tt.want) {
+				t.Fatalf("errors = %q, want %q", rt.errs, tt.want)
+			}
+			for i, w := range tt.want {
+				if !strings.Contains(rt.errs[i], w) {
+					t.Fatalf("error %q does not contain %q", rt.errs[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusesAndFallthrough(t *testing.T) {
+	s := mock.NewInMemory(t)
+	s.On("GET", "/svc").Statuses(503, 200).Times(3)
+	s.On("GET", "/svc").Reply(204, "").Once()
+
+	c := s.Client()
+	var got []int
+	for range 4 {
+		r := c.NewRequester().Get("/svc")
+		resp, err := r.Response(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp.StatusCode)
+		r.Close()
+	}
+	if fmt.Sprint(got) != "[503 200 200 204]" {
+		t.Fatalf("statuses = %v", got)
+	}
+}
+
+func TestDelayHonorsCancel(t *testing.T) {
+	s := mock.NewInMemory(t)
+	s.On("GET", "/slow").Delay(time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	r := s.Client().NewRequester().Get("/slow")
+	defer r.Close()
+	start := time.Now()
+	if _, err := r.Response(ctx); err == nil {
+		t.Fatal("expected timeout")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("Delay ignored cancellation")
+	}
+}
+
+func TestStreamingReplies(t *testing.T) {
+	s := mock.NewServer(t)
+	s.On("GET", "/events").ReplySSE(
+		requester.Event{Event: "tick", ID: "1", Data: "a"},
+		requester.Event{Data: "line1\nline2"},
+	).Interval(time.Millisecond)
+	s.On("GET", "/lines").ReplyNDJSON(map[string]int{"n": 1}, map[string]int{"n": 2})
+
+	c := s.Client()
+	ctx := context.Background()
+
+	r := c.NewRequester().Get("/events")
+	var events []string
+	for ev, err := range r.Events(ctx, requester.EventsOption{NoReconnect: true}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, ev.Event+"|"+ev.ID+"|"+ev.Data)
+	}
+	r.Close()
+	if want := "[tick|1|a message|1|line1\nline2]"; fmt.Sprint(events) != want {
+		t.Fatalf("events = %q", events)
+	}
+
+	r = c.NewRequester().Get("/lines")
+	var sum int
+	for v, err := range requester.JSONLines[map[string]int](ctx, r) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sum += v["n"]
+	}
+	r.Close()
+	if sum != 3 {
+		t.Fatalf("sum = %d", sum)
+	}
+}
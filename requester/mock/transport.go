@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package mock
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// transport 内存模式的 RoundTripper：处理器在独立协程中运行，响应体通过 io.Pipe 流式返回。
+type transport struct {
+	s *Server
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	sreq := req.Clone(ctx)
+	sreq.RequestURI = req.URL.RequestURI()
+	sreq.RemoteAddr = "127.0.0.1:0"
+	if sreq.Host == "" {
+		sreq.Host = req.URL.Host
+	}
+	if sreq.Body == nil {
+		sreq.Body = http.NoBody
+	}
+
+	pr, pw := io.Pipe()
+	w := &pipeWriter{header: make(http.Header), pw: pw, ready: make(chan struct{})}
+	go func() {
+		defer func() {
+			w.WriteHeader(http.StatusOK)
+			pw.Close()
+		}()
+		t.s.ServeHTTP(w, sreq)
+	}()
+
+	select {
+	case <-w.ready:
+	case <-req.Context().Done():
+		cancel()
+		pr.Close()
+		return nil, req.Context().Err()
+	}
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(w.status) + " " + http.StatusText(w.status),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.sent,
+		Body:          &pipeBody{pr: pr, cancel: cancel},
+		ContentLength: -1,
+		Request:       req,
+	}
+	if cl, err := strconv.ParseInt(w.sent.Get("Content-Length"), 10, 64); err == nil {
+		resp.ContentLength = cl
+	}
+	return resp, nil
+}
+
+type pipeWriter struct {
+	header http.Header
+	pw     *io.PipeWriter
+
+	once   sync.Once
+	ready  chan struct{}
+	status int
+	sent   http.Header
+}
+
+func (w *pipeWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *pipeWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.status = status
+		w.sent = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *pipeWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.pw.Write(p)
+}
+
+// Flush 管道写入本身是同步的，这里只需确保响应头已发出。
+func (w *pipeWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+}
+
+// pipeBody 关闭时取消处理器的 ctx，模拟客户端断开。
+type pipeBody struct {
+	pr     *io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (b *pipeBody) Read(p []byte) (int, error) {
+	return b.pr.Read(p)
+}
+
+func (b *pipeBody) Close() error {
+	b.cancel()
+	return b.pr.Close()
+}
@@ -22,6 +22,14 @@ type Requester struct {
 
 	err   error
 	debug bool
+
+	client       *Client
+	interceptors []Interceptor
+	retry        *RetryOption
+	breakers     *Breakers
+
+	uploadProgress func(sent, total int64)
+	maxLine        int
 }
 
 var requesterPool = sync.Pool{
@@ -0,0 +1,261 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token OAuth2 访问令牌。
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// valid 令牌存在且距离过期超过 delta。
+func (t *Token) valid(delta time.Duration) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || time.Now().Add(delta).Before(t.Expiry)
+}
+
+func (t *Token) authorization() string {
+	typ := t.TokenType
+	if typ == "" || strings.EqualFold(typ, "bearer") {
+		typ = "Bearer"
+	}
+	return typ + " " + t.AccessToken
+}
+
+// OAuth2Error 令牌端点返回的错误（RFC 6749 §5.2）。
+type OAuth2Error struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuth2Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("requester: oauth2: %s: %s", e.Code, e.Description)
+	}
+	return fmt.Sprintf("requester: oauth2: %s (status %d)", e.Code, e.StatusCode)
+}
+
+// OAuth2Option OAuth2 配置。
+type OAuth2Option struct {
+	// Scopes 申请的权限范围
+	Scopes []string
+	// RefreshToken 初始 refresh token；设置后使用 refresh_token 流程，否则使用 client_credentials
+	RefreshToken string
+	// EndpointParams 令牌请求的额外参数，如 audience
+	EndpointParams url.Values
+	// AuthInParams 把 client_id/client_secret 放在表单参数中，默认使用 Basic 认证头
+	AuthInParams bool
+	// ExpiryDelta 提前刷新的时间，默认 10s
+	ExpiryDelta time.Duration
+	// Client 请求令牌使用的客户端，默认 http.DefaultClient；不要在它上面挂使用本令牌的 TokenAuth
+	Client *Client
+	// OnToken 获取到新令牌时回调，可用于持久化 refresh token
+	OnToken func(t *Token)
+}
+
+// OAuth2 实现 TokenSource：缓存令牌，过期前自动通过 refresh_token 或 client_credentials 换新。
+// 并发调用合并为一次请求，每个调用方只受自己的 ctx 约束；所有调用方都放弃时取消该请求。
+type OAuth2 struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	opt          OAuth2Option
+
+	mu       sync.Mutex
+	tok      *Token
+	inflight *tokenCall
+}
+
+// tokenCall 一次进行中的令牌请求。
+type tokenCall struct {
+	done    chan struct{}
+	tok     *Token
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func NewOAuth2(tokenURL, clientID, clientSecret string, opts ...OAuth2Option) *OAuth2 {
+	opt := OAuth2Option{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.ExpiryDelta <= 0 {
+		opt.ExpiryDelta = 10 * time.Second
+	}
+	o := &OAuth2{tokenURL: tokenURL, clientID: clientID, clientSecret: clientSecret, opt: opt}
+	if opt.RefreshToken != "" {
+		o.tok = &Token{RefreshToken: opt.RefreshToken}
+	}
+	return o
+}
+
+// Token 返回有效的令牌，必要时请求令牌端点。
+func (o *OAuth2) Token(ctx context.Context) (*Token, error) {
+	o.mu.Lock()
+	if o.tok.valid(o.opt.ExpiryDelta) {
+		tok := o.tok
+		o.mu.Unlock()
+		return tok, nil
+	}
+	call := o.inflight
+	if call == nil {
+		call = o.start(ctx)
+	}
+	call.waiters++
+	o.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.tok, call.err
+	case <-ctx.Done():
+		o.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// 没有调用方在等待：取消请求，之后的调用重新发起
+			call.cancel()
+			if o.inflight == call {
+				o.inflight = nil
+			}
+		}
+		o.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// start 在后台请求令牌，调用方需持有 o.mu。
+func (o *OAuth2) start(ctx context.Context) *tokenCall {
+	fctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &tokenCall{done: make(chan struct{}), cancel: cancel}
+	o.inflight = call
+
+	form := url.Values{}
+	prev := o.tok
+	if prev != nil && prev.RefreshToken != "" {
+		form.Set("grant_type", "refresh_token")
+		form.Set("refresh_token", prev.RefreshToken)
+	} else {
+		form.Set("grant_type", "client_credentials")
+	}
+	if len(o.opt.Scopes) > 0 {
+		form.Set("scope", strings.Join(o.opt.Scopes, " "))
+	}
+	for k, v := range o.opt.EndpointParams {
+		form[k] = v
+	}
+
+	go func() {
+		defer cancel()
+		tok, err := o.fetch(fctx, form)
+		if err == nil && tok.RefreshToken == "" && prev != nil {
+			// 服务端未轮换 refresh token 时沿用旧的
+			tok.RefreshToken = prev.RefreshToken
+		}
+		o.mu.Lock()
+		stored := false
+		if o.inflight == call {
+			o.inflight = nil
+			if err == nil {
+				o.tok, stored = tok, true
+			}
+		}
+		o.mu.Unlock()
+		if stored && o.opt.OnToken != nil {
+			o.opt.OnToken(tok)
+		}
+		call.tok, call.err = tok, err
+		close(call.done)
+	}()
+	return call
+}
+
+// SetToken 设置当前令牌，例如恢复持久化的令牌。
+func (o *OAuth2) SetToken(t *Token) {
+	o.mu.Lock()
+	o.tok = t
+	o.mu.Unlock()
+}
+
+// Invalidate 丢弃 t 对应的访问令牌（保留 refresh token），下次 Token 时重新获取。
+func (o *OAuth2) Invalidate(t *Token) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.tok == t {
+		o.tok = &Token{RefreshToken: t.RefreshToken}
+	}
+}
+
+func (o *OAuth2) fetch(ctx context.Context, form url.Values) (*Token, error) {
+	var r *Requester
+	if o.opt.Client != nil {
+		r = o.opt.Client.NewRequester()
+	} else {
+		r = NewRequester()
+	}
+	defer r.Close()
+
+	if o.opt.AuthInParams {
+		form.Set("client_id", o.clientID)
+		if o.clientSecret != "" {
+			form.Set("client_secret", o.clientSecret)
+		}
+	} else {
+		// RFC 6749 §2.3.1：凭据需先做 form 编码
+		r.SetHead("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(url.QueryEscape(o.clientID)+":"+url.QueryEscape(o.clientSecret))))
+	}
+	r.SetHead("Accept", "application/json")
+
+	resp, err := r.PostForm(o.tokenURL, form).Response(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var body struct {
+		AccessToken  string          `json:"access_token"`
+		TokenType    string          `json:"token_type"`
+		RefreshToken string          `json:"refresh_token"`
+		ExpiresIn    json.RawMessage `json:"expires_in"`
+		OAuth2Error
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 || body.Code != "" {
+		e := body.OAuth2Error
+		e.StatusCode = resp.StatusCode
+		if e.Code == "" {
+			e.Code = http.StatusText(resp.StatusCode)
+		}
+		return nil, &e
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("requester: oauth2: decode token response: %w", decodeErr)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("requester: oauth2: token response missing access_token")
+	}
+	tok := &Token{AccessToken: body.AccessToken, TokenType: body.TokenType, RefreshToken: body.RefreshToken}
+	// expires_in 可能是数字或字符串
+	if secs, err := strconv.ParseInt(strings.Trim(string(body.ExpiresIn), `"`), 10, 64); err == nil && secs > 0 {
+		tok.Expiry = time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return tok, nil
+}
@@ -0,0 +1,142 @@
+package requester_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+func TestOAuth2Flows(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       requester.OAuth2Option
+		wantGrant string
+	}{
+		{"client credentials", requester.OAuth2Option{}, "client_credentials"},
+		{"refresh token", requester.OAuth2Option{RefreshToken: "rt"}, "refresh_token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var grant, user string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				grant = r.PostForm.Get("grant_type")
+				user, _, _ = r.BasicAuth()
+				json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "bearer", "expires_in": "3600"})
+			}))
+			defer srv.Close()
+
+			var saved *requester.Token
+			tt.opt.OnToken = func(tok *requester.Token) { saved = tok }
+			o := requester.NewOAuth2(srv.URL, "id", "secret", tt.opt)
+			tok, err := o.Token(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if grant != tt.wantGrant || user != "id" || tok.AccessToken != "at" || saved != tok {
+				t.Fatalf("grant = %q, user = %q, token = %+v", grant, user, tok)
+			}
+			if tok.RefreshToken != tt.opt.RefreshToken || time.Until(tok.Expiry) < time.Hour-time.Minute {
+				t.Fatalf("token = %+v", tok)
+			}
+		})
+	}
+}
+
+func TestOAuth2Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_client","error_description":"bad secret"}`))
+	}))
+	defer srv.Close()
+
+	_, err := requester.NewOAuth2(srv.URL, "id", "x").Token(context.Background())
+	var oe *requester.OAuth2Error
+	if !errors.As(err, &oe) || oe.Code != "invalid_client" || oe.StatusCode != http.StatusBadRequest {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestOAuth2CoalescesConcurrentCallers(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		<-release
+		w.Write([]byte(`{"access_token":"at","expires_in":3600}`))
+	}))
+	defer srv.Close()
+	o := requester.NewOAuth2(srv.URL, "id", "secret")
+
+	// 一个调用方提前放弃，不影响其他调用方，也不会取消共享的请求
+	cctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error, 1)
+	go func() {
+		_, err := o.Token(cctx)
+		cancelled <- err
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tok, err := o.Token(context.Background())
+			if err == nil && tok.AccessToken != "at" {
+				err = errors.New("wrong token")
+			}
+			errs <- err
+		}()
+	}
+	for fetches.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller err = %v", err)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("token endpoint called %d times", n)
+	}
+}
+
+func TestTokenAuthRefreshesOn401(t *testing.T) {
+	var issued atomic.Int32
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := issued.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": []string{"", "old", "new"}[n], "expires_in": 3600})
+	}))
+	defer tokenSrv.Close()
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer api.Close()
+
+	r := requester.NewRequester().PostJSON(api.URL, map[string]int{"a": 1}).
+		Use(requester.TokenAuth(requester.NewOAuth2(tokenSrv.URL, "id", "secret")))
+	defer r.Close()
+	if got := r.AsString(context.Background()); got != "ok" || issued.Load() != 2 {
+		t.Fatalf("body = %q, tokens issued = %d", got, issued.Load())
+	}
+}
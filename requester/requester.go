@@ -1,10 +1,13 @@
 package requester
 
 import (
+	stdbytes "bytes"
 	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"os"
+	"slices"
 
 	strings2 "github.com/Rehtt/Kit/strings"
 
@@ -88,21 +91,132 @@ func (h *Requester) Response(ctx context.Context) (*http.Response, error) {
 	if h.err != nil {
 		return nil, h.err
 	}
+	h.response, h.err = h.do(ctx)
+	return h.response, h.err
+}
 
-	req, err := http.NewRequestWithContext(ctx, h.m, h.url, h.body)
+// do 执行请求；开启 Retry 时按策略重试，开启 Breaker 时每次尝试前检查目标 host 的熔断状态。
+func (h *Requester) do(ctx context.Context) (*http.Response, error) {
+	open, size, replayable, err := h.openBody()
 	if err != nil {
-		h.err = err
 		return nil, err
 	}
-	req.Header = h.header.Clone()
 
-	h.printRequestDebug(req)
+	u, hc := h.url, http.DefaultClient
+	if h.client != nil {
+		u, hc = h.client.resolve(u), h.client.client
+	}
 
-	h.response, h.err = http.DefaultClient.Do(req)
+	attempts := 1
+	if h.retry != nil {
+		attempts = h.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		body := open()
+		if size == 0 {
+			body = nil
+		}
+		if body != nil && h.uploadProgress != nil {
+			body = &progressReader{r: body, total: size, f: h.uploadProgress}
+		}
+		req, err := http.NewRequestWithContext(ctx, h.m, u, body)
+		if err != nil {
+			return nil, err
+		}
+		if size > 0 {
+			req.ContentLength = size
+		}
+		if replayable && body != nil {
+			// 供重定向与拦截器（如 DigestAuth、TokenAuth）重发请求
+			req.GetBody = func() (io.ReadCloser, error) {
+				r := open()
+				if rc, ok := r.(io.ReadCloser); ok {
+					return rc, nil
+				}
+				return io.NopCloser(r), nil
+			}
+		}
+		req.Header = h.header.Clone()
 
-	h.printResponseDebug(h.response)
+		resp, err := h.attempt(hc, req)
+		if h.retry == nil || attempt >= attempts || !h.retry.shouldRetry(resp, err) {
+			return resp, err
+		}
+		delay, ok := h.retry.backoff(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		if h.retry.OnRetry != nil {
+			h.retry.OnRetry(attempt+1, resp, err, delay)
+		}
+		drain(resp)
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
 
-	return h.response, h.err
+// openBody 返回每次尝试使用的请求体构造函数、请求体长度（-1 表示未知）以及能否重复生成。
+func (h *Requester) openBody() (open func() io.Reader, size int64, replayable bool, err error) {
+	switch b := h.body.(type) {
+	case nil:
+		return func() io.Reader { return nil }, 0, true, nil
+	case *bytes.ByteBuffer:
+		data := b.Bytes()[b.Position():]
+		return func() io.Reader { return stdbytes.NewReader(data) }, int64(len(data)), true, nil
+	case *multipartBody:
+		if b.m.replayable() {
+			return func() io.Reader { return b.m.open() }, b.m.size(), true, nil
+		}
+		if h.retry == nil {
+			return func() io.Reader { return b }, b.m.size(), false, nil
+		}
+	}
+	if h.retry != nil {
+		// 重试需要重放请求体，先完整读入
+		data, err := io.ReadAll(h.body)
+		if err != nil {
+			return nil, 0, false, err
+		}
+		return func() io.Reader { return stdbytes.NewReader(data) }, int64(len(data)), true, nil
+	}
+	return func() io.Reader { return h.body }, bodySize(h.body), false, nil
+}
+
+// bodyReplayable 请求体能否在多次调用 do 时重新生成（do 会为其设置 GetBody）。
+func (h *Requester) bodyReplayable() bool {
+	switch b := h.body.(type) {
+	case nil, *bytes.ByteBuffer:
+		return true
+	case *multipartBody:
+		return b.m.replayable()
+	}
+	return false
+}
+
+// attempt 经过熔断判断与拦截器链发送一次请求。
+func (h *Requester) attempt(hc *http.Client, req *http.Request) (*http.Response, error) {
+	var done func(*http.Response, error)
+	if h.breakers != nil {
+		var err error
+		if done, err = h.breakers.allow(req.URL.Host); err != nil {
+			return nil, err
+		}
+	}
+
+	var outer, debug []Interceptor
+	if h.client != nil {
+		outer = h.client.interceptors
+	}
+	if h.debug {
+		debug = []Interceptor{DebugInterceptor(os.Stdout)}
+	}
+	resp, err := chain(hc.Do, outer, h.interceptors, debug)(req)
+
+	if done != nil {
+		done(resp, err)
+	}
+	return resp, err
 }
 
 func (h *Requester) AsBytes(ctx context.Context) []byte {
@@ -146,6 +260,12 @@ func (h *Requester) Clear() *Requester {
 	h.m = ""
 	h.body = nil
 	h.response = nil
+	h.client = nil
+	h.interceptors = nil
+	h.retry = nil
+	h.breakers = nil
+	h.uploadProgress = nil
+	h.maxLine = 0
 	return h
 }
 
@@ -155,6 +275,12 @@ func (h *Requester) Clone() *Requester {
 	new.url = h.url
 	new.m = h.m
 	new.body = h.body
+	new.client = h.client
+	new.interceptors = slices.Clone(h.interceptors)
+	new.retry = h.retry
+	new.breakers = h.breakers
+	new.uploadProgress = h.uploadProgress
+	new.maxLine = h.maxLine
 	return new
 }
 
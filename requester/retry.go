@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryOption 重试策略，零值字段取默认值。
+type RetryOption struct {
+	// MaxAttempts 总尝试次数（含首次），默认 3
+	MaxAttempts int
+	// BaseDelay 首次重试前的等待，之后按 Multiplier 指数增长，默认 100ms
+	BaseDelay time.Duration
+	// MaxDelay 单次等待上限，默认 10s；服务端 Retry-After 超过该值时不再重试
+	MaxDelay time.Duration
+	// Multiplier 退避倍数，默认 2
+	Multiplier float64
+	// Jitter 随机抖动比例 [0,1]：实际等待在 [d*(1-Jitter), d] 之间，默认 0.5；小于 0 表示不抖动
+	Jitter float64
+	// RetryOnStatus 需要重试的状态码，默认 429、502、503、504
+	RetryOnStatus []int
+	// RetryIf 自定义是否重试，设置后 RetryOnStatus 与默认的网络错误判断不再生效
+	RetryIf func(resp *http.Response, err error) bool
+	// IgnoreRetryAfter 忽略 429/503 响应中的 Retry-After
+	IgnoreRetryAfter bool
+	// OnRetry 每次重试等待前回调，attempt 为即将进行的第几次尝试（从 2 开始）
+	OnRetry func(attempt int, resp *http.Response, err error, delay time.Duration)
+}
+
+var defaultRetryStatus = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// Retry 开启失败重试（指数退避 + 抖动）。开启后请求体会被读入内存，以便每次重试重新发送；
+// 对 POST 等非幂等请求同样生效，需要时请配合 Idempotency-Key 等服务端去重机制。
+func (h *Requester) Retry(opts ...RetryOption) *Requester {
+	opt := RetryOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxAttempts <= 0 {
+		opt.MaxAttempts = 3
+	}
+	if opt.BaseDelay <= 0 {
+		opt.BaseDelay = 100 * time.Millisecond
+	}
+	if opt.MaxDelay <= 0 {
+		opt.MaxDelay = 10 * time.Second
+	}
+	if opt.Multiplier < 1 {
+		opt.Multiplier = 2
+	}
+	if opt.Jitter == 0 {
+		opt.Jitter = 0.5
+	} else if opt.Jitter < 0 {
+		opt.Jitter = 0
+	} else if opt.Jitter > 1 {
+		opt.Jitter = 1
+	}
+	if opt.RetryOnStatus == nil {
+		opt.RetryOnStatus = defaultRetryStatus
+	}
+	h.retry = &opt
+	return h
+}
+
+func (o *RetryOption) shouldRetry(resp *http.Response, err error) bool {
+	if o.RetryIf != nil {
+		return o.RetryIf(resp, err)
+	}
+	if err != nil {
+		// 主动取消、超时与熔断不重试
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrCircuitOpen)
+	}
+	return slices.Contains(o.RetryOnStatus, resp.StatusCode)
+}
+
+// backoff 第 n 次重试（从 1 开始）的等待时长；ok 为 false 表示 Retry-After 超出上限，不应重试。
+func (o *RetryOption) backoff(n int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil && !o.IgnoreRetryAfter {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d, d <= o.MaxDelay
+		}
+	}
+	d := float64(o.BaseDelay) * math.Pow(o.Multiplier, float64(n-1))
+	if d > float64(o.MaxDelay) {
+		d = float64(o.MaxDelay)
+	}
+	d -= d * o.Jitter * rand.Float64()
+	return time.Duration(d), true
+}
+
+// parseRetryAfter 支持秒数与 HTTP 日期两种格式（RFC 9110 §10.2.3）。
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// drain 丢弃将被重试的响应，读少量剩余数据以便连接复用。
+func drain(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, resp.Body, 4<<10)
+	resp.Body.Close()
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
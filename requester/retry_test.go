@@ -0,0 +1,112 @@
+package requester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		opt        RetryOption
+		attempt    int
+		retryAfter string
+		want       time.Duration
+		wantOK     bool
+	}{
+		{"first", RetryOption{BaseDelay: 100 * time.Millisecond, Jitter: -1}, 1, "", 100 * time.Millisecond, true},
+		{"exponential", RetryOption{BaseDelay: 100 * time.Millisecond, Jitter: -1}, 3, "", 400 * time.Millisecond, true},
+		{"capped", RetryOption{BaseDelay: time.Second, MaxDelay: 2 * time.Second, Jitter: -1}, 5, "", 2 * time.Second, true},
+		{"retry-after seconds", RetryOption{Jitter: -1}, 1, "2", 2 * time.Second, true},
+		{"retry-after too long", RetryOption{MaxDelay: time.Second}, 1, "5", 5 * time.Second, false},
+		{"retry-after ignored", RetryOption{BaseDelay: 50 * time.Millisecond, Jitter: -1, IgnoreRetryAfter: true}, 1, "5", 50 * time.Millisecond, true},
+		{"retry-after invalid", RetryOption{BaseDelay: 50 * time.Millisecond, Jitter: -1}, 1, "soon", 50 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewRequester().Retry(tt.opt).retry
+			resp := &http.Response{Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+			got, ok := o.backoff(tt.attempt, resp)
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("backoff = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryJitterRange(t *testing.T) {
+	o := NewRequester().Retry(RetryOption{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}).retry
+	for i := 0; i < 100; i++ {
+		if d, _ := o.backoff(1, nil); d < 50*time.Millisecond || d > 100*time.Millisecond {
+			t.Fatalf("delay %v out of range", d)
+		}
+	}
+}
+
+func TestRetryRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		opt      RetryOption
+		want     int
+		calls    int32
+	}{
+		{"recovers", []int{503, 502, 200}, RetryOption{}, 200, 3},
+		{"gives up", []int{503, 503, 503, 200}, RetryOption{}, 503, 3},
+		{"not retried status", []int{500, 200}, RetryOption{}, 500, 1},
+		{"custom status", []int{500, 200}, RetryOption{RetryOnStatus: []int{500}}, 200, 2},
+		{"retry if", []int{200, 201}, RetryOption{RetryIf: func(r *http.Response, err error) bool { return r.StatusCode == 200 }}, 201, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != "payload" {
+					t.Errorf("attempt %d body = %q", n, body)
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer srv.Close()
+
+			tt.opt.BaseDelay = time.Millisecond
+			r := NewRequester().Post(srv.URL, io.NopCloser(strings.NewReader("payload"))).Retry(tt.opt)
+			defer r.Close()
+			resp, err := r.Response(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want || calls.Load() != tt.calls {
+				t.Fatalf("status = %d, calls = %d", resp.StatusCode, calls.Load())
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var retries int
+	r := NewRequester().Get(srv.URL).Retry(RetryOption{OnRetry: func(int, *http.Response, error, time.Duration) { retries++ }})
+	defer r.Close()
+	if _, err := r.Response(ctx); !errors.Is(err, context.DeadlineExceeded) || retries != 1 {
+		t.Fatalf("err = %v, retries = %d", err, retries)
+	}
+}
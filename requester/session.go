@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"net/http"
+)
+
+// SessionOption 会话配置。
+type SessionOption struct {
+	ClientOption
+	// CookieFile 非空时 cookie 持久化到该文件；为空时使用 ClientOption.Jar，仍为空则保存在内存
+	CookieFile string
+	// Auth 认证方式，如 BasicAuth、BearerAuth、DigestAuth、TokenAuth(NewOAuth2(...))，位于其他拦截器之外
+	Auth Interceptor
+}
+
+// Session 有状态的客户端：在多次请求间保持 cookie，并透明地附加认证。可并发使用。
+type Session struct {
+	*Client
+	jar http.CookieJar
+}
+
+func NewSession(opts ...SessionOption) (*Session, error) {
+	opt := SessionOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	switch {
+	case opt.CookieFile != "":
+		jar, err := NewFileCookieJar(opt.CookieFile)
+		if err != nil {
+			return nil, err
+		}
+		opt.Jar = jar
+	case opt.Jar == nil:
+		opt.Jar = NewCookieJar()
+	}
+	if opt.Auth != nil {
+		opt.Interceptors = append([]Interceptor{opt.Auth}, opt.Interceptors...)
+	}
+
+	c, err := NewClient(opt.ClientOption)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{Client: c, jar: opt.Jar}, nil
+}
+
+// Jar 返回会话使用的 cookie 容器；未自定义 Jar 时为 *CookieJar。
+func (s *Session) Jar() http.CookieJar {
+	return s.jar
+}
+
+// Close 把 cookie 写回文件（使用 CookieFile 时）并关闭空闲连接。
+func (s *Session) Close() error {
+	s.CloseIdleConnections()
+	if jar, ok := s.jar.(*CookieJar); ok {
+		return jar.Save()
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ func (h *Requester) HandleStream(ctx context.Context, f func(response *http.Resp
 	defer resp.Body.Close()
 
 	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, h.maxLineSize())
 	for scanner.Scan() {
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,266 @@
+// Copyright (c) 2025 Rehtt
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package requester
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// PostForm 以 application/x-www-form-urlencoded 提交表单。
+func (h *Requester) PostForm(u string, form url.Values) *Requester {
+	h.m = http.MethodPost
+	h.url = u
+	h.SetHead("content-type", "application/x-www-form-urlencoded")
+	h.body = strings.NewReader(form.Encode())
+	return h
+}
+
+// PostMultipart 以 multipart/form-data 提交字段与文件。请求体边读边写（io.Pipe），不会整体读入内存。
+func (h *Requester) PostMultipart(u string, m *Multipart) *Requester {
+	h.m = http.MethodPost
+	h.url = u
+	h.SetHead("content-type", m.ContentType())
+	h.body = m.open()
+	return h
+}
+
+// UploadProgress 上传进度回调，sent 为已发送字节数，total 为请求体总长度（未知时为 -1）。
+// 开启重试时每次尝试都从 0 重新计数。
+func (h *Requester) UploadProgress(f func(sent, total int64)) *Requester {
+	h.uploadProgress = f
+	return h
+}
+
+// Multipart multipart/form-data 请求体构造器，按添加顺序写出各部分。
+type Multipart struct {
+	boundary string
+	parts    []multipartPart
+}
+
+type multipartPart struct {
+	field       string
+	value       string
+	isFile      bool
+	filename    string
+	contentType string
+	path        string
+	r           io.Reader
+}
+
+func NewMultipart() *Multipart {
+	return &Multipart{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// Field 添加普通字段。
+func (m *Multipart) Field(name, value string) *Multipart {
+	m.parts = append(m.parts, multipartPart{field: name, value: value})
+	return m
+}
+
+// File 添加磁盘文件，发送时才打开；Content-Type 按扩展名推断。
+func (m *Multipart) File(field, path string) *Multipart {
+	m.parts = append(m.parts, multipartPart{
+		field:       field,
+		isFile:      true,
+		filename:    filepath.Base(path),
+		contentType: mime.TypeByExtension(filepath.Ext(path)),
+		path:        path,
+	})
+	return m
+}
+
+// Reader 添加来自 r 的文件内容；contentType 为空时使用 application/octet-stream。
+// r 只能读取一次，开启重试时整个请求体会先读入内存。
+func (m *Multipart) Reader(field, filename, contentType string, r io.Reader) *Multipart {
+	m.parts = append(m.parts, multipartPart{
+		field:       field,
+		isFile:      true,
+		filename:    filename,
+		contentType: contentType,
+		r:           r,
+	})
+	return m
+}
+
+// ContentType 返回带 boundary 的 Content-Type。
+func (m *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + m.boundary
+}
+
+// replayable 不含一次性 Reader 时可以重复生成请求体。
+func (m *Multipart) replayable() bool {
+	for _, p := range m.parts {
+		if p.r != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// size 计算请求体长度，存在未知长度的 Reader 时返回 -1。
+func (m *Multipart) size() int64 {
+	var cw countWriter
+	mw := multipart.NewWriter(&cw)
+	mw.SetBoundary(m.boundary)
+	var n int64
+	for _, p := range m.parts {
+		if !p.isFile {
+			mw.WriteField(p.field, p.value)
+			continue
+		}
+		mw.CreatePart(p.header())
+		switch {
+		case p.path != "":
+			fi, err := os.Stat(p.path)
+			if err != nil {
+				return -1
+			}
+			n += fi.Size()
+		default:
+			size := bodySize(p.r)
+			if size < 0 {
+				return -1
+			}
+			n += size
+		}
+	}
+	mw.Close()
+	return int64(cw) + n
+}
+
+func (m *Multipart) writeTo(w io.Writer) error {
+	mw := multipart.NewWriter(w)
+	mw.SetBoundary(m.boundary)
+	for _, p := range m.parts {
+		if !p.isFile {
+			if err := mw.WriteField(p.field, p.value); err != nil {
+				return err
+			}
+			continue
+		}
+		pw, err := mw.CreatePart(p.header())
+		if err != nil {
+			return err
+		}
+		if p.path != "" {
+			f, err := os.Open(p.path)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(pw, f)
+			f.Close()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := io.Copy(pw, p.r); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// open 生成一份新的请求体，首次 Read 时才开始写出。
+func (m *Multipart) open() *multipartBody {
+	return &multipartBody{m: m}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func (p *multipartPart) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(p.field), quoteEscaper.Replace(p.filename)))
+	ct := p.contentType
+	if ct == "" {
+		ct = "application/octet-stream"
+	}
+	h.Set("Content-Type", ct)
+	return h
+}
+
+type multipartBody struct {
+	m    *Multipart
+	once sync.Once
+	pr   *io.PipeReader
+}
+
+func (b *multipartBody) start() {
+	b.once.Do(func() {
+		pr, pw := io.Pipe()
+		b.pr = pr
+		go func() {
+			pw.CloseWithError(b.m.writeTo(pw))
+		}()
+	})
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start()
+	return b.pr.Read(p)
+}
+
+// Close 由 http.Transport 在请求结束后调用，中止尚未写完的写出协程。
+func (b *multipartBody) Close() error {
+	b.start()
+	return b.pr.Close()
+}
+
+type countWriter int64
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
+
+// bodySize 返回已知类型请求体的剩余长度，未知时返回 -1。
+func bodySize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case *bytes.Reader:
+		return int64(v.Len())
+	case *bytes.Buffer:
+		return int64(v.Len())
+	case *strings.Reader:
+		return int64(v.Len())
+	case *multipartBody:
+		return v.m.size()
+	}
+	return -1
+}
+
+type progressReader struct {
+	r     io.Reader
+	sent  int64
+	total int64
+	f     func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.f(p.sent, p.total)
+	}
+	return n, err
+}
+
+func (p *progressReader) Close() error {
+	if c, ok := p.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package requester_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Rehtt/Kit/requester"
+)
+
+// uploadServer 解析 multipart 请求并回显字段与文件内容。
+func uploadServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			r.ParseForm()
+		}
+		var out []string
+		for k, v := range r.Form {
+			out = append(out, k+"="+strings.Join(v, ","))
+		}
+		if r.MultipartForm != nil {
+			for k, fhs := range r.MultipartForm.File {
+				f, _ := fhs[0].Open()
+				data, _ := io.ReadAll(f)
+				f.Close()
+				out = append(out, k+":"+fhs[0].Filename+"="+string(data))
+			}
+		}
+		w.Header().Set("X-Length", r.Header.Get("Content-Length"))
+		io.WriteString(w, strings.Join(out, "&"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPostForm(t *testing.T) {
+	srv := uploadServer(t)
+	r := requester.NewRequester().PostForm(srv.URL, url.Values{"a": {"1"}})
+	defer r.Close()
+	if got := r.AsString(context.Background()); got != "a=1" {
+		t.Fatalf("body = %q, err = %v", got, r.GetErr())
+	}
+}
+
+func TestPostMultipart(t *testing.T) {
+	srv := uploadServer(t)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	os.WriteFile(path, []byte("disk"), 0o644)
+
+	tests := []struct {
+		name       string
+		m          *requester.Multipart
+		want       string
+		wantLength bool
+	}{
+		{"file", requester.NewMultipart().Field("k", "v").File("f", path), "k=v&f:a.txt=disk", true},
+		{"sized reader", requester.NewMultipart().Reader("f", "b.bin", "", strings.NewReader("mem")), "f:b.bin=mem", true},
+		// 未知长度的 Reader 通过 io.Pipe 流式发送，使用 chunked 编码
+		{"stream", requester.NewMultipart().Reader("f", "c.bin", "", io.MultiReader(strings.NewReader("pipe"))), "f:c.bin=pipe", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent, total int64
+			r := requester.NewRequester().PostMultipart(srv.URL, tt.m).UploadProgress(func(s, t int64) { sent, total = s, t })
+			defer r.Close()
+			resp, err := r.Response(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Fatalf("body = %q, want %q", body, tt.want)
+			}
+			if hasLength := resp.Header.Get("X-Length") != ""; hasLength != tt.wantLength {
+				t.Fatalf("Content-Length sent = %v, want %v", hasLength, tt.wantLength)
+			}
+			if sent == 0 || (tt.wantLength && sent != total) || (!tt.wantLength && total != -1) {
+				t.Fatalf("progress sent=%d total=%d", sent, total)
+			}
+		})
+	}
+}
+
+func TestPostMultipartRetryReplays(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		r.ParseMultipartForm(1 << 20)
+		io.WriteString(w, r.FormValue("k"))
+	}))
+	defer srv.Close()
+
+	m := requester.NewMultipart().Field("k", "again")
+	r := requester.NewRequester().PostMultipart(srv.URL, m).Retry(requester.RetryOption{MaxAttempts: 2, BaseDelay: 1})
+	defer r.Close()
+	if got := r.AsString(context.Background()); got != "again" || calls != 2 {
+		t.Fatalf("body = %q, calls = %d", got, calls)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"github.com/Rehtt/Kit/web"
+)
+
+const defaultAPIKeyHeader = "X-API-Key"
+
+type APIKeyOption struct {
+	// Header 读取 key 的请求头；空走默认 X-API-Key。
+	Header string
+	// Query 读取 key 的查询参数，请求头缺失时使用；空串表示不从查询串读取。
+	// 查询串会进入访问日志与 Referer，仅建议用于无法设置请求头的场景。
+	Query string
+	// OnUnauthorized 缺少或无效 key 时调用；nil 走默认 401。
+	OnUnauthorized web.HandlerFunc
+}
+
+// APIKey 按请求头 / 查询参数认证。validate 返回主体名与是否通过，主体名挂到 ctx。
+func APIKey(validate func(c *web.Context, key string) (subject string, ok bool), opts ...APIKeyOption) web.HandlerFunc {
+	opt := APIKeyOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Header == "" {
+		opt.Header = defaultAPIKeyHeader
+	}
+	if opt.OnUnauthorized == nil {
+		opt.OnUnauthorized = defaultUnauthorized
+	}
+
+	return func(c *web.Context) {
+		key := c.Request.Header.Get(opt.Header)
+		if key == "" && opt.Query != "" {
+			key = c.Request.URL.Query().Get(opt.Query)
+		}
+		if key != "" {
+			if subject, ok := validate(c, key); ok {
+				c.SetContextValue(subjectKey{}, subject)
+				c.Next()
+				return
+			}
+		}
+		c.Stop()
+		opt.OnUnauthorized(c)
+	}
+}
+
+// APIKeys 基于固定 key → 主体名映射的校验函数，逐个以常量时间比较，耗时与命中位置无关。
+func APIKeys(keys map[string]string) func(c *web.Context, key string) (string, bool) {
+	return func(_ *web.Context, key string) (string, bool) {
+		subject, found := "", false
+		for k, name := range keys {
+			if secureCompare(key, k) && !found {
+				subject, found = name, true
+			}
+		}
+		return subject, found
+	}
+}
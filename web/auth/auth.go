@@ -0,0 +1,38 @@
+// Package auth 提供 GOweb 认证中间件：JWT（HS / RS / PS / ES / EdDSA，支持密钥集轮换与 JWKS）、
+// Basic、API Key，以及基于签名 / 加密 cookie 的会话与配套 CSRF 防护。
+//
+// 认证成功后主体标识挂在 ctx 上，经 GetSubject 读取；JWT 的完整声明经 GetClaims 读取，
+// 会话经 GetSession 读取。
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type subjectKey struct{}
+
+// GetSubject 取认证通过的主体：JWT 的 sub、Basic 的用户名或 API Key 校验函数返回的名字；未认证返回空串。
+func GetSubject(ctx context.Context) string {
+	s, _ := ctx.Value(subjectKey{}).(string)
+	return s
+}
+
+// secureCompare 常量时间比较；先取哈希使耗时与长度无关。
+func secureCompare(a, b string) bool {
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+func defaultUnauthorized(c *web.Context) {
+	http.Error(c.Writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+func defaultForbidden(c *web.Context) {
+	http.Error(c.Writer, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
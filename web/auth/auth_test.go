@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func TestBasic(t *testing.T) {
+	g := web.New()
+	g.Middlewares(Basic(BasicUsers(map[string]string{"alice": "s3cret"}), BasicOption{Realm: "admin"}))
+	g.GET("/", func(c *web.Context) { c.WriteString(GetSubject(c)) })
+
+	cases := []struct {
+		user, pass string
+		setAuth    bool
+		code       int
+	}{
+		{"alice", "s3cret", true, 200},
+		{"alice", "wrong", true, 401},
+		{"bob", "s3cret", true, 401},
+		{"", "", false, 401},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tc.setAuth {
+			req.SetBasicAuth(tc.user, tc.pass)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Fatalf("%s/%s: code = %d, want %d", tc.user, tc.pass, rec.Code, tc.code)
+		}
+		if tc.code == 200 && rec.Body.String() != "alice" {
+			t.Fatalf("subject = %q", rec.Body.String())
+		}
+		if tc.code == 401 && rec.Header().Get("WWW-Authenticate") != `Basic realm="admin", charset="UTF-8"` {
+			t.Fatalf("challenge = %q", rec.Header().Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	g := web.New()
+	g.Middlewares(APIKey(APIKeys(map[string]string{"k-123": "ci", "k-456": "cron"}), APIKeyOption{Query: "api_key"}))
+	g.GET("/", func(c *web.Context) { c.WriteString(GetSubject(c)) })
+
+	cases := []struct {
+		path, header string
+		code         int
+		subject      string
+	}{
+		{"/", "k-123", 200, "ci"},
+		{"/?api_key=k-456", "", 200, "cron"},
+		{"/?api_key=k-456", "bad", 401, ""},
+		{"/", "", 401, ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("X-API-Key", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		if rec.Code != tc.code || (tc.code == 200 && rec.Body.String() != tc.subject) {
+			t.Fatalf("%s %q: %d %q", tc.path, tc.header, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type BasicOption struct {
+	// Realm 写入 WWW-Authenticate；空走默认 "Restricted"。
+	Realm string
+	// OnUnauthorized 认证失败时调用，此时 WWW-Authenticate 已写入；nil 走默认 401。
+	OnUnauthorized web.HandlerFunc
+}
+
+// Basic HTTP Basic 认证（RFC 7617）。validate 返回 true 时用户名作为主体挂到 ctx。
+func Basic(validate func(username, password string) bool, opts ...BasicOption) web.HandlerFunc {
+	opt := BasicOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Realm == "" {
+		opt.Realm = "Restricted"
+	}
+	if opt.OnUnauthorized == nil {
+		opt.OnUnauthorized = defaultUnauthorized
+	}
+	challenge := "Basic realm=" + strconv.Quote(opt.Realm) + `, charset="UTF-8"`
+
+	return func(c *web.Context) {
+		user, pass, ok := c.Request.BasicAuth()
+		if ok && validate(user, pass) {
+			c.SetContextValue(subjectKey{}, user)
+			c.Next()
+			return
+		}
+		c.Writer.Header().Set("WWW-Authenticate", challenge)
+		c.Stop()
+		opt.OnUnauthorized(c)
+	}
+}
+
+// BasicUsers 基于固定用户表的校验函数，口令以常量时间比较；不存在的用户同样走一次比较，避免按耗时枚举用户名。
+func BasicUsers(users map[string]string) func(username, password string) bool {
+	return func(username, password string) bool {
+		want, ok := users[username]
+		match := secureCompare(password, want)
+		return ok && match
+	}
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrCookieInvalid = errors.New("[web] auth: cookie invalid")
+
+// CookieCodec 把值编码为防篡改的 cookie 串，并记录签发时间以便校验有效期。
+// cookie 名参与签名 / 认证，值不能被挪到另一个 cookie 下复用。
+type CookieCodec interface {
+	Encode(name string, value []byte) (string, error)
+	Decode(name, cookie string) (value []byte, issued time.Time, err error)
+}
+
+// SignedCookie HMAC-SHA256 签名，值对客户端可见但不可篡改；key 至少 32 字节。
+func SignedCookie(key []byte) CookieCodec {
+	if len(key) < 32 {
+		panic("[web] SignedCookie: key must be at least 32 bytes")
+	}
+	return &signedCookie{key: key}
+}
+
+// EncryptedCookie AES-GCM 加密，值对客户端不可见且不可篡改；key 为 16 / 24 / 32 字节。
+func EncryptedCookie(key []byte) CookieCodec {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic("[web] EncryptedCookie: " + err.Error())
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic("[web] EncryptedCookie: " + err.Error())
+	}
+	return &encryptedCookie{aead: aead}
+}
+
+var cookieEncoding = base64.RawURLEncoding
+
+// withTimestamp 在值前加 8 字节签发时间（Unix 秒）。
+func withTimestamp(value []byte) []byte {
+	buf := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(buf, uint64(time.Now().Unix()))
+	return append(buf, value...)
+}
+
+func splitTimestamp(payload []byte) ([]byte, time.Time, error) {
+	if len(payload) < 8 {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	return payload[8:], time.Unix(int64(binary.BigEndian.Uint64(payload)), 0), nil
+}
+
+type signedCookie struct {
+	key []byte
+}
+
+func (s *signedCookie) mac(name, payload string) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write([]byte(name))
+	m.Write([]byte{0})
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
+
+func (s *signedCookie) Encode(name string, value []byte) (string, error) {
+	payload := cookieEncoding.EncodeToString(withTimestamp(value))
+	return payload + "." + cookieEncoding.EncodeToString(s.mac(name, payload)), nil
+}
+
+func (s *signedCookie) Decode(name, cookie string) ([]byte, time.Time, error) {
+	payload, sig, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	mac, err := cookieEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(name, payload)) {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	data, err := cookieEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	return splitTimestamp(data)
+}
+
+type encryptedCookie struct {
+	aead cipher.AEAD
+}
+
+func (e *encryptedCookie) Encode(name string, value []byte) (string, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := e.aead.Seal(nonce, nonce, withTimestamp(value), []byte(name))
+	return cookieEncoding.EncodeToString(sealed), nil
+}
+
+func (e *encryptedCookie) Decode(name, cookie string) ([]byte, time.Time, error) {
+	data, err := cookieEncoding.DecodeString(cookie)
+	if err != nil || len(data) < e.aead.NonceSize() {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	n := e.aead.NonceSize()
+	plain, err := e.aead.Open(nil, data[:n], data[n:], []byte(name))
+	if err != nil {
+		return nil, time.Time{}, ErrCookieInvalid
+	}
+	return splitTimestamp(plain)
+}
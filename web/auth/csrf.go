@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const (
+	csrfSessionKey = "_csrf"
+	csrfSecretSize = 32
+)
+
+type CSRFOption struct {
+	// Header 读取 token 的请求头；空走默认 X-CSRF-Token。
+	Header string
+	// FormField 请求头缺失时读取的表单字段；空走默认 _csrf。
+	FormField string
+	// TrustedOrigins 跨域提交时额外放行的 Origin（如 https://app.example.com）；同源请求始终放行。
+	TrustedOrigins []string
+	// OnFailure 校验失败时调用；nil 走默认 403。
+	OnFailure web.HandlerFunc
+}
+
+type csrfKey struct{}
+
+// CSRF 会话模式下的 CSRF 防护，需挂在 Sessions 之后：每个会话持有一个随机 secret，
+// 非安全方法须经请求头或表单字段回传 CSRFToken 生成的 token，且 Origin（若有）须同源或受信。
+// 下发的 token 每次都用随机掩码重新编码，防止 BREACH 类压缩侧信道推断 secret。
+func CSRF(opts ...CSRFOption) web.HandlerFunc {
+	opt := CSRFOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Header == "" {
+		opt.Header = "X-CSRF-Token"
+	}
+	if opt.FormField == "" {
+		opt.FormField = csrfSessionKey
+	}
+	if opt.OnFailure == nil {
+		opt.OnFailure = defaultForbidden
+	}
+
+	return func(c *web.Context) {
+		sess := GetSession(c)
+		if sess == nil {
+			log.Printf("[web] auth: CSRF requires the Sessions middleware")
+			c.Stop()
+			opt.OnFailure(c)
+			return
+		}
+		st := &csrfState{sess: sess}
+		if !sess.Get(csrfSessionKey, &st.secret) || len(st.secret) != csrfSecretSize {
+			st.secret = nil
+		}
+		c.SetContextValue(csrfKey{}, st)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+		token := c.Request.Header.Get(opt.Header)
+		if token == "" {
+			token = c.Request.PostFormValue(opt.FormField)
+		}
+		// 会话中没有 secret 说明从未下发过 token
+		if !originAllowed(c.Request, opt.TrustedOrigins) || st.secret == nil || !validCSRFToken(token, st.secret) {
+			c.Stop()
+			opt.OnFailure(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// csrfState 请求内的 CSRF secret。secret 在首次调用 CSRFToken 时才生成并写入会话，
+// 不需要 token 的请求不会改动会话，也就不会下发 Set-Cookie（匿名 GET 仍可被缓存）。
+type csrfState struct {
+	mu     sync.Mutex
+	sess   *Session
+	secret []byte
+}
+
+func (s *csrfState) ensureSecret() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.secret == nil {
+		s.secret = make([]byte, csrfSecretSize)
+		_, _ = rand.Read(s.secret)
+		_ = s.sess.Set(csrfSessionKey, s.secret)
+	}
+	return s.secret
+}
+
+// CSRFToken 生成当前会话的 CSRF token，用于模板中的隐藏字段或前端请求头；未启用 CSRF 时返回空串。
+// 会话尚无 secret 时会生成并写入会话，因此须在写出响应前调用，以便 Sessions 补写 cookie。
+func CSRFToken(ctx context.Context) string {
+	st, _ := ctx.Value(csrfKey{}).(*csrfState)
+	if st == nil {
+		return ""
+	}
+	secret := st.ensureSecret()
+	buf := make([]byte, 2*csrfSecretSize)
+	_, _ = rand.Read(buf[:csrfSecretSize])
+	subtle.XORBytes(buf[csrfSecretSize:], buf[:csrfSecretSize], secret)
+	return cookieEncoding.EncodeToString(buf)
+}
+
+func validCSRFToken(token string, secret []byte) bool {
+	buf, err := cookieEncoding.DecodeString(token)
+	if err != nil || len(buf) != 2*csrfSecretSize {
+		return false
+	}
+	got := make([]byte, csrfSecretSize)
+	subtle.XORBytes(got, buf[:csrfSecretSize], buf[csrfSecretSize:])
+	return subtle.ConstantTimeCompare(got, secret) == 1
+}
+
+// originAllowed 没有 Origin 时放行（交给 token 校验）；有则须与 Host 同源或在受信列表中。
+func originAllowed(r *http.Request, trusted []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if slices.Contains(trusted, origin) {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Key 一把签名 / 校验密钥。
+type Key struct {
+	// ID 对应 JWT 头部的 kid。
+	ID string
+	// Algorithm 限定该键可用的算法；校验时为空串表示不限（仍需与密钥类型匹配），签名时必填。
+	Algorithm string
+	// Key 校验用 []byte（HS*）、*rsa.PublicKey（RS* / PS*）、*ecdsa.PublicKey（ES*）、ed25519.PublicKey（EdDSA），
+	// 签名用对应私钥；私钥同样可用于校验。
+	Key any
+}
+
+// KeyProvider 为 token 提供候选校验密钥。kid 为空时返回全部可能的键，由调用方逐个尝试。
+type KeyProvider interface {
+	Keys(ctx context.Context, kid, alg string) ([]Key, error)
+}
+
+// KeySet 进程内密钥集，可在运行期增删实现轮换：先 Add 新键开始签发，旧 token 过期后再 Remove 旧键。
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []Key
+}
+
+func NewKeySet(keys ...Key) *KeySet {
+	s := &KeySet{}
+	s.Add(keys...)
+	return s
+}
+
+// Add 加入密钥，ID 相同的旧键被替换。
+func (s *KeySet) Add(keys ...Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range keys {
+		replaced := false
+		for i := range s.keys {
+			if k.ID != "" && s.keys[i].ID == k.ID {
+				s.keys[i] = k
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			s.keys = append(s.keys, k)
+		}
+	}
+}
+
+func (s *KeySet) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := s.keys[:0:0]
+	for _, k := range s.keys {
+		if k.ID != id {
+			keys = append(keys, k)
+		}
+	}
+	s.keys = keys
+}
+
+// Replace 整体替换密钥集。
+func (s *KeySet) Replace(keys ...Key) {
+	s.mu.Lock()
+	s.keys = append([]Key(nil), keys...)
+	s.mu.Unlock()
+}
+
+func (s *KeySet) Keys(_ context.Context, kid, alg string) ([]Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return filterKeys(s.keys, kid, alg)
+}
+
+func filterKeys(keys []Key, kid, alg string) ([]Key, error) {
+	var out []Key
+	for _, k := range keys {
+		if kid != "" && k.ID != kid {
+			continue
+		}
+		if k.Algorithm != "" && k.Algorithm != alg {
+			continue
+		}
+		out = append(out, k)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: kid %q alg %q", ErrKeyNotFound, kid, alg)
+	}
+	return out, nil
+}
+
+type RemoteKeySetOption struct {
+	// Client 拉取 JWKS 使用的客户端；nil 走带 10s 超时的默认客户端。
+	Client *http.Client
+	// RefreshInterval 定期刷新间隔；0 走默认 1 小时。
+	RefreshInterval time.Duration
+	// MinRefreshInterval 遇到未知 kid 时触发刷新的最小间隔，防止伪造 kid 打爆上游；0 走默认 1 分钟。
+	MinRefreshInterval time.Duration
+}
+
+// RemoteKeySet 从 JWKS 端点（如 OIDC 的 jwks_uri）拉取公钥：按需懒加载、定期刷新，
+// 遇到未知 kid 时提前刷新以跟上签发方的密钥轮换。刷新失败时继续使用上一次的密钥。
+type RemoteKeySet struct {
+	url string
+	opt RemoteKeySetOption
+
+	// fetchMu 串行化拉取，并发请求只触发一次
+	fetchMu     sync.Mutex
+	mu          sync.RWMutex
+	keys        []Key
+	fetchedAt   time.Time
+	lastAttempt time.Time
+}
+
+func NewRemoteKeySet(url string, opts ...RemoteKeySetOption) *RemoteKeySet {
+	opt := RemoteKeySetOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Client == nil {
+		opt.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if opt.RefreshInterval <= 0 {
+		opt.RefreshInterval = time.Hour
+	}
+	if opt.MinRefreshInterval <= 0 {
+		opt.MinRefreshInterval = time.Minute
+	}
+	return &RemoteKeySet{url: url, opt: opt}
+}
+
+func (s *RemoteKeySet) Keys(ctx context.Context, kid, alg string) ([]Key, error) {
+	s.mu.RLock()
+	keys, fetchedAt, lastAttempt := s.keys, s.fetchedAt, s.lastAttempt
+	s.mu.RUnlock()
+
+	now := time.Now()
+	stale := fetchedAt.IsZero() || now.Sub(fetchedAt) >= s.opt.RefreshInterval
+	if !stale {
+		if out, err := filterKeys(keys, kid, alg); err == nil {
+			return out, nil
+		}
+	}
+	if stale || now.Sub(lastAttempt) >= s.opt.MinRefreshInterval {
+		if err := s.refresh(ctx, lastAttempt); err != nil {
+			log.Printf("[web] auth: refresh jwks %s: %v", s.url, err)
+			if len(keys) == 0 {
+				return nil, err
+			}
+		}
+		s.mu.RLock()
+		keys = s.keys
+		s.mu.RUnlock()
+	}
+	return filterKeys(keys, kid, alg)
+}
+
+// Refresh 立即重新拉取 JWKS。
+func (s *RemoteKeySet) Refresh(ctx context.Context) error {
+	return s.refresh(ctx, time.Now())
+}
+
+// refresh seen 为调用方观察到的上次尝试时间；拿到锁后发现已有更新的尝试则直接复用。
+func (s *RemoteKeySet) refresh(ctx context.Context, seen time.Time) error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	s.mu.RLock()
+	done := s.lastAttempt.After(seen)
+	s.mu.RUnlock()
+	if done {
+		return nil
+	}
+
+	keys, err := s.fetch(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastAttempt = time.Now()
+	if err != nil {
+		return err
+	}
+	s.keys = keys
+	s.fetchedAt = s.lastAttempt
+	return nil
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) ([]Key, error) {
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.opt.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[web] auth: jwks status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS 解析 JWK Set（RFC 7517），支持 RSA、EC（P-256/384/521）、OKP（Ed25519）与 oct；
+// use 为 enc 或类型不支持的键被跳过。
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("[web] auth: parse jwks: %w", err)
+	}
+	keys := make([]Key, 0, len(set.Keys))
+	for _, j := range set.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		key, err := j.key()
+		if errors.Is(err, errUnsupportedJWK) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[web] auth: jwk %q: %w", j.Kid, err)
+		}
+		keys = append(keys, Key{ID: j.Kid, Algorithm: j.Alg, Key: key})
+	}
+	return keys, nil
+}
+
+var errUnsupportedJWK = errors.New("unsupported jwk")
+
+func (j jwk) key() (any, error) {
+	b64 := base64.RawURLEncoding
+	switch j.Kty {
+	case "RSA":
+		n, err := b64.DecodeString(j.N)
+		if err != nil || len(n) == 0 {
+			return nil, errors.New("invalid n")
+		}
+		e, err := b64.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid e")
+		}
+		exp := int(new(big.Int).SetBytes(e).Int64())
+		if exp < 3 {
+			return nil, errors.New("invalid e")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exp}, nil
+	case "EC":
+		var (
+			curve elliptic.Curve
+			check ecdh.Curve
+		)
+		switch j.Crv {
+		case "P-256":
+			curve, check = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, check = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, check = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, errUnsupportedJWK
+		}
+		x, err1 := b64.DecodeString(j.X)
+		y, err2 := b64.DecodeString(j.Y)
+		size := (curve.Params().BitSize + 7) / 8
+		if err1 != nil || err2 != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("invalid point")
+		}
+		// 借 ecdh 校验点在曲线上
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := check.NewPublicKey(point); err != nil {
+			return nil, errors.New("invalid point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	case "OKP":
+		if j.Crv != "Ed25519" {
+			return nil, errUnsupportedJWK
+		}
+		x, err := b64.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		k, err := b64.DecodeString(j.K)
+		if err != nil || len(k) == 0 {
+			return nil, errors.New("invalid k")
+		}
+		return k, nil
+	}
+	return nil, errUnsupportedJWK
+}
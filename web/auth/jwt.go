@@ -0,0 +1,445 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+var (
+	ErrTokenMissing         = errors.New("[web] auth: token missing")
+	ErrTokenMalformed       = errors.New("[web] auth: token malformed")
+	ErrTokenSignature       = errors.New("[web] auth: token signature invalid")
+	ErrTokenExpired         = errors.New("[web] auth: token expired")
+	ErrTokenNotYetValid     = errors.New("[web] auth: token not yet valid")
+	ErrTokenClaims          = errors.New("[web] auth: token claims invalid")
+	ErrUnsupportedAlgorithm = errors.New("[web] auth: unsupported algorithm")
+	ErrKeyNotFound          = errors.New("[web] auth: key not found")
+)
+
+// Claims JWT 声明集。数字按 JSON 解码为 float64，自定义结构可用 Decode 转换。
+type Claims map[string]any
+
+func (c Claims) str(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+func (c Claims) Subject() string { return c.str("sub") }
+func (c Claims) Issuer() string  { return c.str("iss") }
+func (c Claims) ID() string      { return c.str("jti") }
+
+// Audience aud 可以是字符串或字符串数组，统一返回切片。
+func (c Claims) Audience() []string {
+	switch v := c["aud"].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		aud := make([]string, 0, len(v))
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				aud = append(aud, s)
+			}
+		}
+		return aud
+	case []string:
+		return v
+	}
+	return nil
+}
+
+func (c Claims) time(name string) (time.Time, bool) {
+	switch v := c[name].(type) {
+	case float64:
+		return time.Unix(0, int64(v*float64(time.Second))), true
+	case json.Number:
+		f, err := v.Float64()
+		return time.Unix(0, int64(f*float64(time.Second))), err == nil
+	case int64:
+		return time.Unix(v, 0), true
+	case int:
+		return time.Unix(int64(v), 0), true
+	}
+	return time.Time{}, false
+}
+
+func (c Claims) ExpiresAt() (time.Time, bool) { return c.time("exp") }
+func (c Claims) NotBefore() (time.Time, bool) { return c.time("nbf") }
+func (c Claims) IssuedAt() (time.Time, bool)  { return c.time("iat") }
+
+// Decode 把声明转换到自定义结构（按 json tag）。
+func (c Claims) Decode(v any) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+type JWTOption struct {
+	// Algorithms 允许的算法；nil 允许全部已支持算法（仍需与密钥类型匹配），"none" 始终拒绝。
+	Algorithms []string
+	// Issuer / Audience 非空时校验 iss / aud。
+	Issuer   string
+	Audience string
+	// RequireExpiry 为 true 时拒绝缺少 exp 的 token。
+	RequireExpiry bool
+	// Leeway 校验 exp / nbf 时容忍的时钟偏差。
+	Leeway time.Duration
+	// Now 当前时间；nil 走 time.Now。
+	Now func() time.Time
+
+	// 以下字段仅用于 JWT 中间件。
+
+	// TokenLookup 取 token；nil 按 "Authorization: Bearer <token>"。
+	TokenLookup func(c *web.Context) string
+	// Optional 为 true 时缺少 token 直接放行（不写入声明），token 无效仍拒绝。
+	Optional bool
+	// OnError 认证失败时调用，此时 WWW-Authenticate 已写入；nil 走默认 401。
+	OnError func(c *web.Context, err error)
+}
+
+type claimsKey struct{}
+
+// GetClaims 取 JWT 中间件校验通过的声明。
+func GetClaims(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
+
+// JWT 校验 Bearer token：签名由 keys 按 kid / alg 提供候选密钥，通过后声明挂到 ctx（GetClaims），
+// sub 作为主体（GetSubject）。
+func JWT(keys KeyProvider, opts ...JWTOption) web.HandlerFunc {
+	opt := JWTOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.TokenLookup == nil {
+		opt.TokenLookup = BearerToken
+	}
+	if opt.OnError == nil {
+		opt.OnError = func(c *web.Context, err error) { defaultUnauthorized(c) }
+	}
+
+	return func(c *web.Context) {
+		token := opt.TokenLookup(c)
+		if token == "" && opt.Optional {
+			c.Next()
+			return
+		}
+		var (
+			claims Claims
+			err    = ErrTokenMissing
+		)
+		if token != "" {
+			claims, err = ParseJWT(c, token, keys, opt)
+		}
+		if err != nil {
+			if errors.Is(err, ErrTokenMissing) {
+				c.Writer.Header().Set("WWW-Authenticate", "Bearer")
+			} else {
+				c.Writer.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			}
+			c.Stop()
+			opt.OnError(c, err)
+			return
+		}
+		c.SetContextValue(claimsKey{}, claims)
+		c.SetContextValue(subjectKey{}, claims.Subject())
+		c.Next()
+	}
+}
+
+// BearerToken 从 Authorization 头取 Bearer token，scheme 不区分大小写。
+func BearerToken(c *web.Context) string {
+	scheme, token, ok := strings.Cut(c.Request.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// ParseJWT 校验 JWS 紧凑序列化的 token 并返回声明：先验签再校验 exp / nbf / iss / aud。
+func ParseJWT(ctx context.Context, token string, keys KeyProvider, opts ...JWTOption) (Claims, error) {
+	opt := JWTOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	alg, ok := algorithms[header.Alg]
+	if !ok || (opt.Algorithms != nil && !slices.Contains(opt.Algorithms, header.Alg)) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+	candidates, err := keys.Keys(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	signed := []byte(token[:len(parts[0])+1+len(parts[1])])
+	verified := false
+	for _, k := range candidates {
+		if k.Algorithm != "" && k.Algorithm != header.Alg {
+			continue
+		}
+		if alg.verify(k.Key, signed, sig) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrTokenSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := validateClaims(claims, opt); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return ErrTokenMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrTokenMalformed
+	}
+	return nil
+}
+
+func validateClaims(claims Claims, opt JWTOption) error {
+	now := time.Now()
+	if opt.Now != nil {
+		now = opt.Now()
+	}
+	exp, hasExp := claims.ExpiresAt()
+	if hasExp && now.After(exp.Add(opt.Leeway)) {
+		return ErrTokenExpired
+	}
+	if !hasExp && opt.RequireExpiry {
+		return fmt.Errorf("%w: exp required", ErrTokenClaims)
+	}
+	if nbf, ok := claims.NotBefore(); ok && now.Add(opt.Leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+	if opt.Issuer != "" && claims.Issuer() != opt.Issuer {
+		return fmt.Errorf("%w: iss %q", ErrTokenClaims, claims.Issuer())
+	}
+	if opt.Audience != "" && !slices.Contains(claims.Audience(), opt.Audience) {
+		return fmt.Errorf("%w: aud %q", ErrTokenClaims, claims.Audience())
+	}
+	return nil
+}
+
+// SignJWT 用 key 签发 token：alg 取 key.Algorithm（必填），kid 取 key.ID；claims 可以是 Claims 或任意可 JSON 序列化的结构。
+func SignJWT(key Key, claims any) (string, error) {
+	alg, ok := algorithms[key.Algorithm]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, key.Algorithm)
+	}
+	header, err := json.Marshal(jwtHeader{Alg: key.Algorithm, Kid: key.ID, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(payload)
+	sig, err := alg.sign(key.Key, []byte(signed))
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+type algorithm struct {
+	verify func(key any, signed, sig []byte) error
+	sign   func(key any, signed []byte) ([]byte, error)
+}
+
+var algorithms = map[string]algorithm{
+	"HS256": hmacAlg(crypto.SHA256),
+	"HS384": hmacAlg(crypto.SHA384),
+	"HS512": hmacAlg(crypto.SHA512),
+	"RS256": rsaAlg(crypto.SHA256, false),
+	"RS384": rsaAlg(crypto.SHA384, false),
+	"RS512": rsaAlg(crypto.SHA512, false),
+	"PS256": rsaAlg(crypto.SHA256, true),
+	"PS384": rsaAlg(crypto.SHA384, true),
+	"PS512": rsaAlg(crypto.SHA512, true),
+	"ES256": ecdsaAlg(crypto.SHA256, "P-256"),
+	"ES384": ecdsaAlg(crypto.SHA384, "P-384"),
+	"ES512": ecdsaAlg(crypto.SHA512, "P-521"),
+	"EdDSA": {verify: ed25519Verify, sign: ed25519Sign},
+}
+
+var errKeyType = errors.New("[web] auth: key type does not match algorithm")
+
+func digest(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
+
+func hmacAlg(h crypto.Hash) algorithm {
+	mac := func(key any, signed []byte) ([]byte, error) {
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return nil, errKeyType
+		}
+		m := hmac.New(h.New, secret)
+		m.Write(signed)
+		return m.Sum(nil), nil
+	}
+	return algorithm{
+		sign: mac,
+		verify: func(key any, signed, sig []byte) error {
+			want, err := mac(key, signed)
+			if err != nil {
+				return err
+			}
+			if !hmac.Equal(sig, want) {
+				return ErrTokenSignature
+			}
+			return nil
+		},
+	}
+}
+
+func rsaAlg(h crypto.Hash, pss bool) algorithm {
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: h}
+	return algorithm{
+		verify: func(key any, signed, sig []byte) error {
+			var pub *rsa.PublicKey
+			switch k := key.(type) {
+			case *rsa.PublicKey:
+				pub = k
+			case *rsa.PrivateKey:
+				pub = &k.PublicKey
+			default:
+				return errKeyType
+			}
+			if pss {
+				return rsa.VerifyPSS(pub, h, digest(h, signed), sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto, Hash: h})
+			}
+			return rsa.VerifyPKCS1v15(pub, h, digest(h, signed), sig)
+		},
+		sign: func(key any, signed []byte) ([]byte, error) {
+			priv, ok := key.(*rsa.PrivateKey)
+			if !ok {
+				return nil, errKeyType
+			}
+			if pss {
+				return rsa.SignPSS(rand.Reader, priv, h, digest(h, signed), pssOpts)
+			}
+			return rsa.SignPKCS1v15(rand.Reader, priv, h, digest(h, signed))
+		},
+	}
+}
+
+// ecdsaAlg JWS 的 ECDSA 签名是定长 r || s（RFC 7518 §3.4），而非 ASN.1。
+func ecdsaAlg(h crypto.Hash, curve string) algorithm {
+	return algorithm{
+		verify: func(key any, signed, sig []byte) error {
+			var pub *ecdsa.PublicKey
+			switch k := key.(type) {
+			case *ecdsa.PublicKey:
+				pub = k
+			case *ecdsa.PrivateKey:
+				pub = &k.PublicKey
+			default:
+				return errKeyType
+			}
+			if pub.Curve.Params().Name != curve {
+				return errKeyType
+			}
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			if len(sig) != 2*size {
+				return ErrTokenSignature
+			}
+			r := new(big.Int).SetBytes(sig[:size])
+			s := new(big.Int).SetBytes(sig[size:])
+			if !ecdsa.Verify(pub, digest(h, signed), r, s) {
+				return ErrTokenSignature
+			}
+			return nil
+		},
+		sign: func(key any, signed []byte) ([]byte, error) {
+			priv, ok := key.(*ecdsa.PrivateKey)
+			if !ok || priv.Curve.Params().Name != curve {
+				return nil, errKeyType
+			}
+			r, s, err := ecdsa.Sign(rand.Reader, priv, digest(h, signed))
+			if err != nil {
+				return nil, err
+			}
+			size := (priv.Curve.Params().BitSize + 7) / 8
+			sig := make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+			return sig, nil
+		},
+	}
+}
+
+func ed25519Verify(key any, signed, sig []byte) error {
+	var pub ed25519.PublicKey
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		pub = k
+	case ed25519.PrivateKey:
+		pub = k.Public().(ed25519.PublicKey)
+	default:
+		return errKeyType
+	}
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, signed, sig) {
+		return ErrTokenSignature
+	}
+	return nil
+}
+
+func ed25519Sign(key any, signed []byte) ([]byte, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok || len(priv) != ed25519.PrivateKeySize {
+		return nil, errKeyType
+	}
+	return ed25519.Sign(priv, signed), nil
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func TestJWTAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ec256, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ec384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	ec521, _ := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	cases := []struct {
+		alg       string
+		sign, pub any
+	}{
+		{"HS256", secret, secret},
+		{"HS384", secret, secret},
+		{"HS512", secret, secret},
+		{"RS256", rsaKey, &rsaKey.PublicKey},
+		{"RS512", rsaKey, &rsaKey.PublicKey},
+		{"PS256", rsaKey, &rsaKey.PublicKey},
+		{"PS384", rsaKey, &rsaKey.PublicKey},
+		{"ES256", ec256, &ec256.PublicKey},
+		{"ES384", ec384, &ec384.PublicKey},
+		{"ES512", ec521, &ec521.PublicKey},
+		{"EdDSA", edPriv, edPub},
+	}
+	claims := Claims{"sub": "alice", "exp": time.Now().Add(time.Minute).Unix()}
+	for _, tc := range cases {
+		t.Run(tc.alg, func(t *testing.T) {
+			token, err := SignJWT(Key{ID: "k1", Algorithm: tc.alg, Key: tc.sign}, claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseJWT(context.Background(), token, NewKeySet(Key{ID: "k1", Key: tc.pub}))
+			if err != nil || got.Subject() != "alice" {
+				t.Fatalf("ParseJWT = %v, %v", got, err)
+			}
+			// 篡改 payload
+			parts := strings.Split(token, ".")
+			parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory"}`))
+			if _, err := ParseJWT(context.Background(), strings.Join(parts, "."), NewKeySet(Key{ID: "k1", Key: tc.pub})); !errors.Is(err, ErrTokenSignature) {
+				t.Fatalf("tampered token: err = %v", err)
+			}
+		})
+	}
+}
+
+func TestJWTRejectsNoneAndKeyConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	keys := NewKeySet(Key{Key: &rsaKey.PublicKey})
+
+	enc := base64.RawURLEncoding
+	none := enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(`{"sub":"x"}`)) + "."
+	if _, err := ParseJWT(context.Background(), none, keys); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("alg none: err = %v", err)
+	}
+
+	// 以 RSA 公钥字节作为 HMAC 密钥伪造 HS256
+	pubBytes := rsaKey.PublicKey.N.Bytes()
+	forged, _ := SignJWT(Key{Algorithm: "HS256", Key: pubBytes}, Claims{"sub": "x"})
+	if _, err := ParseJWT(context.Background(), forged, keys); !errors.Is(err, ErrTokenSignature) {
+		t.Fatalf("key confusion: err = %v", err)
+	}
+
+	token, _ := SignJWT(Key{Algorithm: "RS256", Key: rsaKey}, Claims{"sub": "x"})
+	if _, err := ParseJWT(context.Background(), token, keys, JWTOption{Algorithms: []string{"ES256"}}); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Fatalf("disallowed alg: err = %v", err)
+	}
+}
+
+func TestJWTClaimsValidation(t *testing.T) {
+	key := Key{Algorithm: "HS256", Key: []byte("secret-secret-secret-secret-1234")}
+	keys := NewKeySet(key)
+	now := time.Unix(1_700_000_000, 0)
+	opt := JWTOption{Issuer: "kit", Audience: "api", Leeway: 5 * time.Second, Now: func() time.Time { return now }}
+
+	cases := []struct {
+		name   string
+		claims Claims
+		want   error
+	}{
+		{"ok", Claims{"iss": "kit", "aud": []string{"web", "api"}, "exp": now.Unix() + 60}, nil},
+		{"leeway", Claims{"iss": "kit", "aud": "api", "exp": now.Unix() - 3}, nil},
+		{"expired", Claims{"iss": "kit", "aud": "api", "exp": now.Unix() - 10}, ErrTokenExpired},
+		{"nbf", Claims{"iss": "kit", "aud": "api", "nbf": now.Unix() + 60}, ErrTokenNotYetValid},
+		{"iss", Claims{"iss": "other", "aud": "api"}, ErrTokenClaims},
+		{"aud", Claims{"iss": "kit", "aud": "web"}, ErrTokenClaims},
+	}
+	for _, tc := range cases {
+		token, _ := SignJWT(key, tc.claims)
+		_, err := ParseJWT(context.Background(), token, keys, opt)
+		if !errors.Is(err, tc.want) && !(tc.want == nil && err == nil) {
+			t.Errorf("%s: err = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	token, _ := SignJWT(key, Claims{"iss": "kit", "aud": "api"})
+	opt.RequireExpiry = true
+	if _, err := ParseJWT(context.Background(), token, keys, opt); !errors.Is(err, ErrTokenClaims) {
+		t.Fatalf("RequireExpiry: err = %v", err)
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldKey := Key{ID: "2024", Algorithm: "HS256", Key: []byte("old-secret-old-secret-old-secret")}
+	newKey := Key{ID: "2025", Algorithm: "HS256", Key: []byte("new-secret-new-secret-new-secret")}
+	keys := NewKeySet(oldKey)
+	oldToken, _ := SignJWT(oldKey, Claims{"sub": "a"})
+
+	keys.Add(newKey)
+	newToken, _ := SignJWT(newKey, Claims{"sub": "b"})
+	for _, tok := range []string{oldToken, newToken} {
+		if _, err := ParseJWT(context.Background(), tok, keys); err != nil {
+			t.Fatalf("during rotation: %v", err)
+		}
+	}
+	keys.Remove("2024")
+	if _, err := ParseJWT(context.Background(), oldToken, keys); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("removed key: err = %v", err)
+	}
+
+	// 无 kid 的 token 逐个尝试候选键
+	noKid, _ := SignJWT(Key{Algorithm: "HS256", Key: newKey.Key}, Claims{"sub": "c"})
+	keys.Replace(Key{ID: "x", Key: []byte("another-secret-another-secret-12")}, Key{ID: "y", Key: newKey.Key})
+	if _, err := ParseJWT(context.Background(), noKid, keys); err != nil {
+		t.Fatalf("no kid: %v", err)
+	}
+}
+
+func jwkOf(t *testing.T, kid string, pub any) map[string]string {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		e := []byte{byte(k.E >> 16), byte(k.E >> 8), byte(k.E)}
+		return map[string]string{"kty": "RSA", "kid": kid, "n": b64(k.N.Bytes()), "e": b64(e)}
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		x, y := make([]byte, size), make([]byte, size)
+		k.X.FillBytes(x)
+		k.Y.FillBytes(y)
+		return map[string]string{"kty": "EC", "kid": kid, "crv": k.Curve.Params().Name, "x": b64(x), "y": b64(y)}
+	case ed25519.PublicKey:
+		return map[string]string{"kty": "OKP", "kid": kid, "crv": "Ed25519", "x": b64(k)}
+	}
+	t.Fatalf("unsupported key %T", pub)
+	return nil
+}
+
+func TestRemoteKeySetRotation(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	edPub, edPriv, _ := ed25519.GenerateKey(rand.Reader)
+
+	var (
+		published atomic.Value
+		fetches   atomic.Int32
+	)
+	publish := func(keys ...map[string]string) {
+		data, _ := json.Marshal(map[string]any{"keys": keys})
+		published.Store(data)
+	}
+	publish(jwkOf(t, "rsa", &rsaKey.PublicKey), jwkOf(t, "ec", &ecKey.PublicKey),
+		map[string]string{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(published.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetOption{MinRefreshInterval: time.Millisecond})
+	for _, k := range []Key{{ID: "rsa", Algorithm: "RS256", Key: rsaKey}, {ID: "ec", Algorithm: "ES384", Key: ecKey}} {
+		token, _ := SignJWT(k, Claims{"sub": k.ID})
+		if _, err := ParseJWT(context.Background(), token, keys); err != nil {
+			t.Fatalf("%s: %v", k.ID, err)
+		}
+	}
+	if fetches.Load() != 1 {
+		t.Fatalf("fetches = %d, want 1", fetches.Load())
+	}
+
+	// 签发方轮换到新键：未知 kid 触发提前刷新
+	publish(jwkOf(t, "ed", edPub))
+	time.Sleep(2 * time.Millisecond)
+	token, _ := SignJWT(Key{ID: "ed", Algorithm: "EdDSA", Key: edPriv}, Claims{"sub": "ed"})
+	if _, err := ParseJWT(context.Background(), token, keys); err != nil {
+		t.Fatalf("rotated key: %v", err)
+	}
+	if fetches.Load() != 2 {
+		t.Fatalf("fetches = %d, want 2", fetches.Load())
+	}
+}
+
+func TestJWTMiddleware(t *testing.T) {
+	key := Key{ID: "k", Algorithm: "HS256", Key: []byte("middleware-secret-middleware-sec")}
+	g := web.New()
+	api := g.Grep("/api")
+	api.Middlewares(JWT(NewKeySet(key), JWTOption{Audience: "api"}))
+	api.GET("/me", func(c *web.Context) {
+		claims, _ := GetClaims(c)
+		var v struct {
+			Role string `json:"role"`
+		}
+		claims.Decode(&v)
+		fmt.Fprintf(c.Writer, "%s %s", GetSubject(c), v.Role)
+	})
+	pub := g.Grep("/pub")
+	pub.Middlewares(JWT(NewKeySet(key), JWTOption{Optional: true}))
+	pub.GET("/", func(c *web.Context) { c.WriteString("sub=" + GetSubject(c)) })
+
+	do := func(path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+
+	token, _ := SignJWT(key, Claims{"sub": "alice", "aud": "api", "role": "admin"})
+	if rec := do("/api/me", "bearer "+token); rec.Code != 200 || rec.Body.String() != "alice admin" {
+		t.Fatalf("valid: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := do("/api/me", ""); rec.Code != 401 || rec.Header().Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("missing: %d %v", rec.Code, rec.Header())
+	}
+	wrongAud, _ := SignJWT(key, Claims{"sub": "alice", "aud": "web"})
+	if rec := do("/api/me", "Bearer "+wrongAud); rec.Code != 401 || !strings.Contains(rec.Header().Get("WWW-Authenticate"), "invalid_token") {
+		t.Fatalf("wrong aud: %d %v", rec.Code, rec.Header())
+	}
+	if rec := do("/pub/", ""); rec.Code != 200 || rec.Body.String() != "sub=" {
+		t.Fatalf("optional: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := do("/pub/", "Bearer garbage"); rec.Code != 401 {
+		t.Fatalf("optional invalid: %d", rec.Code)
+	}
+}
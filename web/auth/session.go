@@ -0,0 +1,387 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const (
+	defaultSessionCookie = "session"
+	defaultSessionMaxAge = 24 * time.Hour
+	// maxCookieSize 浏览器普遍的单个 cookie 上限
+	maxCookieSize = 4096
+)
+
+type SessionOption struct {
+	// FallbackCodecs 仅用于解码的旧编解码器，轮换密钥时放入旧 key，下次保存即改用新 key 写回。
+	FallbackCodecs []CookieCodec
+	// Store 服务端存储，cookie 中只放签名后的会话 ID；nil 时会话内容整体编码进 cookie（受 4KB 限制）。
+	Store SessionStore
+	// CookieName 空走默认 "session"。
+	CookieName string
+	// MaxAge 会话有效期，每次保存后重新计算；0 走默认 24 小时。
+	MaxAge   time.Duration
+	Path     string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+type sessionKey struct{}
+
+// GetSession 取 Sessions 中间件加载的会话；未启用时返回 nil。
+func GetSession(ctx context.Context) *Session {
+	s, _ := ctx.Value(sessionKey{}).(*Session)
+	return s
+}
+
+// Sessions 基于 cookie 的会话：请求开始时经 codec 解码 cookie 加载会话，
+// 有改动时在响应头写出前保存并下发 Set-Cookie（cookie 固定 HttpOnly，SameSite 默认 Lax）。
+func Sessions(codec CookieCodec, opts ...SessionOption) web.HandlerFunc {
+	opt := SessionOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.CookieName == "" {
+		opt.CookieName = defaultSessionCookie
+	}
+	if opt.MaxAge <= 0 {
+		opt.MaxAge = defaultSessionMaxAge
+	}
+	if opt.Path == "" {
+		opt.Path = "/"
+	}
+	if opt.SameSite == 0 {
+		opt.SameSite = http.SameSiteLaxMode
+	}
+	m := &sessionManager{codecs: append([]CookieCodec{codec}, opt.FallbackCodecs...), opt: opt}
+
+	return func(c *web.Context) {
+		sess := m.load(c)
+		c.SetContextValue(sessionKey{}, sess)
+
+		original := c.Writer
+		w := newCommitWriter(original, func() { m.save(c, original, sess) })
+		c.Writer = w
+		defer func() {
+			c.Writer = original
+			w.once.Do(w.commit)
+		}()
+		c.Next()
+	}
+}
+
+type sessionManager struct {
+	codecs []CookieCodec
+	opt    SessionOption
+}
+
+func (m *sessionManager) decode(value string) ([]byte, bool) {
+	for _, codec := range m.codecs {
+		data, issued, err := codec.Decode(m.opt.CookieName, value)
+		if err != nil {
+			continue
+		}
+		return data, time.Since(issued) < m.opt.MaxAge
+	}
+	return nil, false
+}
+
+func (m *sessionManager) load(c *web.Context) *Session {
+	sess := &Session{values: map[string]json.RawMessage{}, isNew: true}
+	ck, err := c.Request.Cookie(m.opt.CookieName)
+	if err != nil {
+		sess.id = newSessionID()
+		return sess
+	}
+	payload, ok := m.decode(ck.Value)
+	if !ok {
+		sess.id = newSessionID()
+		return sess
+	}
+	data := payload
+	if m.opt.Store != nil {
+		var found bool
+		data, found, err = m.opt.Store.Load(c, string(payload))
+		if err != nil {
+			log.Printf("[web] session store error: %v", err)
+		}
+		if !found {
+			// 存储中已过期的 ID 不复用，避免会话固定
+			sess.id = newSessionID()
+			return sess
+		}
+		sess.id = string(payload)
+	}
+	var stored sessionData
+	if json.Unmarshal(data, &stored) != nil {
+		sess.id = newSessionID()
+		return sess
+	}
+	if sess.id == "" {
+		sess.id = stored.ID
+	}
+	if stored.Values != nil {
+		sess.values = stored.Values
+	}
+	sess.isNew = false
+	return sess
+}
+
+// sessionData 会话序列化格式；cookie 存储模式下 ID 也随内容保存。
+type sessionData struct {
+	ID     string                     `json:"id,omitempty"`
+	Values map[string]json.RawMessage `json:"v"`
+}
+
+func (m *sessionManager) save(ctx context.Context, w http.ResponseWriter, sess *Session) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if !sess.changed && !sess.destroyed {
+		return
+	}
+	store := m.opt.Store
+	if sess.oldID != "" && store != nil {
+		if err := store.Delete(ctx, sess.oldID); err != nil {
+			log.Printf("[web] session store error: %v", err)
+		}
+	}
+	cookie := &http.Cookie{
+		Name:     m.opt.CookieName,
+		Path:     m.opt.Path,
+		Domain:   m.opt.Domain,
+		Secure:   m.opt.Secure,
+		HttpOnly: true,
+		SameSite: m.opt.SameSite,
+	}
+	if sess.destroyed {
+		if store != nil {
+			if err := store.Delete(ctx, sess.id); err != nil {
+				log.Printf("[web] session store error: %v", err)
+			}
+		}
+		cookie.MaxAge = -1
+		http.SetCookie(w, cookie)
+		return
+	}
+
+	data := sessionData{Values: sess.values}
+	if store == nil {
+		data.ID = sess.id
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[web] session encode error: %v", err)
+		return
+	}
+	payload := raw
+	if store != nil {
+		if err := store.Save(ctx, sess.id, raw, m.opt.MaxAge); err != nil {
+			log.Printf("[web] session store error: %v", err)
+			return
+		}
+		payload = []byte(sess.id)
+	}
+	value, err := m.codecs[0].Encode(m.opt.CookieName, payload)
+	if err != nil {
+		log.Printf("[web] session encode error: %v", err)
+		return
+	}
+	if len(value) > maxCookieSize {
+		log.Printf("[web] session cookie %s is %d bytes, exceeds %d; use a SessionStore", m.opt.CookieName, len(value), maxCookieSize)
+		return
+	}
+	cookie.Value = value
+	cookie.MaxAge = int(m.opt.MaxAge / time.Second)
+	http.SetCookie(w, cookie)
+}
+
+func newSessionID() string {
+	var b [32]byte
+	_, _ = rand.Read(b[:])
+	return cookieEncoding.EncodeToString(b[:])
+}
+
+// Session 一次请求内的会话。值以 JSON 保存，读取时按目标类型解码，内存与 cookie 存储行为一致。
+// 修改在响应头写出前自动保存，之后的修改不再生效。
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	oldID     string
+	values    map[string]json.RawMessage
+	isNew     bool
+	changed   bool
+	destroyed bool
+}
+
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew 报告本次请求是否新建了会话（无 cookie、cookie 无效或已过期）。
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// Get 把 key 对应的值解码到 v，不存在或类型不符时返回 false。
+func (s *Session) Get(key string, v any) bool {
+	s.mu.Lock()
+	raw, ok := s.values[key]
+	s.mu.Unlock()
+	return ok && json.Unmarshal(raw, v) == nil
+}
+
+func (s *Session) GetString(key string) string {
+	var v string
+	s.Get(key, &v)
+	return v
+}
+
+// Set 保存任意可 JSON 序列化的值。
+func (s *Session) Set(key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = raw
+	s.changed = true
+	s.destroyed = false
+	return nil
+}
+
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.changed = true
+	}
+}
+
+// Clear 清空全部值但保留会话 ID。
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]json.RawMessage{}
+	s.changed = true
+}
+
+// Regenerate 更换会话 ID 并保留内容，登录 / 提权后调用以防会话固定；服务端存储中的旧 ID 会被删除。
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.id
+	}
+	s.id = newSessionID()
+	s.changed = true
+}
+
+// Destroy 删除会话并让浏览器清除 cookie，用于登出。
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]json.RawMessage{}
+	s.destroyed = true
+}
+
+// commitWriter 在首次写出响应头前执行一次 commit，用于补写 Set-Cookie。
+// 实现 web.ResponseWriter：Status / Size / Written 转发给链上最近的 web.ResponseWriter，
+// 使内层的 Timeout / Recovery / AccessLog 仍能读到真实的写出状态。
+type commitWriter struct {
+	http.ResponseWriter
+	rw     web.ResponseWriter
+	commit func()
+	once   sync.Once
+}
+
+var _ web.ResponseWriter = (*commitWriter)(nil)
+
+func newCommitWriter(w http.ResponseWriter, commit func()) *commitWriter {
+	cw := &commitWriter{ResponseWriter: w, commit: commit}
+	for u := w; u != nil; {
+		if rw, ok := u.(web.ResponseWriter); ok {
+			cw.rw = rw
+			break
+		}
+		uw, ok := u.(web.ResponseWriterUnwrapper)
+		if !ok {
+			break
+		}
+		u = uw.Unwrap()
+	}
+	return cw
+}
+
+func (w *commitWriter) WriteHeader(code int) {
+	w.once.Do(w.commit)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *commitWriter) Write(b []byte) (int, error) {
+	w.once.Do(w.commit)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *commitWriter) ReadFrom(src io.Reader) (int64, error) {
+	w.once.Do(w.commit)
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(struct{ io.Writer }{w.ResponseWriter}, src)
+}
+
+func (w *commitWriter) Flush() {
+	w.once.Do(w.commit)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 接管连接后不再有响应头可写，跳过 commit。
+func (w *commitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *commitWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *commitWriter) Status() int {
+	if w.rw == nil {
+		return 0
+	}
+	return w.rw.Status()
+}
+
+func (w *commitWriter) Size() int {
+	if w.rw == nil {
+		return 0
+	}
+	return w.rw.Size()
+}
+
+func (w *commitWriter) Written() bool {
+	return w.rw != nil && w.rw.Written()
+}
+
+func (w *commitWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/Rehtt/Kit/maps"
+)
+
+// SessionStore 服务端会话存储，data 为序列化后的会话内容，ttl 为空闲过期时长。
+type SessionStore interface {
+	Load(ctx context.Context, id string) (data []byte, ok bool, err error)
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// MemorySessionStore 基于 maps.ConcurrentMap 的进程内存储，条目按 TTL 自动淘汰；多实例部署需换用共享存储。
+type MemorySessionStore struct {
+	items *maps.ConcurrentMap[[]byte]
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{items: maps.NewConcurrentMap[[]byte]()}
+}
+
+func (s *MemorySessionStore) Load(_ context.Context, id string) ([]byte, bool, error) {
+	data, ok := s.items.Get(id)
+	return data, ok, nil
+}
+
+func (s *MemorySessionStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	s.items.Set(id, data, ttl)
+	return nil
+}
+
+func (s *MemorySessionStore) Delete(_ context.Context, id string) error {
+	s.items.Delete(id)
+	return nil
+}
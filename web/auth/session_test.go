@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+	"github.com/Rehtt/Kit/web/middleware"
+)
+
+var (
+	hashKey  = []byte("0123456789abcdef0123456789abcdef")
+	blockKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+// browser 在多次请求间保存 cookie。
+type browser struct {
+	g       *web.GOweb
+	cookies map[string]*http.Cookie
+}
+
+func newBrowser(g *web.GOweb) *browser {
+	return &browser{g: g, cookies: map[string]*http.Cookie{}}
+}
+
+func (b *browser) do(method, path string, body url.Values, header ...string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body != nil {
+		req = httptest.NewRequest(method, path, strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	for _, ck := range b.cookies {
+		req.AddCookie(ck)
+	}
+	rec := httptest.NewRecorder()
+	b.g.ServeHTTP(rec, req)
+	for _, ck := range rec.Result().Cookies() {
+		if ck.MaxAge < 0 {
+			delete(b.cookies, ck.Name)
+		} else {
+			b.cookies[ck.Name] = ck
+		}
+	}
+	return rec
+}
+
+func sessionEngine(codec CookieCodec, opt SessionOption) *web.GOweb {
+	g := web.New()
+	g.Middlewares(Sessions(codec, opt))
+	g.GET("/set", func(c *web.Context) {
+		sess := GetSession(c)
+		sess.Set("user", c.Request.URL.Query().Get("u"))
+		sess.Set("n", 42)
+		c.WriteString("ok")
+	})
+	g.GET("/get", func(c *web.Context) {
+		sess := GetSession(c)
+		var n int
+		sess.Get("n", &n)
+		c.WriteString(sess.GetString("user") + " " + strings.Repeat("*", n/21))
+	})
+	g.GET("/id", func(c *web.Context) { c.WriteString(GetSession(c).ID()) })
+	g.GET("/login", func(c *web.Context) {
+		sess := GetSession(c)
+		sess.Regenerate()
+		c.WriteString(sess.ID())
+	})
+	g.GET("/logout", func(c *web.Context) { GetSession(c).Destroy() })
+	return g
+}
+
+func TestSessionModes(t *testing.T) {
+	modes := map[string]struct {
+		codec CookieCodec
+		opt   SessionOption
+	}{
+		"signed cookie":    {SignedCookie(hashKey), SessionOption{}},
+		"encrypted cookie": {EncryptedCookie(blockKey), SessionOption{}},
+		"store":            {SignedCookie(hashKey), SessionOption{Store: NewMemorySessionStore()}},
+	}
+	for name, m := range modes {
+		t.Run(name, func(t *testing.T) {
+			b := newBrowser(sessionEngine(m.codec, m.opt))
+			if got := b.do("GET", "/get", nil).Body.String(); got != " " {
+				t.Fatalf("empty session = %q", got)
+			}
+			if len(b.cookies) != 0 {
+				t.Fatal("unchanged session should not set cookie")
+			}
+			rec := b.do("GET", "/set?u=alice", nil)
+			ck := b.cookies["session"]
+			if ck == nil || !ck.HttpOnly || ck.SameSite != http.SameSiteLaxMode {
+				t.Fatalf("cookie = %+v", rec.Result().Cookies())
+			}
+			if got := b.do("GET", "/get", nil).Body.String(); got != "alice **" {
+				t.Fatalf("get = %q", got)
+			}
+			if m.opt.Store != nil && strings.Contains(ck.Value, "alice") {
+				t.Fatal("store mode leaked data into cookie")
+			}
+
+			// 篡改 cookie 视为新会话
+			tampered := *ck
+			flip := "A"
+			if ck.Value[0] == 'A' {
+				flip = "B"
+			}
+			tampered.Value = flip + ck.Value[1:]
+			b.cookies["session"] = &tampered
+			if got := b.do("GET", "/get", nil).Body.String(); got != " " {
+				t.Fatalf("tampered = %q", got)
+			}
+			b.cookies["session"] = ck
+
+			before := b.do("GET", "/id", nil).Body.String()
+			after := b.do("GET", "/login", nil).Body.String()
+			if before == after || b.do("GET", "/id", nil).Body.String() != after {
+				t.Fatalf("regenerate: %q -> %q", before, after)
+			}
+			if got := b.do("GET", "/get", nil).Body.String(); got != "alice **" {
+				t.Fatalf("after regenerate = %q", got)
+			}
+			if m.opt.Store != nil {
+				// 旧 ID 的 cookie 不再有效
+				old, _ := m.codec.Encode("session", []byte(before))
+				stale := newBrowser(b.g)
+				stale.cookies["session"] = &http.Cookie{Name: "session", Value: old}
+				if got := stale.do("GET", "/get", nil).Body.String(); got != " " {
+					t.Fatalf("old id still valid: %q", got)
+				}
+			}
+
+			b.do("GET", "/logout", nil)
+			if _, ok := b.cookies["session"]; ok {
+				t.Fatal("logout did not clear cookie")
+			}
+			if got := b.do("GET", "/get", nil).Body.String(); got != " " {
+				t.Fatalf("after logout = %q", got)
+			}
+		})
+	}
+}
+
+func TestSessionKeyRotation(t *testing.T) {
+	oldCodec := SignedCookie(hashKey)
+	b := newBrowser(sessionEngine(oldCodec, SessionOption{}))
+	b.do("GET", "/set?u=bob", nil)
+
+	newCodec := EncryptedCookie(blockKey)
+	b.g = sessionEngine(newCodec, SessionOption{FallbackCodecs: []CookieCodec{oldCodec}})
+	if got := b.do("GET", "/get", nil).Body.String(); got != "bob **" {
+		t.Fatalf("fallback decode = %q", got)
+	}
+	b.do("GET", "/set?u=bob", nil)
+	if _, _, err := newCodec.Decode("session", b.cookies["session"].Value); err != nil {
+		t.Fatalf("rewritten with new key: %v", err)
+	}
+	// cookie 名参与认证，不能挪用
+	if _, _, err := newCodec.Decode("other", b.cookies["session"].Value); err == nil {
+		t.Fatal("cookie accepted under another name")
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	g := web.New()
+	g.Middlewares(Sessions(SignedCookie(hashKey)), CSRF())
+	g.GET("/form", func(c *web.Context) { c.WriteString(CSRFToken(c)) })
+	g.POST("/submit", func(c *web.Context) { c.WriteString("done") })
+	g.GET("/page", func(c *web.Context) { c.WriteString("page") })
+
+	b := newBrowser(g)
+	// 未用到 token 的请求不生成 secret，也就不下发 cookie
+	if rec := b.do("GET", "/page", nil); len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("anonymous GET set cookies: %v", rec.Result().Cookies())
+	}
+	if rec := b.do("POST", "/submit", nil, "X-CSRF-Token", "x"); rec.Code != http.StatusForbidden {
+		t.Fatalf("no secret: %d", rec.Code)
+	}
+	token := b.do("GET", "/form", nil).Body.String()
+	if token == "" || token == b.do("GET", "/form", nil).Body.String() {
+		t.Fatal("token should be non-empty and masked differently each time")
+	}
+
+	if rec := b.do("POST", "/submit", url.Values{}); rec.Code != http.StatusForbidden {
+		t.Fatalf("missing token: %d", rec.Code)
+	}
+	if rec := b.do("POST", "/submit", url.Values{"_csrf": {token}}); rec.Code != 200 || rec.Body.String() != "done" {
+		t.Fatalf("form token: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := b.do("POST", "/submit", nil, "X-CSRF-Token", token); rec.Code != 200 {
+		t.Fatalf("header token: %d", rec.Code)
+	}
+	if rec := b.do("POST", "/submit", nil, "X-CSRF-Token", token, "Origin", "https://evil.test"); rec.Code != http.StatusForbidden {
+		t.Fatalf("cross origin: %d", rec.Code)
+	}
+	if rec := b.do("POST", "/submit", nil, "X-CSRF-Token", token, "Origin", "http://example.com"); rec.Code != 200 {
+		t.Fatalf("same origin: %d", rec.Code)
+	}
+
+	other := newBrowser(g)
+	other.do("GET", "/form", nil)
+	if rec := other.do("POST", "/submit", nil, "X-CSRF-Token", token); rec.Code != http.StatusForbidden {
+		t.Fatalf("token from another session: %d", rec.Code)
+	}
+}
+
+// 内层中间件透过 Sessions 的 writer 包装仍能读到写出状态。
+func TestSessionWriterExposesStatus(t *testing.T) {
+	var buf bytes.Buffer
+	g := web.New()
+	g.Middlewares(
+		Sessions(SignedCookie(hashKey)),
+		middleware.AccessLog(middleware.AccessLogOption{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}),
+		middleware.Timeout(20*time.Millisecond),
+	)
+	g.GET("/slow", func(c *web.Context) {
+		GetSession(c).Set("user", "alice")
+		c.Writer.WriteHeader(http.StatusCreated)
+		c.WriteString("done")
+		<-c.Done()
+	})
+
+	b := newBrowser(g)
+	rec := b.do("GET", "/slow", nil)
+	if rec.Code != http.StatusCreated || rec.Body.String() != "done" {
+		t.Fatalf("timeout overwrote response: %d %q", rec.Code, rec.Body.String())
+	}
+	if b.cookies["session"] == nil {
+		t.Fatal("session cookie not committed")
+	}
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log %q: %v", buf.String(), err)
+	}
+	if entry["status"] != float64(http.StatusCreated) || entry["size"] != float64(4) {
+		t.Fatalf("entry = %v", entry)
+	}
+}
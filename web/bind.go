@@ -0,0 +1,490 @@
+package web
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 绑定来源对应的 struct tag。
+const (
+	TagPath   = "path"
+	TagQuery  = "query"
+	TagHeader = "header"
+	TagForm   = "form"
+)
+
+// DefaultMultipartMemory multipart 表单在内存中保留的最大字节数，超出部分落盘。
+var DefaultMultipartMemory int64 = 32 << 20
+
+var ErrBindTarget = errors.New("[web] bind target must be a non-nil pointer to struct")
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// Bind 依次从 body（按 Content-Type 选择编解码器）、路径参数、query、header 填充 v，最后执行 validate 校验。
+// 后填充的来源覆盖先填充的；转换或校验失败返回 ValidationErrors，body 格式不受支持返回 ErrUnsupportedMediaType（应答 415），
+// body 解码失败返回编解码器的原始错误。可交给 WriteBindError 按错误类型写出响应。
+func (c *Context) Bind(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.bindBody(v); err != nil {
+		return err
+	}
+	if err := c.bindPath(v); err != nil {
+		return err
+	}
+	if err := c.bindQuery(v); err != nil {
+		return err
+	}
+	if err := c.bindHeader(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// WriteBindError 按 Bind 系列返回的错误写出响应：ValidationErrors 为 400 + 字段级 JSON，
+// ErrUnsupportedMediaType 为 415，ErrBindTarget / ErrInvalidRule（编程错误）为 500，其余（如 body 格式错误）为 400 + {"error": "..."}。
+func (c *Context) WriteBindError(err error) error {
+	var verrs ValidationErrors
+	switch {
+	case errors.As(err, &verrs):
+		return c.WriteJSON(verrs, http.StatusBadRequest)
+	case errors.Is(err, ErrUnsupportedMediaType):
+		return c.WriteJSON(map[string]string{"error": err.Error()}, http.StatusUnsupportedMediaType)
+	case errors.Is(err, ErrBindTarget), errors.Is(err, ErrInvalidRule):
+		return c.WriteJSON(map[string]string{"error": err.Error()}, http.StatusInternalServerError)
+	}
+	return c.WriteJSON(map[string]string{"error": err.Error()}, http.StatusBadRequest)
+}
+
+// BindJSON 只解析 JSON body 并校验。
+func (c *Context) BindJSON(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.ReadJSON(v); err != nil && err != io.EOF {
+		return err
+	}
+	return Validate(v)
+}
+
+// BindPath 只绑定 `path` tag 并校验。
+func (c *Context) BindPath(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.bindPath(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// BindQuery 只绑定 `query` tag 并校验。
+func (c *Context) BindQuery(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.bindQuery(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// BindHeader 只绑定 `header` tag 并校验。
+func (c *Context) BindHeader(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.bindHeader(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// BindForm 只绑定 `form` tag（urlencoded / multipart）并校验。
+func (c *Context) BindForm(v any) error {
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	if err := c.bindForm(v); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// checkBindTarget 在绑定前确认 v 为非 nil 的 struct 指针且 validate tag 合法，避免绑定了一半才发现 tag 错误。
+func checkBindTarget(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrBindTarget
+	}
+	return CheckRules(v)
+}
+
+// bindBody 按 Content-Type 选择编解码器，表单走 `form` tag 绑定；空 body 或缺少 Content-Type 时跳过。
+// 没有对应编解码器时返回 ErrUnsupportedMediaType。
+func (c *Context) bindBody(v any) error {
+	if c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return nil
+	}
+	contentType := c.Request.Header.Get("Content-Type")
+	if contentType == "" {
+		return nil
+	}
+	ct, _, _ := mime.ParseMediaType(contentType)
+	switch ct {
+	case "application/x-www-form-urlencoded", "multipart/form-data":
+		return c.bindForm(v)
+	}
+	codec := c.engine.codecTable().lookup(contentType)
+	if codec == nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, ct)
+	}
+	defer c.Request.Body.Close()
+	if err := codec.Decode(c.Request.Body, v); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+func (c *Context) bindPath(v any) error {
+	return bindSource(v, TagPath, func(key string) []string {
+		if val, ok := c.param[key]; ok {
+			return []string{val}
+		}
+		return nil
+	}, nil)
+}
+
+func (c *Context) bindQuery(v any) error {
+	query := c.Request.URL.Query()
+	return bindSource(v, TagQuery, func(key string) []string { return query[key] }, nil)
+}
+
+func (c *Context) bindHeader(v any) error {
+	header := c.Request.Header
+	return bindSource(v, TagHeader, header.Values, nil)
+}
+
+func (c *Context) bindForm(v any) error {
+	ct, _, _ := mime.ParseMediaType(c.Request.Header.Get("Content-Type"))
+	var files map[string][]*multipart.FileHeader
+	if ct == "multipart/form-data" {
+		if err := c.Request.ParseMultipartForm(DefaultMultipartMemory); err != nil {
+			return err
+		}
+		files = c.Request.MultipartForm.File
+	} else if err := c.Request.ParseForm(); err != nil {
+		return err
+	}
+	form := c.Request.PostForm
+	return bindSource(v, TagForm, func(key string) []string { return form[key] }, files)
+}
+
+// bindSource 把 get(key) 的值写入 tag 为 tagName 的字段；嵌套 struct 以相同 key 空间递归。
+func bindSource(v any, tagName string, get func(string) []string, files map[string][]*multipart.FileHeader) error {
+	rv := reflect.ValueOf(v).Elem()
+	return bindStruct(rv, "", tagName, get, files, []reflect.Type{rv.Type()})
+}
+
+// bindStruct stack 为递归路径上的 struct 类型，自引用类型（如链表节点）不会再次进入同一类型。
+func bindStruct(rv reflect.Value, prefix, tagName string, get func(string) []string, files map[string][]*multipart.FileHeader, stack []reflect.Type) error {
+	meta := structMetaOf(rv.Type())
+	for i := range meta.fields {
+		f := &meta.fields[i]
+		fv := rv.Field(f.index)
+		key := f.tags[tagName]
+		if key == "" {
+			if !f.nested || !hasAnyKey(f.keys[tagName], get, files) {
+				continue
+			}
+			t := fv.Type()
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			if slices.Contains(stack, t) {
+				continue
+			}
+			if fv.Kind() == reflect.Pointer && fv.IsNil() {
+				// 先绑到临时值，确有字段被填充才挂上，避免凭空造出空指针对象。
+				tmp := reflect.New(t)
+				if err := bindStruct(tmp.Elem(), f.childPrefix(prefix), tagName, get, files, append(stack, t)); err != nil {
+					return err
+				}
+				if !tmp.Elem().IsZero() {
+					fv.Set(tmp)
+				}
+				continue
+			}
+			if fv.Kind() == reflect.Pointer {
+				fv = fv.Elem()
+			}
+			if err := bindStruct(fv, f.childPrefix(prefix), tagName, get, files, append(stack, t)); err != nil {
+				return err
+			}
+			continue
+		}
+		if files != nil && isFileField(fv.Type()) {
+			setFileField(fv, files[key])
+			continue
+		}
+		vals := get(key)
+		if len(vals) == 0 {
+			continue
+		}
+		if err := setField(fv, vals); err != nil {
+			return ValidationErrors{{
+				Field: prefix + f.label,
+				Tag:   "type",
+				Param: fv.Type().String(),
+				Value: vals[0],
+				Err:   err,
+			}}
+		}
+	}
+	return nil
+}
+
+// hasAnyKey 判断来源中是否存在 keys 中的任一 key。
+func hasAnyKey(keys []string, get func(string) []string, files map[string][]*multipart.FileHeader) bool {
+	for _, k := range keys {
+		if len(get(k)) > 0 || len(files[k]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isFileField(t reflect.Type) bool {
+	return t == fileHeaderType || (t.Kind() == reflect.Slice && t.Elem() == fileHeaderType)
+}
+
+func setFileField(fv reflect.Value, fhs []*multipart.FileHeader) {
+	if len(fhs) == 0 {
+		return
+	}
+	if fv.Kind() == reflect.Slice {
+		fv.Set(reflect.ValueOf(fhs))
+		return
+	}
+	fv.Set(reflect.ValueOf(fhs[0]))
+}
+
+// setField 支持标量、指针、切片以及实现 encoding.TextUnmarshaler 的类型。
+func setField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setField(fv.Elem(), vals)
+	}
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(vals[0]))
+	}
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		out := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, s := range vals {
+			if err := setField(out.Index(i), []string{s}); err != nil {
+				return err
+			}
+		}
+		fv.Set(out)
+		return nil
+	}
+	return setScalar(fv, vals[0])
+}
+
+func setScalar(fv reflect.Value, s string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	case reflect.Slice:
+		// 仅剩 []byte
+		fv.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported kind %s", fv.Kind())
+	}
+	return nil
+}
+
+// structMeta 缓存某个 struct 类型的绑定 / 校验元信息，按类型只解析一次。
+type structMeta struct {
+	fields []fieldMeta
+	// err 首个非法 validate tag，见 CheckRules
+	err error
+}
+
+type fieldMeta struct {
+	index int
+	// label 用于错误中的字段名：优先 json tag，其次各绑定 tag，最后是 Go 字段名。
+	label     string
+	tags      map[string]string
+	rules     []Rule
+	nested    bool
+	anonymous bool
+	// keys 嵌套 struct 内（含更深层）各绑定 tag 用到的 key，来源中没有这些 key 时跳过递归
+	keys map[string][]string
+}
+
+// childPrefix 返回嵌套字段的错误路径前缀；匿名嵌入的字段平铺到父级。
+func (f *fieldMeta) childPrefix(prefix string) string {
+	if f.anonymous {
+		return prefix
+	}
+	return prefix + f.label + "."
+}
+
+var structMetaCache sync.Map // reflect.Type -> *structMeta
+
+func structMetaOf(t reflect.Type) *structMeta {
+	if m, ok := structMetaCache.Load(t); ok {
+		return m.(*structMeta)
+	}
+	m := &structMeta{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		f := fieldMeta{
+			index: i,
+			label: sf.Name,
+			tags:  make(map[string]string, 4),
+		}
+		for _, name := range [...]string{TagPath, TagQuery, TagHeader, TagForm} {
+			if tag := tagName(sf.Tag.Get(name)); tag != "" {
+				f.tags[name] = tag
+			}
+		}
+		if tag := tagName(sf.Tag.Get("json")); tag != "" {
+			f.label = tag
+		} else {
+			for _, name := range [...]string{TagQuery, TagForm, TagPath, TagHeader} {
+				if tag := f.tags[name]; tag != "" {
+					f.label = tag
+					break
+				}
+			}
+		}
+		if sf.Anonymous {
+			f.label = sf.Name
+			f.anonymous = true
+		}
+		rules, err := ParseRules(t, sf)
+		if err != nil && m.err == nil {
+			m.err = err
+		}
+		f.rules = rules
+		f.nested = isNestedStruct(sf.Type)
+		if f.nested {
+			f.keys = nestedKeys(sf.Type, map[reflect.Type]bool{t: true})
+		}
+		m.fields = append(m.fields, f)
+	}
+	actual, _ := structMetaCache.LoadOrStore(t, m)
+	return actual.(*structMeta)
+}
+
+// nestedKeys 收集 struct 类型 t（或其指针）内各绑定 tag 的 key；seen 防止自引用类型无限展开。
+func nestedKeys(t reflect.Type, seen map[reflect.Type]bool) map[string][]string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	keys := make(map[string][]string)
+	if seen[t] {
+		return keys
+	}
+	seen[t] = true
+	defer delete(seen, t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		for _, name := range [...]string{TagPath, TagQuery, TagHeader, TagForm} {
+			if tag := tagName(sf.Tag.Get(name)); tag != "" && !slices.Contains(keys[name], tag) {
+				keys[name] = append(keys[name], tag)
+			}
+		}
+		if isNestedStruct(sf.Type) {
+			for name, sub := range nestedKeys(sf.Type, seen) {
+				for _, k := range sub {
+					if !slices.Contains(keys[name], k) {
+						keys[name] = append(keys[name], k)
+					}
+				}
+			}
+		}
+	}
+	return keys
+}
+
+func tagName(tag string) string {
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == ',' {
+			tag = tag[:i]
+			break
+		}
+	}
+	if tag == "-" {
+		return ""
+	}
+	return tag
+}
+
+// isNestedStruct 判断字段是否需要递归：struct（或其指针），但排除 time.Time 这类自解析类型。
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
@@ -0,0 +1,276 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=6"`
+}
+
+type bindUser struct {
+	ID      int           `path:"id" validate:"min=1"`
+	Name    string        `json:"name" validate:"required,min=2,max=8"`
+	Role    string        `json:"role" query:"role" validate:"oneof=admin user"`
+	Tags    []string      `query:"tag"`
+	Token   string        `header:"X-Token"`
+	Timeout time.Duration `query:"timeout"`
+	Page    *int          `query:"page"`
+	Address bindAddress   `json:"address"`
+	Items   []bindAddress `json:"items"`
+}
+
+func TestBindAllSources(t *testing.T) {
+	g := New()
+	var got bindUser
+	var bindErr error
+	g.POST("/users/#id", func(ctx *Context) {
+		bindErr = ctx.Bind(&got)
+	})
+
+	body := `{"name":"alice","role":"user","address":{"city":"sh"}}`
+	req := httptest.NewRequest("POST", "/users/42?role=admin&tag=a&tag=b&timeout=3s&page=2", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Token", "tk")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	if bindErr != nil {
+		t.Fatalf("Bind() error = %v", bindErr)
+	}
+	if got.ID != 42 || got.Name != "alice" || got.Token != "tk" || got.Timeout != 3*time.Second {
+		t.Fatalf("got %+v", got)
+	}
+	// query 后于 body 绑定，应覆盖 body 中的 role。
+	if got.Role != "admin" {
+		t.Fatalf("Role = %q, want admin", got.Role)
+	}
+	if !equalStrings(got.Tags, []string{"a", "b"}) {
+		t.Fatalf("Tags = %v", got.Tags)
+	}
+	if got.Page == nil || *got.Page != 2 {
+		t.Fatalf("Page = %v", got.Page)
+	}
+	if got.Address.City != "sh" {
+		t.Fatalf("Address = %+v", got.Address)
+	}
+}
+
+func TestBindValidationErrors(t *testing.T) {
+	g := New()
+	var bindErr error
+	g.POST("/users/#id", func(ctx *Context) {
+		var u bindUser
+		bindErr = ctx.Bind(&u)
+	})
+
+	body := `{"name":"a","role":"root","address":{"zip":"123"},"items":[{"city":"x"},{}]}`
+	req := httptest.NewRequest("POST", "/users/0", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+
+	var verrs ValidationErrors
+	if !errors.As(bindErr, &verrs) {
+		t.Fatalf("Bind() error = %v, want ValidationErrors", bindErr)
+	}
+	got := make([]string, len(verrs))
+	for i, e := range verrs {
+		got[i] = e.Field + ":" + e.Tag
+	}
+	want := []string{"id:min", "name:min", "role:oneof", "address.city:required", "address.zip:len", "items[1].city:required"}
+	if !equalStrings(got, want) {
+		t.Fatalf("got %v\nwant %v", got, want)
+	}
+}
+
+func TestWriteBindError(t *testing.T) {
+	g := New()
+	g.POST("/users/#id", func(ctx *Context) {
+		var u bindUser
+		if err := ctx.Bind(&u); err != nil {
+			ctx.WriteBindError(err)
+		}
+	})
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		code        int
+		contains    string
+	}{
+		{"validation", "application/json", `{"name":"a"}`, http.StatusBadRequest, `"tag":"min"`},
+		{"unsupported media type", "text/csv", "a,b", http.StatusUnsupportedMediaType, "unsupported media type"},
+		{"malformed body", "application/json", `{"name":`, http.StatusBadRequest, `"error"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/users/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			g.ServeHTTP(rec, req)
+			if rec.Code != tt.code || !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Fatalf("code=%d body=%q", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBindTypeError(t *testing.T) {
+	g := New()
+	var bindErr error
+	g.GET("/users/#id", func(ctx *Context) {
+		var u struct {
+			ID int `path:"id"`
+		}
+		bindErr = ctx.BindPath(&u)
+	})
+	runRequest(t, g, "GET", "/users/abc")
+
+	var verrs ValidationErrors
+	if !errors.As(bindErr, &verrs) || verrs[0].Tag != "type" || verrs[0].Field != "id" {
+		t.Fatalf("BindPath() error = %v", bindErr)
+	}
+}
+
+func TestBindForm(t *testing.T) {
+	type form struct {
+		Name string                `form:"name" validate:"required"`
+		Age  uint8                 `form:"age" validate:"max=150"`
+		File *multipart.FileHeader `form:"file"`
+	}
+
+	g := New()
+	var got form
+	var bindErr error
+	g.POST("/f", func(ctx *Context) { bindErr = ctx.Bind(&got) })
+
+	values := url.Values{"name": {"bob"}, "age": {"30"}}
+	req := httptest.NewRequest("POST", "/f", strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil || got.Name != "bob" || got.Age != 30 {
+		t.Fatalf("urlencoded: err=%v got=%+v", bindErr, got)
+	}
+
+	got = form{}
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("name", "carol")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	fw.Write([]byte("hello"))
+	mw.Close()
+	req = httptest.NewRequest("POST", "/f", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	g.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil || got.Name != "carol" || got.File == nil || got.File.Filename != "a.txt" {
+		t.Fatalf("multipart: err=%v got=%+v", bindErr, got)
+	}
+}
+
+type bindNode struct {
+	Name string `query:"name"`
+	Next *bindNode
+}
+
+type bindPaging struct {
+	Page *struct {
+		Size int `query:"size"`
+	}
+}
+
+func TestBindNestedPointers(t *testing.T) {
+	g := New()
+	var node bindNode
+	var paging bindPaging
+	done := make(chan error, 1)
+	g.GET("/", func(ctx *Context) {
+		if err := ctx.BindQuery(&node); err != nil {
+			done <- err
+			return
+		}
+		done <- ctx.BindQuery(&paging)
+	})
+	go g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/?name=a", nil))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("BindQuery on self-referential type did not return")
+	}
+	if node.Name != "a" || node.Next != nil {
+		t.Fatalf("node = %+v", node)
+	}
+	// 来源中没有嵌套字段的 key 时不分配
+	if paging.Page != nil {
+		t.Fatalf("paging = %+v", paging.Page)
+	}
+}
+
+func TestBindRejectsNonPointer(t *testing.T) {
+	ctx := &Context{Request: httptest.NewRequest(http.MethodGet, "/", nil)}
+	if err := ctx.Bind(bindUser{}); !errors.Is(err, ErrBindTarget) {
+		t.Fatalf("Bind() error = %v, want ErrBindTarget", err)
+	}
+}
+
+func TestValidateRegex(t *testing.T) {
+	type v struct {
+		Code string `validate:"required,regex=^[a-z]{2,3}$"`
+	}
+	if err := Validate(&v{Code: "ab"}); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := Validate(v{Code: "abcd"}); err == nil {
+		t.Fatal("Validate() expected regex failure")
+	}
+}
+
+func TestInvalidValidateTag(t *testing.T) {
+	type inner struct {
+		Y string `validate:"regex=("`
+	}
+	type v struct {
+		X     int `validate:"bogus"`
+		Inner inner
+	}
+	type outer struct {
+		Items []inner `json:"items"`
+	}
+	for _, target := range []any{v{}, &outer{}} {
+		if err := Validate(target); !errors.Is(err, ErrInvalidRule) {
+			t.Fatalf("Validate(%T) = %v, want ErrInvalidRule", target, err)
+		}
+	}
+
+	// Bind 返回错误而不是在请求中 panic
+	g := New()
+	g.POST("/x", func(ctx *Context) {
+		var o outer
+		ctx.WriteBindError(ctx.Bind(&o))
+	})
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("POST", "/x", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "inner.Y") {
+		t.Fatalf("code=%d body=%q", rec.Code, rec.Body.String())
+	}
+
+	// 声明了 Request 的路由在注册期即发现
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "v.X") {
+			t.Fatalf("recover = %v, want panic naming v.X", r)
+		}
+	}()
+	g.POST("/y", func(ctx *Context) {}, HandlerOpt{Request: v{}})
+}
@@ -0,0 +1,429 @@
+package web
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrNotAcceptable Accept 中没有任何已注册编解码器可满足，Render 已写出 406。
+	ErrNotAcceptable = errors.New("[web] not acceptable")
+	// ErrUnsupportedMediaType 请求 Content-Type 没有对应的编解码器，调用方应返回 415（WriteBindError 会处理）。
+	ErrUnsupportedMediaType = errors.New("[web] unsupported media type")
+)
+
+// Codec 一种 body 格式的编解码器。
+// 若同时实现 Supports(v any) bool，协商时会跳过不能编码 v 的编解码器。
+type Codec interface {
+	// MediaTypes 支持的媒体类型，首个用作响应 Content-Type。
+	MediaTypes() []string
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+// 内置编解码器，New 时按此顺序注册；Accept 缺失或为 */* 时优先第一个。
+var (
+	JSONCodec     Codec = jsonCodec{}
+	XMLCodec      Codec = xmlCodec{}
+	YAMLCodec     Codec = yamlCodec{}
+	MsgPackCodec  Codec = msgpackCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+	FormCodec     Codec = formCodec{}
+)
+
+var defaultCodecs = newCodecTable(nil, JSONCodec, XMLCodec, YAMLCodec, MsgPackCodec, ProtobufCodec, FormCodec)
+
+// codecTable 注册后只读，写时复制整体替换。
+type codecTable struct {
+	codecs  []Codec
+	byMedia map[string]Codec
+}
+
+func newCodecTable(base *codecTable, codecs ...Codec) *codecTable {
+	t := &codecTable{byMedia: map[string]Codec{}}
+	if base != nil {
+		t.codecs = append(t.codecs, base.codecs...)
+	}
+	for _, c := range codecs {
+		// 与已注册编解码器共享媒体类型时原位替换，保持其协商优先级
+		at := -1
+		kept := t.codecs[:0]
+		for _, old := range t.codecs {
+			if sharesMediaType(old, c) {
+				if at < 0 {
+					at = len(kept)
+					kept = append(kept, c)
+				}
+				continue
+			}
+			kept = append(kept, old)
+		}
+		if at < 0 {
+			kept = append(kept, c)
+		}
+		t.codecs = kept
+	}
+	for _, c := range t.codecs {
+		for _, m := range c.MediaTypes() {
+			t.byMedia[strings.ToLower(m)] = c
+		}
+	}
+	return t
+}
+
+func sharesMediaType(a, b Codec) bool {
+	for _, x := range a.MediaTypes() {
+		for _, y := range b.MediaTypes() {
+			if strings.EqualFold(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RegisterCodec 注册编解码器；与已有编解码器媒体类型重叠时替换之并沿用其优先级，否则追加到末尾。
+func (g *GOweb) RegisterCodec(codecs ...Codec) {
+	g.codecMu.Lock()
+	defer g.codecMu.Unlock()
+	g.codecs.Store(newCodecTable(g.codecTable(), codecs...))
+}
+
+// Codec 返回媒体类型对应的编解码器（忽略参数与大小写），未注册返回 nil。
+func (g *GOweb) Codec(mediaType string) Codec {
+	return g.codecTable().lookup(mediaType)
+}
+
+func (g *GOweb) codecTable() *codecTable {
+	if g != nil {
+		if t := g.codecs.Load(); t != nil {
+			return t
+		}
+	}
+	return defaultCodecs
+}
+
+func (t *codecTable) lookup(contentType string) Codec {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	return t.byMedia[mt]
+}
+
+// negotiate 按 Accept 的 q 值与具体程度选出编解码器与响应媒体类型；q 相同时取注册顺序靠前者。
+func (t *codecTable) negotiate(accept string, v any) (Codec, string) {
+	ranges := parseAccept(accept)
+	var (
+		best     Codec
+		bestType string
+		bestQ    float64
+	)
+	for _, c := range t.codecs {
+		if s, ok := c.(interface{ Supports(v any) bool }); ok && !s.Supports(v) {
+			continue
+		}
+		for _, mt := range c.MediaTypes() {
+			q := acceptQuality(ranges, mt)
+			if q > bestQ {
+				best, bestType, bestQ = c, mt, q
+			}
+		}
+	}
+	return best, bestType
+}
+
+type acceptRange struct {
+	typ, sub string
+	q        float64
+}
+
+// parseAccept 解析 Accept；缺失时等价于 */*。
+func parseAccept(header string) []acceptRange {
+	if strings.TrimSpace(header) == "" {
+		return []acceptRange{{typ: "*", sub: "*", q: 1}}
+	}
+	var out []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, sub, _ := strings.Cut(mt, "/")
+		r := acceptRange{typ: typ, sub: sub, q: 1}
+		if q, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(q, 64); err == nil {
+				r.q = f
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// acceptQuality 取与 mediaType 匹配的最具体范围的 q 值。
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	typ, sub, _ := strings.Cut(strings.ToLower(mediaType), "/")
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.sub == sub:
+			s = 2
+		case r.typ == typ && r.sub == "*":
+			s = 1
+		case r.typ == "*" && r.sub == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// Render 按请求 Accept 选择编解码器写出 v，并追加 Vary: Accept。
+// 没有可接受的格式时写出 406 并返回 ErrNotAcceptable。
+func (c *Context) Render(v any, statusCode ...int) error {
+	h := c.Writer.Header()
+	h.Add("Vary", "Accept")
+	codec, mediaType := c.engine.codecTable().negotiate(c.Request.Header.Get("Accept"), v)
+	if codec == nil {
+		http.Error(c.Writer, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return ErrNotAcceptable
+	}
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "json") ||
+		strings.HasSuffix(mediaType, "xml") || strings.HasSuffix(mediaType, "yaml") {
+		mediaType += "; charset=utf-8"
+	}
+	h.Set("Content-Type", mediaType)
+	if len(statusCode) != 0 {
+		c.Writer.WriteHeader(statusCode[0])
+	}
+	return codec.Encode(c.Writer, v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaTypes() []string            { return []string{"application/json"} }
+func (jsonCodec) Encode(w io.Writer, v any) error { return jsonEncode(w, v) }
+func (jsonCodec) Decode(r io.Reader, v any) error { return jsonDecode(r, v) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) MediaTypes() []string { return []string{"application/xml", "text/xml"} }
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+func (xmlCodec) Decode(r io.Reader, v any) error { return xml.NewDecoder(r).Decode(v) }
+
+type yamlCodec struct{}
+
+func (yamlCodec) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+}
+func (yamlCodec) Encode(w io.Writer, v any) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+func (yamlCodec) Decode(r io.Reader, v any) error { return yaml.NewDecoder(r).Decode(v) }
+
+// protobufCodec 不依赖 protobuf 运行时：要求值实现 gogo / vtprotobuf 风格的
+// Marshal() ([]byte, error) 与 Unmarshal([]byte) error。使用 google.golang.org/protobuf 时可注册自定义 Codec。
+type protobufCodec struct{}
+
+type protoMarshaler interface{ Marshal() ([]byte, error) }
+type protoUnmarshaler interface{ Unmarshal([]byte) error }
+
+func (protobufCodec) MediaTypes() []string {
+	return []string{"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf"}
+}
+func (protobufCodec) Supports(v any) bool {
+	_, ok := v.(protoMarshaler)
+	return ok
+}
+func (protobufCodec) Encode(w io.Writer, v any) error {
+	m, ok := v.(protoMarshaler)
+	if !ok {
+		return fmt.Errorf("[web] protobuf: %T does not implement Marshal() ([]byte, error)", v)
+	}
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+func (protobufCodec) Decode(r io.Reader, v any) error {
+	m, ok := v.(protoUnmarshaler)
+	if !ok {
+		return fmt.Errorf("[web] protobuf: %T does not implement Unmarshal([]byte) error", v)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return m.Unmarshal(data)
+}
+
+// formCodec 编解码 application/x-www-form-urlencoded：
+// 支持 url.Values、map[string][]string、map[string]string 与带 `form` tag 的 struct。
+type formCodec struct{}
+
+func (formCodec) MediaTypes() []string { return []string{"application/x-www-form-urlencoded"} }
+func (formCodec) Supports(v any) bool {
+	switch v.(type) {
+	case url.Values, map[string][]string, map[string]string:
+		return true
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	return rv.Kind() == reflect.Struct
+}
+func (formCodec) Encode(w io.Writer, v any) error {
+	values, err := formValues(v)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, values.Encode())
+	return err
+}
+func (formCodec) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+	switch p := v.(type) {
+	case *url.Values:
+		*p = values
+		return nil
+	case *map[string][]string:
+		*p = values
+		return nil
+	}
+	if err := checkBindTarget(v); err != nil {
+		return err
+	}
+	return bindSource(v, TagForm, func(key string) []string { return values[key] }, nil)
+}
+
+func formValues(v any) (url.Values, error) {
+	switch m := v.(type) {
+	case url.Values:
+		return m, nil
+	case map[string][]string:
+		return m, nil
+	case map[string]string:
+		values := make(url.Values, len(m))
+		for k, s := range m {
+			values.Set(k, s)
+		}
+		return values, nil
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("[web] form: unsupported type %T", v)
+	}
+	values := url.Values{}
+	if err := encodeFormStruct(rv, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeFormStruct(rv reflect.Value, values url.Values) error {
+	meta := structMetaOf(rv.Type())
+	for i := range meta.fields {
+		f := &meta.fields[i]
+		fv := rv.Field(f.index)
+		key := f.tags[TagForm]
+		if key == "" {
+			if f.nested {
+				if fv.Kind() == reflect.Pointer {
+					if fv.IsNil() {
+						continue
+					}
+					fv = fv.Elem()
+				}
+				if err := encodeFormStruct(fv, values); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		vals, err := formatField(fv)
+		if err != nil {
+			return fmt.Errorf("[web] form: field %s: %w", f.label, err)
+		}
+		if len(vals) > 0 {
+			values[key] = vals
+		}
+	}
+	return nil
+}
+
+// formatField 是 setField 的逆操作；nil 指针不输出。
+func formatField(fv reflect.Value) ([]string, error) {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return nil, nil
+		}
+		fv = fv.Elem()
+	}
+	if tm, ok := fv.Interface().(interface{ MarshalText() ([]byte, error) }); ok {
+		b, err := tm.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return []string{string(b)}, nil
+	}
+	if fv.Type() == durationType {
+		return []string{fv.Interface().(fmt.Stringer).String()}, nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return []string{fv.String()}, nil
+	case reflect.Bool:
+		return []string{strconv.FormatBool(fv.Bool())}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return []string{strconv.FormatInt(fv.Int(), 10)}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return []string{strconv.FormatUint(fv.Uint(), 10)}, nil
+	case reflect.Float32, reflect.Float64:
+		return []string{strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits())}, nil
+	case reflect.Slice, reflect.Array:
+		if fv.Type().Elem().Kind() == reflect.Uint8 && fv.Kind() == reflect.Slice {
+			return []string{string(fv.Bytes())}, nil
+		}
+		out := make([]string, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			s, err := formatField(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s...)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %s", fv.Kind())
+}
@@ -0,0 +1,924 @@
+package web
+
+import (
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// msgpackCodec 基于反射的 MessagePack 编解码，无第三方依赖。
+//
+// struct 字段名依次取 `msgpack`、`json` tag 与字段名，支持 omitempty 与 "-"，匿名嵌入字段平铺；
+// time.Time 使用 timestamp 扩展（-1）；实现 encoding.TextMarshaler 的类型编码为字符串。
+// 解码到 any 时整数为 int64（超出范围的无符号数为 uint64）、浮点为 float64、map 为 map[string]any。
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	e := mpEncoder{buf: make([]byte, 0, 256)}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf)
+	return err
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("[web] msgpack: decode target must be a non-nil pointer, got %T", v)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return io.EOF
+	}
+	d := mpDecoder{data: data}
+	return d.decode(rv.Elem())
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errMsgpackTruncated = fmt.Errorf("[web] msgpack: %w", io.ErrUnexpectedEOF)
+	errMsgpackTooDeep   = errors.New("[web] msgpack: exceeded max depth")
+)
+
+const mpExtTimestamp = -1
+
+// mpMaxDepth 数组、map 的最大嵌套深度，与 encoding/json 一致，防止恶意输入耗尽栈。
+const mpMaxDepth = 10000
+
+type mpEncoder struct {
+	buf []byte
+}
+
+func (e *mpEncoder) encode(rv reflect.Value) error {
+	if !rv.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encode(rv.Elem())
+	}
+	if rv.Type() == timeType {
+		e.encodeTime(rv.Interface().(time.Time))
+		return nil
+	}
+	if rv.Type().Implements(textMarshalerType) {
+		b, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+		e.encodeString(string(b))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if rv.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(rv.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(rv.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(rv.Float()))
+	case reflect.String:
+		e.encodeString(rv.String())
+	case reflect.Slice:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			e.encodeBytes(rv.Bytes())
+			return nil
+		}
+		return e.encodeArray(rv)
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			e.encodeBytes(b)
+			return nil
+		}
+		return e.encodeArray(rv)
+	case reflect.Map:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+		return e.encodeMap(rv)
+	case reflect.Struct:
+		return e.encodeStruct(rv)
+	default:
+		return fmt.Errorf("[web] msgpack: unsupported type %s", rv.Type())
+	}
+	return nil
+}
+
+func (e *mpEncoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(n))
+	}
+}
+
+func (e *mpEncoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+func (e *mpEncoder) encodeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, s...)
+}
+
+func (e *mpEncoder) encodeBytes(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+	e.buf = append(e.buf, b...)
+}
+
+func (e *mpEncoder) encodeArrayLen(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xdc)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdd)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *mpEncoder) encodeMapLen(n int) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xde)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdf)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *mpEncoder) encodeArray(rv reflect.Value) error {
+	e.encodeArrayLen(rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		if err := e.encode(rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeMap 字符串 key 按字典序输出，保证编码结果稳定。
+func (e *mpEncoder) encodeMap(rv reflect.Value) error {
+	keys := rv.MapKeys()
+	if rv.Type().Key().Kind() == reflect.String {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	}
+	e.encodeMapLen(len(keys))
+	for _, k := range keys {
+		if err := e.encode(k); err != nil {
+			return err
+		}
+		if err := e.encode(rv.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *mpEncoder) encodeStruct(rv reflect.Value) error {
+	fields := mpFieldsOf(rv.Type())
+	values := make([]reflect.Value, len(fields))
+	n := 0
+	for i, f := range fields {
+		fv, err := rv.FieldByIndexErr(f.index)
+		if err != nil || (f.omitEmpty && mpIsEmpty(fv)) {
+			continue
+		}
+		values[i] = fv
+		n++
+	}
+	e.encodeMapLen(n)
+	for i, f := range fields {
+		if !values[i].IsValid() {
+			continue
+		}
+		e.encodeString(f.name)
+		if err := e.encode(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *mpEncoder) encodeTime(t time.Time) {
+	sec, nsec := t.Unix(), uint64(t.Nanosecond())
+	switch {
+	case sec>>34 == 0 && nsec == 0 && sec <= math.MaxUint32:
+		e.buf = append(e.buf, 0xd6, 0xff)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(sec))
+	case sec>>34 == 0:
+		e.buf = append(e.buf, 0xd7, 0xff)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, nsec<<34|uint64(sec))
+	default:
+		e.buf = append(e.buf, 0xc7, 12, 0xff)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(nsec))
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(sec))
+	}
+}
+
+func mpIsEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+type mpField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var mpFieldsCache sync.Map // reflect.Type -> []mpField
+
+// mpFieldsOf 列出可编解码的字段；匿名嵌入 struct 的字段平铺，同名时外层优先。
+func mpFieldsOf(t reflect.Type) []mpField {
+	if f, ok := mpFieldsCache.Load(t); ok {
+		return f.([]mpField)
+	}
+	var direct, promoted []mpField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("msgpack")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if !sf.IsExported() && ft.Kind() == reflect.Pointer {
+				// 无法为未导出的嵌入指针分配对象
+				continue
+			}
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, f := range mpFieldsOf(ft) {
+					f.index = append([]int{i}, f.index...)
+					promoted = append(promoted, f)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		direct = append(direct, mpField{
+			name:      name,
+			index:     []int{i},
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	seen := make(map[string]bool, len(direct))
+	for _, f := range direct {
+		seen[f.name] = true
+	}
+	for _, f := range promoted {
+		if !seen[f.name] {
+			seen[f.name] = true
+			direct = append(direct, f)
+		}
+	}
+	actual, _ := mpFieldsCache.LoadOrStore(t, direct)
+	return actual.([]mpField)
+}
+
+type mpDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+// enter 进入一层数组或 map，返回时需调用 d.leave。
+func (d *mpDecoder) enter() error {
+	d.depth++
+	if d.depth > mpMaxDepth {
+		return errMsgpackTooDeep
+	}
+	return nil
+}
+
+func (d *mpDecoder) leave() {
+	d.depth--
+}
+
+func (d *mpDecoder) byte() (byte, error) {
+	if d.pos >= len(d.data) {
+		return 0, errMsgpackTruncated
+	}
+	b := d.data[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *mpDecoder) take(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, errMsgpackTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *mpDecoder) uint(size int) (uint64, error) {
+	b, err := d.take(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// length 读取 size 字节的长度并检查剩余数据至少还有 n*minElem 字节，防止伪造长度导致超大分配。
+func (d *mpDecoder) length(size, minElem int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos)/uint64(minElem) {
+		return 0, errMsgpackTruncated
+	}
+	return int(n), nil
+}
+
+func (d *mpDecoder) arrayLen() (int, error) {
+	c, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case c&0xf0 == 0x90:
+		return int(c & 0x0f), nil
+	case c == 0xdc:
+		return d.length(2, 1)
+	case c == 0xdd:
+		return d.length(4, 1)
+	}
+	return 0, fmt.Errorf("[web] msgpack: expected array, got 0x%02x", c)
+}
+
+func (d *mpDecoder) mapLen() (int, error) {
+	c, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case c&0xf0 == 0x80:
+		return int(c & 0x0f), nil
+	case c == 0xde:
+		return d.length(2, 2)
+	case c == 0xdf:
+		return d.length(4, 2)
+	}
+	return 0, fmt.Errorf("[web] msgpack: expected map, got 0x%02x", c)
+}
+
+func (d *mpDecoder) decode(rv reflect.Value) error {
+	if d.pos >= len(d.data) {
+		return errMsgpackTruncated
+	}
+	if d.data[d.pos] == 0xc0 {
+		d.pos++
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return d.decode(rv.Elem())
+	case reflect.Interface:
+		if rv.NumMethod() != 0 {
+			return fmt.Errorf("[web] msgpack: cannot decode into non-empty interface %s", rv.Type())
+		}
+		v, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			rv.Set(reflect.ValueOf(v))
+		}
+		return nil
+	}
+
+	if rv.Type() != timeType && rv.CanAddr() && rv.Addr().Type().Implements(textUnmarshalerType) {
+		v, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("[web] msgpack: cannot decode %T into %s", v, rv.Type())
+		}
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			break
+		}
+		return d.decodeStruct(rv)
+	case reflect.Map:
+		return d.decodeMap(rv)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		n, err := d.arrayLen()
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(rv.Type(), n, n)
+		for i := 0; i < n; i++ {
+			if err := d.decode(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+		return nil
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		n, err := d.arrayLen()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if i < rv.Len() {
+				if err := d.decode(rv.Index(i)); err != nil {
+					return err
+				}
+			} else if _, err := d.decodeAny(); err != nil {
+				return err
+			}
+		}
+		for i := n; i < rv.Len(); i++ {
+			rv.Index(i).Set(reflect.Zero(rv.Type().Elem()))
+		}
+		return nil
+	}
+
+	v, err := d.decodeAny()
+	if err != nil {
+		return err
+	}
+	return mpAssign(rv, v)
+}
+
+func (d *mpDecoder) decodeStruct(rv reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	n, err := d.mapLen()
+	if err != nil {
+		return err
+	}
+	fields := mpFieldsOf(rv.Type())
+	for i := 0; i < n; i++ {
+		k, err := d.decodeAny()
+		if err != nil {
+			return err
+		}
+		key, _ := k.(string)
+		f := mpLookupField(fields, key)
+		if f == nil {
+			if _, err := d.decodeAny(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decode(mpFieldAlloc(rv, f.index)); err != nil {
+			return fmt.Errorf("%w (field %s)", err, f.name)
+		}
+	}
+	return nil
+}
+
+// mpLookupField 先精确匹配字段名，再大小写不敏感匹配。
+func mpLookupField(fields []mpField, key string) *mpField {
+	for i := range fields {
+		if fields[i].name == key {
+			return &fields[i]
+		}
+	}
+	for i := range fields {
+		if strings.EqualFold(fields[i].name, key) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// mpFieldAlloc 沿 index 取字段，途经的 nil 嵌入指针按需分配。
+func mpFieldAlloc(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
+
+func (d *mpDecoder) decodeMap(rv reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	n, err := d.mapLen()
+	if err != nil {
+		return err
+	}
+	t := rv.Type()
+	if rv.IsNil() {
+		rv.Set(reflect.MakeMapWithSize(t, n))
+	}
+	for i := 0; i < n; i++ {
+		k := reflect.New(t.Key()).Elem()
+		if err := d.decode(k); err != nil {
+			return err
+		}
+		v := reflect.New(t.Elem()).Elem()
+		if err := d.decode(v); err != nil {
+			return err
+		}
+		rv.SetMapIndex(k, v)
+	}
+	return nil
+}
+
+func (d *mpDecoder) decodeAny() (any, error) {
+	c, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xf0 == 0x80:
+		return d.mapAny(int(c & 0x0f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1<<(c-0xc4), 1)
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(1<<(c-0xc7), 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		n, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := d.uint(8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		n, err := d.uint(1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := d.uint(2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := d.uint(4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := d.uint(8)
+		return int64(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1<<(c-0xd9), 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2<<(c-0xdc), 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.length(2<<(c-0xde), 2)
+		if err != nil {
+			return nil, err
+		}
+		return d.mapAny(n)
+	}
+	return nil, fmt.Errorf("[web] msgpack: invalid code 0x%02x", c)
+}
+
+func (d *mpDecoder) str(n int) (any, error) {
+	b, err := d.take(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (d *mpDecoder) array(n int) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+	out := make([]any, n)
+	for i := range out {
+		v, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// mapAny 非字符串 key 以 fmt.Sprint 转为字符串。
+func (d *mpDecoder) mapAny(n int) (any, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+	out := make(map[string]any, n)
+	for i := 0; i < n; i++ {
+		k, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.decodeAny()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// ext 仅支持 timestamp 扩展。
+func (d *mpDecoder) ext(n int) (any, error) {
+	typ, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.take(n)
+	if err != nil {
+		return nil, err
+	}
+	if int8(typ) != mpExtTimestamp {
+		return nil, fmt.Errorf("[web] msgpack: unsupported ext type %d", int8(typ))
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))), nil
+	}
+	return nil, errors.New("[web] msgpack: invalid timestamp length")
+}
+
+// mpAssign 将 decodeAny 的结果写入标量字段，数值跨类型转换时检查溢出。
+func mpAssign(rv reflect.Value, v any) error {
+	mismatch := func() error {
+		return fmt.Errorf("[web] msgpack: cannot decode %T into %s", v, rv.Type())
+	}
+	switch rv.Kind() {
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := v.(int64)
+		if !ok {
+			return mismatch()
+		}
+		if rv.OverflowInt(n) {
+			return fmt.Errorf("[web] msgpack: %d overflows %s", n, rv.Type())
+		}
+		rv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		switch x := v.(type) {
+		case int64:
+			if x < 0 {
+				return fmt.Errorf("[web] msgpack: %d overflows %s", x, rv.Type())
+			}
+			n = uint64(x)
+		case uint64:
+			n = x
+		default:
+			return mismatch()
+		}
+		if rv.OverflowUint(n) {
+			return fmt.Errorf("[web] msgpack: %d overflows %s", n, rv.Type())
+		}
+		rv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		switch x := v.(type) {
+		case float64:
+			rv.SetFloat(x)
+		case int64:
+			rv.SetFloat(float64(x))
+		case uint64:
+			rv.SetFloat(float64(x))
+		default:
+			return mismatch()
+		}
+	case reflect.String:
+		switch x := v.(type) {
+		case string:
+			rv.SetString(x)
+		case []byte:
+			rv.SetString(string(x))
+		default:
+			return mismatch()
+		}
+	case reflect.Slice:
+		switch x := v.(type) {
+		case []byte:
+			rv.SetBytes(x)
+		case string:
+			rv.SetBytes([]byte(x))
+		default:
+			return mismatch()
+		}
+	case reflect.Array:
+		b, ok := v.([]byte)
+		if !ok {
+			return mismatch()
+		}
+		reflect.Copy(rv, reflect.ValueOf(b))
+	case reflect.Struct:
+		t, ok := v.(time.Time)
+		if !ok || rv.Type() != timeType {
+			return mismatch()
+		}
+		rv.Set(reflect.ValueOf(t))
+	default:
+		return mismatch()
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package web
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type codecItem struct {
+	Name  string    `json:"name" xml:"name" yaml:"name" form:"name"`
+	Count int       `json:"count" xml:"count" yaml:"count" form:"count"`
+	Tags  []string  `json:"tags,omitempty" xml:"tag" yaml:"tags,omitempty" form:"tag"`
+	At    time.Time `json:"at" xml:"-" yaml:"-" form:"-"`
+}
+
+func renderWith(t *testing.T, g *GOweb, accept string, v any) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	var renderErr error
+	g.GET("/render", func(c *Context) { renderErr = c.Render(v) })
+	req := httptest.NewRequest("GET", "/render", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec, renderErr
+}
+
+func TestRenderNegotiation(t *testing.T) {
+	item := codecItem{Name: "a", Count: 2}
+	tests := []struct {
+		accept string
+		ctype  string
+		body   string
+	}{
+		{"", "application/json; charset=utf-8", `"name":"a"`},
+		{"*/*", "application/json; charset=utf-8", `"name":"a"`},
+		{"text/html, application/xml;q=0.9, */*;q=0.1", "application/xml; charset=utf-8", "<name>a</name>"},
+		{"application/yaml", "application/yaml; charset=utf-8", "name: a"},
+		{"text/*", "text/xml; charset=utf-8", "<name>a</name>"},
+		{"application/json;q=0.5, application/msgpack", "application/msgpack", "\xa4name\xa1a"},
+		// 普通 struct 不满足 protobuf 编解码器，回落到次优选项
+		{"application/x-protobuf, application/json;q=0.1", "application/json; charset=utf-8", `"count":2`},
+	}
+	for _, tt := range tests {
+		rec, err := renderWith(t, New(), tt.accept, item)
+		if err != nil {
+			t.Fatalf("Accept %q: Render() error = %v", tt.accept, err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != tt.ctype {
+			t.Fatalf("Accept %q: Content-Type = %q, want %q", tt.accept, ct, tt.ctype)
+		}
+		if !strings.Contains(rec.Body.String(), tt.body) {
+			t.Fatalf("Accept %q: body = %q, want contains %q", tt.accept, rec.Body.String(), tt.body)
+		}
+		if rec.Header().Get("Vary") != "Accept" {
+			t.Fatalf("Accept %q: Vary = %q", tt.accept, rec.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestRenderNotAcceptable(t *testing.T) {
+	rec, err := renderWith(t, New(), "image/png, application/json;q=0", codecItem{})
+	if !errors.Is(err, ErrNotAcceptable) {
+		t.Fatalf("Render() error = %v, want ErrNotAcceptable", err)
+	}
+	if rec.Code != http.StatusNotAcceptable {
+		t.Fatalf("status = %d, want 406", rec.Code)
+	}
+}
+
+type protoMsg struct{ data []byte }
+
+func (m *protoMsg) Marshal() ([]byte, error) { return m.data, nil }
+func (m *protoMsg) Unmarshal(b []byte) error { m.data = append([]byte(nil), b...); return nil }
+
+func TestRenderProtobuf(t *testing.T) {
+	rec, err := renderWith(t, New(), "application/x-protobuf", &protoMsg{data: []byte{0x08, 0x96, 0x01}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Header().Get("Content-Type") != "application/x-protobuf" || rec.Body.String() != "\x08\x96\x01" {
+		t.Fatalf("got %q %q", rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+}
+
+func TestRegisterCodecReplacesInPlace(t *testing.T) {
+	g := New()
+	g.RegisterCodec(textJSONCodec{})
+	rec, err := renderWith(t, g, "", codecItem{Name: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.Body.String() != "custom" {
+		t.Fatalf("body = %q, want custom codec to keep JSON priority", rec.Body.String())
+	}
+	if g.Codec("application/json; charset=utf-8") != (textJSONCodec{}) {
+		t.Fatal("Codec() did not return the replacement")
+	}
+	if New().Codec("application/json") != JSONCodec {
+		t.Fatal("registration leaked into another engine")
+	}
+}
+
+type textJSONCodec struct{}
+
+func (textJSONCodec) MediaTypes() []string { return []string{"application/json"} }
+func (textJSONCodec) Encode(w io.Writer, v any) error {
+	_, err := io.WriteString(w, "custom")
+	return err
+}
+func (textJSONCodec) Decode(r io.Reader, v any) error { return nil }
+
+type bindCodecReq struct {
+	ID   int    `path:"id" json:"-" xml:"-" msgpack:"-"`
+	Name string `json:"name" xml:"name" yaml:"name" msgpack:"name" validate:"required"`
+}
+
+func TestBindByContentType(t *testing.T) {
+	var buf bytes.Buffer
+	if err := MsgPackCodec.Encode(&buf, map[string]any{"name": "mp"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ctype string
+		body  string
+		want  string
+	}{
+		{"application/json", `{"name":"js"}`, "js"},
+		{"application/xml", `<bindCodecReq><name>xm</name></bindCodecReq>`, "xm"},
+		{"application/x-yaml", "name: ym\n", "ym"},
+		{"application/msgpack", buf.String(), "mp"},
+	}
+	for _, tt := range tests {
+		g := New()
+		var got bindCodecReq
+		var bindErr error
+		g.POST("/items/#id", func(c *Context) { bindErr = c.Bind(&got) })
+		req := httptest.NewRequest("POST", "/items/7", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.ctype)
+		g.ServeHTTP(httptest.NewRecorder(), req)
+		if bindErr != nil {
+			t.Fatalf("%s: Bind() error = %v", tt.ctype, bindErr)
+		}
+		if got.Name != tt.want || got.ID != 7 {
+			t.Fatalf("%s: got %+v", tt.ctype, got)
+		}
+	}
+}
+
+func TestBindUnsupportedMediaType(t *testing.T) {
+	g := New()
+	var bindErr error
+	g.POST("/", func(c *Context) { bindErr = c.Bind(&bindCodecReq{}) })
+	req := httptest.NewRequest("POST", "/", strings.NewReader("name"))
+	req.Header.Set("Content-Type", "text/plain")
+	g.ServeHTTP(httptest.NewRecorder(), req)
+	if !errors.Is(bindErr, ErrUnsupportedMediaType) {
+		t.Fatalf("Bind() error = %v, want ErrUnsupportedMediaType", bindErr)
+	}
+}
+
+func TestFormCodecRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	in := codecItem{Name: "a b", Count: 3, Tags: []string{"x", "y"}}
+	if err := FormCodec.Encode(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "count=3&name=a+b&tag=x&tag=y" {
+		t.Fatalf("encoded = %q", buf.String())
+	}
+	var out codecItem
+	if err := FormCodec.Decode(&buf, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Fatalf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+type mpInner struct {
+	Score float64 `msgpack:"score"`
+}
+
+type mpEmbedded struct {
+	Level int
+}
+
+type mpValue struct {
+	mpEmbedded
+	Name    string            `msgpack:"name"`
+	Skip    string            `msgpack:"-"`
+	Empty   string            `msgpack:"empty,omitempty"`
+	Neg     int8              `json:"neg"`
+	Big     uint64            `msgpack:"big"`
+	Raw     []byte            `msgpack:"raw"`
+	List    []int             `msgpack:"list"`
+	Labels  map[string]string `msgpack:"labels"`
+	Inner   *mpInner          `msgpack:"inner"`
+	Nil     *mpInner          `msgpack:"nil"`
+	At      time.Time         `msgpack:"at"`
+	Any     any               `msgpack:"any"`
+	Fixed   [2]uint16         `msgpack:"fixed"`
+	Long    string            `msgpack:"long"`
+	Float32 float32           `msgpack:"f32"`
+}
+
+func TestMsgPackRoundTrip(t *testing.T) {
+	in := mpValue{
+		mpEmbedded: mpEmbedded{Level: 3},
+		Name:       "mp",
+		Skip:       "ignored",
+		Neg:        -100,
+		Big:        1 << 63,
+		Raw:        []byte{0, 1, 2},
+		List:       []int{1, -1, 70000, -70000},
+		Labels:     map[string]string{"a": "1", "b": "2"},
+		Inner:      &mpInner{Score: 1.5},
+		At:         time.Date(2024, 5, 6, 7, 8, 9, 123, time.UTC),
+		Any:        map[string]any{"k": []any{int64(1), "v", true, nil}},
+		Fixed:      [2]uint16{7, 65535},
+		Long:       strings.Repeat("x", 300),
+		Float32:    0.25,
+	}
+	var buf bytes.Buffer
+	if err := MsgPackCodec.Encode(&buf, in); err != nil {
+		t.Fatal(err)
+	}
+	var out mpValue
+	if err := MsgPackCodec.Decode(bytes.NewReader(buf.Bytes()), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := in
+	want.Skip = ""
+	if !out.At.Equal(want.At) {
+		t.Fatalf("At = %v, want %v", out.At, want.At)
+	}
+	out.At, want.At = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("round trip\n got %+v\nwant %+v", out, want)
+	}
+
+	var generic map[string]any
+	if err := MsgPackCodec.Decode(bytes.NewReader(buf.Bytes()), &generic); err != nil {
+		t.Fatal(err)
+	}
+	if generic["Level"] != int64(3) || generic["big"] != uint64(1<<63) || generic["neg"] != int64(-100) {
+		t.Fatalf("generic = %v", generic)
+	}
+	if _, ok := generic["empty"]; ok {
+		t.Fatal("omitempty field encoded")
+	}
+}
+
+func TestMsgPackDecodeErrors(t *testing.T) {
+	var small struct {
+		N int8 `msgpack:"n"`
+	}
+	var buf bytes.Buffer
+	MsgPackCodec.Encode(&buf, map[string]int{"n": 1000})
+	if err := MsgPackCodec.Decode(&buf, &small); err == nil || !strings.Contains(err.Error(), "overflows") {
+		t.Fatalf("overflow error = %v", err)
+	}
+	// 声明 4G 个元素的数组但没有数据
+	var list []int
+	err := MsgPackCodec.Decode(bytes.NewReader([]byte{0xdd, 0xff, 0xff, 0xff, 0xff}), &list)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("truncated error = %v", err)
+	}
+	// 深度嵌套的 fixarray 不能耗尽栈
+	deep := bytes.Repeat([]byte{0x91}, 4<<20)
+	var anyv any
+	if err := MsgPackCodec.Decode(bytes.NewReader(deep), &anyv); !errors.Is(err, errMsgpackTooDeep) {
+		t.Fatalf("deep any error = %v", err)
+	}
+	if err := MsgPackCodec.Decode(bytes.NewReader(deep), &list); !errors.Is(err, errMsgpackTooDeep) {
+		t.Fatalf("deep slice error = %v", err)
+	}
+}
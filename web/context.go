@@ -22,7 +22,8 @@ type Context struct {
 	// Writer 默认指向 &c.rw；中间件可替换为自定义 wrapper。
 	Writer http.ResponseWriter
 
-	param map[string]string
+	param     map[string]string
+	hostParam map[string]string
 
 	// Context: 请求生命周期的可取消 ctx，parent 是 request.Context()。
 	// values: GOweb 全局 value chain，仅作为 Value() 查找的 fallback，
@@ -30,17 +31,34 @@ type Context struct {
 	context.Context
 	cancel context.CancelFunc
 	values context.Context
+	engine *GOweb
+	// route 命中的路由节点，未命中（404 / 405）时为 nil
+	route *RouterGroup
 
 	rw responseWriter
 
 	handlers []HandlerFunc
 	index    int
+	// finish 请求结束时同步执行的回调
+	finish []func()
 }
 
 type (
 	HandlerFunc func(ctx *Context)
 	HandlerOpt  struct {
 		Description string
+		// Name 路由名，供 GOweb.URLFor 反向生成 URL；同名只能指向同一路径。
+		Name string
+
+		// 以下字段仅用于文档生成（web/openapi），不影响路由行为。
+		Summary    string
+		Tags       []string
+		Deprecated bool
+		// Request 请求类型样例（如 CreateUser{}），按 path/query/header/form/json tag 生成参数与 body；
+		// 注册时检查其 validate tag，非法则 panic。
+		Request any
+		// Response 200 响应体类型样例。
+		Response any
 	}
 )
 
@@ -68,6 +86,23 @@ func (c *Context) GetUrlPathParam(key string) string {
 	return c.param[key]
 }
 
+// RoutePath 返回命中路由注册时的路径模式，如 /users/#id:int；未命中路由时返回 ""。
+// 虚拟主机下为相对该主机路由树的路径。
+func (c *Context) RoutePath() string {
+	if c.route == nil {
+		return ""
+	}
+	if p := c.route.completePath(); p != "" {
+		return p
+	}
+	return "/"
+}
+
+// Engine 返回处理该请求的 GOweb；手工构造的 Context 返回 nil。
+func (c *Context) Engine() *GOweb {
+	return c.engine
+}
+
 func (c *Context) GetContextValue(key any) any {
 	return c.Value(key)
 }
@@ -114,6 +149,53 @@ func (c *Context) Stop() {
 	c.cancel()
 }
 
+// CancelFunc 返回取消本次请求 ctx 的函数。与 ctx.Stop 不同，它不引用会被回收复用的 Context，
+// 可以在请求结束后安全调用（此时为空操作）。
+func (c *Context) CancelFunc() context.CancelFunc {
+	return c.cancel
+}
+
+// OnFinish 注册请求结束时（handler 返回后、Context 回收前）同步执行的回调，按注册的逆序执行。
+func (c *Context) OnFinish(fn func()) {
+	c.finish = append(c.finish, fn)
+}
+
+// Replay 返回可在请求结束后调用的函数：以 w、r 新建 Context，先执行 head（代替当前 handler），
+// head 中调用 Next 继续执行当前 handler 之后的链；之前的中间件不会再执行。路由参数沿用本次请求。
+// 适合缓存后台刷新等需要脱离原请求重跑下游的场景。
+func (c *Context) Replay(head HandlerFunc) func(w http.ResponseWriter, r *http.Request) {
+	handlers := append([]HandlerFunc{head}, c.handlers[min(c.index+1, len(c.handlers)):]...)
+	param, hostParam := maps.Clone(c.param), maps.Clone(c.hostParam)
+	engine, route, values := c.engine, c.route, c.values
+	return func(w http.ResponseWriter, r *http.Request) {
+		rctx, cancel := context.WithCancel(r.Context())
+		ctx := &Context{
+			Request:   r,
+			Context:   rctx,
+			cancel:    cancel,
+			values:    values,
+			engine:    engine,
+			route:     route,
+			param:     param,
+			hostParam: hostParam,
+			handlers:  handlers,
+			index:     -1,
+		}
+		ctx.rw.reset(w)
+		ctx.Writer = &ctx.rw
+		defer func() {
+			if rec := recover(); rec != nil && rec != http.ErrAbortHandler && engine != nil {
+				engine.runPanicHandler(ctx, rec)
+			}
+			for i := len(ctx.finish) - 1; i >= 0; i-- {
+				ctx.finish[i]()
+			}
+			cancel()
+		}()
+		ctx.Next()
+	}
+}
+
 // Next 显式调用则先把后续跑完再回到调用点（用于尾置逻辑）。
 // ctx.Stop() / 客户端断开后下次循环立即短路。
 func (c *Context) Next() {
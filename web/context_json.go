@@ -3,11 +3,14 @@
 
 package web
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"io"
+)
 
 func (c *Context) ReadJSON(v any) error {
 	defer c.Request.Body.Close()
-	return json.NewDecoder(c.Request.Body).Decode(v)
+	return jsonDecode(c.Request.Body, v)
 }
 
 func (c *Context) WriteJSON(v any, statusCode ...int) error {
@@ -15,5 +18,8 @@ func (c *Context) WriteJSON(v any, statusCode ...int) error {
 	if len(statusCode) != 0 {
 		c.Writer.WriteHeader(statusCode[0])
 	}
-	return json.NewEncoder(c.Writer).Encode(v)
+	return jsonEncode(c.Writer, v)
 }
+
+func jsonEncode(w io.Writer, v any) error { return json.NewEncoder(w).Encode(v) }
+func jsonDecode(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) }
@@ -4,6 +4,8 @@
 package web
 
 import (
+	"io"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -12,7 +14,7 @@ var JSON = jsoniter.ConfigDefault
 func (c *Context) ReadJSON(v any) error {
 	defer c.Request.Body.Close()
 
-	return JSON.NewDecoder(c.Request.Body).Decode(v)
+	return jsonDecode(c.Request.Body, v)
 }
 
 func (c *Context) WriteJSON(v any, statusCode ...int) error {
@@ -20,5 +22,8 @@ func (c *Context) WriteJSON(v any, statusCode ...int) error {
 	if len(statusCode) != 0 {
 		c.Writer.WriteHeader(statusCode[0])
 	}
-	return JSON.NewEncoder(c.Writer).Encode(v)
+	return jsonEncode(c.Writer, v)
 }
+
+func jsonEncode(w io.Writer, v any) error { return JSON.NewEncoder(w).Encode(v) }
+func jsonDecode(r io.Reader, v any) error { return JSON.NewDecoder(r).Decode(v) }
@@ -4,6 +4,8 @@
 package web
 
 import (
+	"io"
+
 	"github.com/bytedance/sonic"
 )
 
@@ -12,7 +14,7 @@ var JSON = sonic.ConfigStd
 // 追求至极速度使用sonic ConfigFastest不验证json struct
 func (c *Context) ReadJSON(v any) error {
 	defer c.Request.Body.Close()
-	return JSON.NewDecoder(c.Request.Body).Decode(v)
+	return jsonDecode(c.Request.Body, v)
 }
 
 // 追求至极速度使用sonic ConfigFastest不验证json struct
@@ -21,5 +23,8 @@ func (c *Context) WriteJSON(v any, statusCode ...int) error {
 	if len(statusCode) != 0 {
 		c.Writer.WriteHeader(statusCode[0])
 	}
-	return JSON.NewEncoder(c.Writer).Encode(v)
+	return jsonEncode(c.Writer, v)
 }
+
+func jsonEncode(w io.Writer, v any) error { return JSON.NewEncoder(w).Encode(v) }
+func jsonDecode(r io.Reader, v any) error { return JSON.NewDecoder(r).Decode(v) }
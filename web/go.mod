@@ -6,6 +6,7 @@ require (
 	github.com/Rehtt/Kit v0.1.19
 	github.com/bytedance/sonic v1.14.2
 	github.com/json-iterator/go v1.1.12
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,6 +31,11 @@ type GOweb struct {
 	onPanic  func(*Context, any)
 	Server   *http.Server
 	context.Context
+
+	lc lifecycle
+
+	codecMu sync.Mutex
+	codecs  atomic.Pointer[codecTable]
 }
 
 var contextPool = sync.Pool{
@@ -38,6 +44,9 @@ var contextPool = sync.Pool{
 	},
 }
 
+// ServeHTTP 按 Host 选择路由树并匹配路径后执行中间件链与 handler。
+// 路径存在但方法不匹配时：HEAD 回落 GET；OPTIONS 自动应答 204 + Allow（仍经过中间件链，
+// 显式注册 OPTIONS 或 Any 即可接管）；其余方法写 405 + Allow。
 func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	var snap *routeSnapshot
 	if g.host != nil {
@@ -53,6 +62,8 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	ctx.Context = rctx
 	ctx.cancel = cancel
 	ctx.values = g.Context
+	ctx.engine = g
+	ctx.route = nil
 	ctx.param = nil
 	ctx.index = 0
 	if ctx.handlers != nil {
@@ -66,6 +77,11 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 			g.runPanicHandler(ctx, rec)
 		}
 
+		for i := len(ctx.finish) - 1; i >= 0; i-- {
+			ctx.finish[i]()
+			ctx.finish[i] = nil
+		}
+		ctx.finish = ctx.finish[:0]
 		if ctx.cancel != nil {
 			ctx.cancel()
 		}
@@ -74,7 +90,10 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		ctx.Context = nil
 		ctx.cancel = nil
 		ctx.values = nil
+		ctx.engine = nil
+		ctx.route = nil
 		ctx.param = nil
+		ctx.hostParam = nil
 		ctx.rw.reset(nil)
 		if ctx.handlers != nil {
 			// 清空 handler 引用，避免 pool 长期持有闭包。
@@ -96,21 +115,31 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	table, hostParam := snap.resolveHost(request.Host)
+	ctx.hostParam = hostParam
+
 	method := request.Method
 	// 用 URL.Path（已解码），不用 RequestURI。
-	params, handleFunc, leaf, allowed := snap.match(request.URL.Path, method)
+	params, handleFunc, leaf, allowed := table.match(request.URL.Path, method)
 
 	// HEAD 未注册时回落 GET（RFC 9110 §9.3.2）。
-	if handleFunc == nil && method == http.MethodHead && leaf != nil {
-		if h := leaf.method[http.MethodGet]; h != nil {
-			handleFunc = h
-			allowed = nil
-		} else if h := leaf.method[ANY]; h != nil {
+	if handleFunc == nil {
+		if h, _ := headFallback(leaf, method); h != nil {
 			handleFunc = h
 			allowed = nil
 		}
 	}
 
+	// OPTIONS 未注册时自动应答 Allow（RFC 9110 §9.3.7），仍走中间件链以便 CORS 处理预检。
+	if handleFunc == nil && method == http.MethodOptions && !allowed.empty() {
+		allow := allowed.headerValue() + ", OPTIONS"
+		handleFunc = func(ctx *Context) {
+			ctx.Writer.Header().Set("Allow", allow)
+			ctx.Writer.WriteHeader(http.StatusNoContent)
+		}
+		allowed = nil
+	}
+
 	if handleFunc == nil {
 		if !allowed.empty() {
 			ctx.Writer.Header().Set("Allow", allowed.headerValue())
@@ -122,6 +151,7 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	ctx.param = params
+	ctx.route = leaf
 
 	handleFuncOrder := leaf.order
 
@@ -131,6 +161,10 @@ func (g *GOweb) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	for gp := leaf; gp != nil; gp = gp.parent {
 		ancestors = append(ancestors, gp)
 	}
+	// 虚拟主机路由树之上再套默认树根节点的全局中间件
+	if table != snap {
+		ancestors = append(ancestors, snap.root)
+	}
 
 	chain := ctx.handlers
 	for i := len(ancestors) - 1; i >= 0; i-- {
@@ -208,7 +242,7 @@ func (g *GOweb) handler404(ctx *Context) {
 
 func New(opts ...Option) (g *GOweb) {
 	g = new(GOweb)
-	reg := &registry{}
+	reg := &registry{main: &g.RouterGroup}
 	g.RouterGroup.host = reg
 	g.Context = context.Background()
 	g.Server = &http.Server{
@@ -269,16 +303,27 @@ func (g *GOweb) RunContext(ctx context.Context, addr string) error {
 			shutdownCtx, cancel = context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 		}
-		_ = g.Server.Shutdown(shutdownCtx)
+		_ = g.Shutdown(shutdownCtx)
 		return <-errCh
 	case err := <-errCh:
 		return err
 	}
 }
 
+// Shutdown 优雅关停：停止接收新连接并通知 TrackConn 登记的长连接收尾，
+// 等待在途请求与长连接结束后执行 OnShutdown 钩子。ctx 到期时返回其错误，钩子仍会执行。
 func (g *GOweb) Shutdown(ctx context.Context) error {
-	if g.Server == nil {
-		return nil
+	drained := g.lc.beginShutdown()
+	var err error
+	if g.Server != nil {
+		err = g.Server.Shutdown(ctx)
+	}
+	if err == nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
 	}
-	return g.Server.Shutdown(ctx)
+	return errors.Join(err, g.lc.runShutdownHooks(ctx))
 }
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAutoOptionsAllow(t *testing.T) {
+	g := New()
+	var mw int
+	g.Middlewares(func(c *Context) { mw++ })
+	g.GET("/x", func(c *Context) {})
+	g.POST("/x", func(c *Context) {})
+	g.GET("/y", func(c *Context) {})
+	g.OPTIONS("/y", func(c *Context) { c.Writer.WriteHeader(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/x", nil))
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Allow") != "GET, POST, OPTIONS" {
+		t.Fatalf("code=%d allow=%q", rec.Code, rec.Header().Get("Allow"))
+	}
+	// 自动应答仍经过中间件链，便于 CORS 处理预检。
+	if mw != 1 {
+		t.Fatalf("middleware ran %d times", mw)
+	}
+
+	// 显式注册的 OPTIONS 优先。
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/y", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Allow") != "" {
+		t.Fatalf("explicit: code=%d allow=%q", rec.Code, rec.Header().Get("Allow"))
+	}
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing: code=%d", rec.Code)
+	}
+}
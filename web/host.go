@@ -0,0 +1,175 @@
+package web
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"sort"
+	"strings"
+)
+
+// hostRoute 是一条虚拟主机路由：写端持有 root，发布后 table 指向其子快照。
+type hostRoute struct {
+	pattern string
+	// labels 按 "." 切分后的模式段："*" 仅可出现在首段，匹配一个或多个标签；"#name" 匹配单个标签。
+	labels   []string
+	wildcard bool
+	params   int
+	order    int
+
+	root  *RouterGroup
+	table *routeSnapshot
+}
+
+func (h *hostRoute) exact() bool { return !h.wildcard && h.params == 0 }
+
+// Host 返回 pattern 对应的虚拟主机路由组，同一 pattern 多次调用返回同一组。
+//
+// pattern 支持：
+//   - 精确域名：api.example.com
+//   - 通配子域：*.example.com，匹配任意层级子域，不匹配裸域 example.com
+//   - 主机参数：#tenant.example.com，单个标签，handler 中经 ctx.GetHostParam("tenant") 读取
+//
+// 匹配优先级：精确 > 参数（标签多者优先）> 通配（标签多者优先）；均未命中时回落到 g 本身的路由。
+// 端口被忽略，大小写不敏感。g 上注册的中间件对所有虚拟主机同样生效，且先于主机组自身的中间件执行。
+func (g *GOweb) Host(pattern string) *RouterGroup {
+	reg := g.requireHost()
+	hr, err := parseHostPattern(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("[web] Host(%q): %v", pattern, err))
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, h := range reg.hosts {
+		if h.pattern == hr.pattern {
+			return h.root
+		}
+	}
+	hr.order = len(reg.hosts)
+	hr.root = &RouterGroup{host: reg}
+	reg.hosts = append(reg.hosts, hr)
+	reg.publish(hr.root)
+	return hr.root
+}
+
+func parseHostPattern(pattern string) (*hostRoute, error) {
+	p := normalizeHost(pattern)
+	if p == "" {
+		return nil, fmt.Errorf("empty host pattern")
+	}
+	hr := &hostRoute{pattern: p, labels: strings.Split(p, ".")}
+	seen := map[string]bool{}
+	for i, l := range hr.labels {
+		switch {
+		case l == "":
+			return nil, fmt.Errorf("empty label")
+		case l == "*":
+			if i != 0 {
+				return nil, fmt.Errorf("'*' must be the first label")
+			}
+			hr.wildcard = true
+		case strings.HasPrefix(l, paramPrefix):
+			name := l[len(paramPrefix):]
+			if name == "" || strings.ContainsAny(name, ":*#") {
+				return nil, fmt.Errorf("invalid host param %q", l)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate host param %q", name)
+			}
+			seen[name] = true
+			hr.params++
+		case strings.ContainsAny(l, "*#"):
+			return nil, fmt.Errorf("invalid label %q", l)
+		}
+	}
+	if hr.wildcard && len(hr.labels) < 2 {
+		return nil, fmt.Errorf("wildcard needs a parent domain")
+	}
+	return hr, nil
+}
+
+func sortHostPatterns(hs []*hostRoute) {
+	sort.SliceStable(hs, func(i, j int) bool {
+		a, b := hs[i], hs[j]
+		if a.wildcard != b.wildcard {
+			return !a.wildcard
+		}
+		if len(a.labels) != len(b.labels) {
+			return len(a.labels) > len(b.labels)
+		}
+		if a.params != b.params {
+			return a.params < b.params
+		}
+		return a.order < b.order
+	})
+}
+
+// match 逐标签匹配；命中时返回主机参数（无参数时为 nil）。
+func (h *hostRoute) match(labels []string) (map[string]string, bool) {
+	pat := h.labels
+	if h.wildcard {
+		// "*" 至少吞掉一个标签，其余自右向左对齐
+		if len(labels) < len(pat) {
+			return nil, false
+		}
+		labels = labels[len(labels)-len(pat)+1:]
+		pat = pat[1:]
+	} else if len(labels) != len(pat) {
+		return nil, false
+	}
+	var params map[string]string
+	for i, l := range pat {
+		if strings.HasPrefix(l, paramPrefix) {
+			if params == nil {
+				params = make(map[string]string, h.params)
+			}
+			params[l[len(paramPrefix):]] = labels[i]
+			continue
+		}
+		if l != labels[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// resolveHost 按请求 Host 选出路由树；未配置虚拟主机时零开销返回自身。
+func (snap *routeSnapshot) resolveHost(host string) (*routeSnapshot, map[string]string) {
+	if len(snap.exactHosts) == 0 && len(snap.hostPatterns) == 0 {
+		return snap, nil
+	}
+	h := normalizeHost(host)
+	if t, ok := snap.exactHosts[h]; ok {
+		return t, nil
+	}
+	if len(snap.hostPatterns) > 0 {
+		labels := strings.Split(h, ".")
+		for _, hp := range snap.hostPatterns {
+			if params, ok := hp.match(labels); ok {
+				return hp.table, params
+			}
+		}
+	}
+	return snap, nil
+}
+
+// normalizeHost 去掉端口与结尾的 "."，并转为小写。
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// GetHostParam 读取 GOweb.Host 模式中 #name 对应的主机标签。
+func (c *Context) GetHostParam(key string) string {
+	if c.hostParam == nil {
+		return ""
+	}
+	return c.hostParam[key]
+}
+
+func (c *Context) AllHostParam() map[string]string {
+	return maps.Clone(c.hostParam)
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func doHost(g *GOweb, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHostRouting(t *testing.T) {
+	g := New()
+	var trace []string
+	g.HeadMiddleware(func(c *Context) { trace = append(trace, "global") })
+	g.GET("/", func(c *Context) { c.WriteString("fallback") })
+
+	api := g.Host("API.example.com")
+	api.HeadMiddleware(func(c *Context) { trace = append(trace, "api") })
+	api.GET("/", func(c *Context) { c.WriteString("api") })
+
+	tenant := g.Host("#tenant.example.com")
+	tenant.GET("/", func(c *Context) { c.WriteString("tenant:" + c.GetHostParam("tenant")) })
+
+	g.Host("*.example.com").GET("/", func(c *Context) { c.WriteString("wildcard") })
+	g.Host("#tenant.#region.example.com").GET("/#id", func(c *Context) {
+		c.WriteString(c.GetHostParam("region") + "/" + c.GetHostParam("tenant") + "/" + c.GetUrlPathParam("id"))
+	})
+
+	if g.Host("api.example.com:443") != api {
+		t.Fatal("same pattern should return the same group")
+	}
+
+	cases := []struct{ host, path, want string }{
+		{"api.example.com:8080", "/", "api"},
+		{"acme.example.com", "/", "tenant:acme"},
+		{"a.b.c.example.com", "/", "wildcard"},
+		{"acme.eu.example.com", "/7", "eu/acme/7"},
+		{"example.com", "/", "fallback"},
+		{"other.org", "/", "fallback"},
+	}
+	for _, tc := range cases {
+		if got := doHost(g, tc.host, tc.path).Body.String(); got != tc.want {
+			t.Fatalf("%s%s = %q, want %q", tc.host, tc.path, got, tc.want)
+		}
+	}
+
+	trace = nil
+	doHost(g, "api.example.com", "/")
+	if strings.Join(trace, ",") != "global,api" {
+		t.Fatalf("trace = %v", trace)
+	}
+
+	// 命中主机但路径不存在：不回落默认树。
+	if rec := doHost(g, "api.example.com", "/nope"); rec.Code != 404 {
+		t.Fatalf("code = %d", rec.Code)
+	}
+}
+
+func TestHostHotRegistration(t *testing.T) {
+	g := New()
+	g.GET("/x", func(c *Context) { c.WriteString("main") })
+	h := g.Host("a.test")
+	if got := doHost(g, "a.test", "/x").Code; got != 404 {
+		t.Fatalf("code = %d", got)
+	}
+	h.GET("/x", func(c *Context) { c.WriteString("a") })
+	g.GET("/y", func(c *Context) { c.WriteString("main-y") })
+	if got := doHost(g, "a.test", "/x").Body.String(); got != "a" {
+		t.Fatalf("got %q", got)
+	}
+	if got := doHost(g, "b.test", "/y").Body.String(); got != "main-y" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestHostPatternInvalid(t *testing.T) {
+	for _, p := range []string{"", "a.*.com", "#x.#x.com", "*", "a..com"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("pattern %q should panic", p)
+				}
+			}()
+			New().Host(p)
+		}()
+	}
+}
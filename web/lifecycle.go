@@ -0,0 +1,241 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+type ServeOption struct {
+	// Listeners 同时监听的端点；为空时使用 Server.Addr（再为空则 ":http"）。
+	Listeners []Listener
+	// Signals 触发优雅关停的信号；nil 走默认 SIGINT / SIGTERM，空切片表示不监听信号。
+	// 关停期间再次收到信号会放弃等待、立即断开全部连接。
+	Signals []os.Signal
+	// ShutdownTimeout 排空在途请求、长连接与执行 OnShutdown 的总时长；0 走默认 30s。
+	ShutdownTimeout time.Duration
+}
+
+// lifecycle 记录钩子与需要在关停时主动关闭的长连接（SSE、WebSocket 等 http.Server 不跟踪的连接）。
+type lifecycle struct {
+	mu           sync.Mutex
+	onStart      []func(ctx context.Context) error
+	onShutdown   []func(ctx context.Context) error
+	conns        map[*trackedConn]struct{}
+	shuttingDown bool
+	// drained 在 conns 清空时关闭，仅关停期间有效
+	drained chan struct{}
+}
+
+type trackedConn struct {
+	close func()
+}
+
+// OnStart 注册启动钩子：Serve 在全部端点监听就绪后、开始接收请求前按注册顺序执行，
+// 任一返回错误则放弃启动。
+func (g *GOweb) OnStart(fn func(ctx context.Context) error) {
+	g.lc.mu.Lock()
+	defer g.lc.mu.Unlock()
+	g.lc.onStart = append(g.lc.onStart, fn)
+}
+
+// OnShutdown 注册关停钩子：在途请求与长连接排空后按注册的逆序执行，适合刷新队列、落盘缓存。
+func (g *GOweb) OnShutdown(fn func(ctx context.Context) error) {
+	g.lc.mu.Lock()
+	defer g.lc.mu.Unlock()
+	g.lc.onShutdown = append(g.lc.onShutdown, fn)
+}
+
+// TrackConn 登记一个长连接，优雅关停开始时调用 closeFn 通知其收尾；
+// 连接结束后须调用返回的 untrack。已处于关停中时 closeFn 会被立即异步调用。
+// 非 GOweb 分发的 Context 上调用为空操作。
+func (c *Context) TrackConn(closeFn func()) (untrack func()) {
+	if c.engine == nil {
+		return func() {}
+	}
+	return c.engine.lc.track(closeFn)
+}
+
+func (lc *lifecycle) track(closeFn func()) func() {
+	tc := &trackedConn{close: closeFn}
+	lc.mu.Lock()
+	if lc.conns == nil {
+		lc.conns = map[*trackedConn]struct{}{}
+	}
+	lc.conns[tc] = struct{}{}
+	shuttingDown := lc.shuttingDown
+	lc.mu.Unlock()
+	if shuttingDown {
+		go closeFn()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			lc.mu.Lock()
+			defer lc.mu.Unlock()
+			delete(lc.conns, tc)
+			if lc.shuttingDown && len(lc.conns) == 0 && lc.drained != nil {
+				close(lc.drained)
+				lc.drained = nil
+			}
+		})
+	}
+}
+
+// beginShutdown 标记关停并通知所有长连接收尾，返回长连接全部结束时关闭的 channel。
+func (lc *lifecycle) beginShutdown() <-chan struct{} {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	done := make(chan struct{})
+	if lc.shuttingDown {
+		// 重复关停：只等待剩余连接
+		if lc.drained == nil {
+			close(done)
+			return done
+		}
+		return lc.drained
+	}
+	lc.shuttingDown = true
+	if len(lc.conns) == 0 {
+		close(done)
+		return done
+	}
+	lc.drained = done
+	for tc := range lc.conns {
+		go tc.close()
+	}
+	return done
+}
+
+func (lc *lifecycle) runShutdownHooks(ctx context.Context) error {
+	lc.mu.Lock()
+	hooks := lc.onShutdown
+	lc.onShutdown = nil
+	lc.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Serve 在多个端点上同时提供服务并管理完整生命周期：
+// 监听全部端点 → OnStart → 服务，直到 ctx 取消、收到信号或任一端点出错 → Shutdown。
+// 正常关停返回 nil。
+func (g *GOweb) Serve(ctx context.Context, opt ServeOption) error {
+	listeners := opt.Listeners
+	if len(listeners) == 0 {
+		addr := g.Server.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		listeners = []Listener{ListenTCP(addr)}
+	}
+	signals := opt.Signals
+	if signals == nil {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	timeout := opt.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	type bound struct {
+		ln   net.Listener
+		spec Listener
+	}
+	var lns []bound
+	closeAll := func() {
+		for _, b := range lns {
+			b.ln.Close()
+		}
+	}
+	for _, spec := range listeners {
+		ls, err := spec.listen()
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("[web] listen %s: %w", spec, err)
+		}
+		for _, ln := range ls {
+			lns = append(lns, bound{ln: ln, spec: spec})
+		}
+	}
+
+	g.lc.mu.Lock()
+	onStart := g.lc.onStart
+	g.lc.mu.Unlock()
+	for _, fn := range onStart {
+		if err := fn(ctx); err != nil {
+			closeAll()
+			return err
+		}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	if len(signals) > 0 {
+		signal.Notify(sigCh, signals...)
+		defer signal.Stop(sigCh)
+	}
+
+	errCh := make(chan error, len(lns))
+	for _, b := range lns {
+		go func(b bound) {
+			errCh <- g.serveListener(b.ln, b.spec)
+		}(b)
+	}
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case <-sigCh:
+	case serveErr = <-errCh:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-shutdownCtx.Done():
+		}
+	}()
+
+	err := g.Shutdown(shutdownCtx)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		// 排空超时或被二次信号打断：强制断开剩余连接
+		g.Server.Close()
+	}
+	if errors.Is(serveErr, http.ErrServerClosed) {
+		serveErr = nil
+	}
+	return errors.Join(serveErr, err)
+}
+
+func (g *GOweb) serveListener(ln net.Listener, spec Listener) error {
+	switch {
+	case spec.CertFile != "":
+		return g.Server.ServeTLS(ln, spec.CertFile, spec.KeyFile)
+	case spec.TLSConfig != nil:
+		cfg := spec.TLSConfig.Clone()
+		if len(cfg.NextProtos) == 0 {
+			cfg.NextProtos = []string{"h2", "http/1.1"}
+		}
+		return g.Server.Serve(tls.NewListener(ln, cfg))
+	}
+	return g.Server.Serve(ln)
+}
@@ -0,0 +1,129 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestServeMultiListenerAndShutdown(t *testing.T) {
+	g := New()
+	var events []string
+	streamStarted := make(chan struct{})
+	g.GET("/ping", func(c *Context) { c.WriteString("pong") })
+	g.GET("/stream", func(c *Context) {
+		untrack := c.TrackConn(c.Stop)
+		defer untrack()
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.(http.Flusher).Flush()
+		close(streamStarted)
+		<-c.Done()
+		events = append(events, "stream closed")
+	})
+	g.OnStart(func(ctx context.Context) error {
+		events = append(events, "start")
+		return nil
+	})
+	g.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "shutdown 1")
+		return nil
+	})
+	g.OnShutdown(func(ctx context.Context) error {
+		events = append(events, "shutdown 2")
+		return nil
+	})
+
+	tcpAddr := freeAddr(t)
+	sock := filepath.Join(t.TempDir(), "web.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Serve(ctx, ServeOption{
+			Listeners:       []Listener{ListenTCP(tcpAddr), ListenUnix(sock)},
+			Signals:         []os.Signal{},
+			ShutdownTimeout: 5 * time.Second,
+		})
+	}()
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + tcpAddr + "/ping"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	resp, err = unixClient.Get("http://unix/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("unix body = %q", body)
+	}
+
+	go func() {
+		if resp, err := http.Get("http://" + tcpAddr + "/stream"); err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	<-streamStarted
+
+	start := time.Now()
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve() = %v", err)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("long-lived stream was not closed on shutdown")
+	}
+	if got := strings.Join(events, ","); got != "start,stream closed,shutdown 2,shutdown 1" {
+		t.Fatalf("events = %s", got)
+	}
+}
+
+func TestServeOnStartError(t *testing.T) {
+	g := New()
+	wantErr := errors.New("boom")
+	g.OnStart(func(ctx context.Context) error { return wantErr })
+	addr := freeAddr(t)
+	err := g.Serve(context.Background(), ServeOption{Listeners: []Listener{ListenTCP(addr)}})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("Serve() = %v", err)
+	}
+	// 监听已释放，可再次绑定。
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("listener leaked: %v", err)
+	}
+	ln.Close()
+}
@@ -0,0 +1,162 @@
+package web
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Listener 描述 Serve 的一个监听端点，由 ListenTCP / ListenUnix / ListenTLS / ListenSystemd 构造。
+type Listener struct {
+	Network string
+	Addr    string
+
+	// CertFile / KeyFile 或 TLSConfig 任一非空即在该端点启用 TLS。
+	CertFile  string
+	KeyFile   string
+	TLSConfig *tls.Config
+
+	// systemd 非 nil 表示从 LISTEN_FDS 继承，值为要取的 FileDescriptorName，空切片表示全部
+	systemd []string
+}
+
+func ListenTCP(addr string) Listener {
+	return Listener{Network: "tcp", Addr: addr}
+}
+
+// ListenUnix 监听 Unix socket；路径上残留的无主 socket 文件会被清理。
+func ListenUnix(path string) Listener {
+	return Listener{Network: "unix", Addr: path}
+}
+
+func ListenTLS(addr, certFile, keyFile string) Listener {
+	return Listener{Network: "tcp", Addr: addr, CertFile: certFile, KeyFile: keyFile}
+}
+
+// ListenTLSConfig 使用自定义 tls.Config；NextProtos 为空时默认协商 h2 / http/1.1。
+func ListenTLSConfig(addr string, config *tls.Config) Listener {
+	return Listener{Network: "tcp", Addr: addr, TLSConfig: config}
+}
+
+// ListenSystemd 使用 systemd socket activation 传入的监听（LISTEN_FDS），
+// names 按 FileDescriptorName 过滤，为空取全部。
+func ListenSystemd(names ...string) Listener {
+	if names == nil {
+		names = []string{}
+	}
+	return Listener{Network: "systemd", systemd: names}
+}
+
+func (l Listener) String() string {
+	if l.systemd != nil {
+		return "systemd:" + strings.Join(l.systemd, ",")
+	}
+	return l.Network + "://" + l.Addr
+}
+
+// listen 打开端点；systemd 端点可能对应多个 fd。
+func (l Listener) listen() ([]net.Listener, error) {
+	if l.systemd != nil {
+		return systemdListeners(l.systemd)
+	}
+	if l.Network == "unix" {
+		removeStaleSocket(l.Addr)
+	}
+	ln, err := net.Listen(l.Network, l.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return []net.Listener{ln}, nil
+}
+
+func removeStaleSocket(path string) {
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	if c, err := net.Dial("unix", path); err == nil {
+		c.Close()
+		return
+	}
+	os.Remove(path)
+}
+
+var (
+	systemdOnce      sync.Once
+	systemdFiles     []*os.File
+	systemdFileNames []string
+	systemdErr       error
+)
+
+const systemdListenFdsStart = 3
+
+// loadSystemdFiles 只解析一次环境变量并清除，避免被子进程继承。
+func loadSystemdFiles() ([]*os.File, []string, error) {
+	systemdOnce.Do(func() {
+		defer func() {
+			os.Unsetenv("LISTEN_PID")
+			os.Unsetenv("LISTEN_FDS")
+			os.Unsetenv("LISTEN_FDNAMES")
+		}()
+
+		if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+			systemdErr = errors.New("[web] no systemd sockets for this process")
+			return
+		}
+		n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+		if err != nil || n <= 0 {
+			systemdErr = errors.New("[web] invalid LISTEN_FDS")
+			return
+		}
+		names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+		for i := 0; i < n; i++ {
+			fd := systemdListenFdsStart + i
+			name := "LISTEN_FD_" + strconv.Itoa(fd)
+			if i < len(names) && names[i] != "" {
+				name = names[i]
+			}
+			systemdFiles = append(systemdFiles, os.NewFile(uintptr(fd), name))
+			systemdFileNames = append(systemdFileNames, name)
+		}
+	})
+	return systemdFiles, systemdFileNames, systemdErr
+}
+
+func systemdListeners(want []string) ([]net.Listener, error) {
+	files, names, err := loadSystemdFiles()
+	if err != nil {
+		return nil, err
+	}
+	var lns []net.Listener
+	for i, f := range files {
+		if len(want) > 0 && !containsName(want, names[i]) {
+			continue
+		}
+		ln, err := net.FileListener(f)
+		if err != nil {
+			for _, l := range lns {
+				l.Close()
+			}
+			return nil, fmt.Errorf("[web] systemd fd %s: %w", names[i], err)
+		}
+		lns = append(lns, ln)
+	}
+	if len(lns) == 0 {
+		return nil, fmt.Errorf("[web] no systemd sockets named %v", want)
+	}
+	return lns, nil
+}
+
+func containsName(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"maps"
+	"net/http"
+	"sort"
+)
+
+// RouteMatch 一次路由匹配的结果，见 GOweb.Match。
+type RouteMatch struct {
+	// Group 命中的路由节点（已发布快照中的只读副本）。
+	Group *RouterGroup
+	// Path 注册时的路径模式，如 /users/#id:int；虚拟主机下相对该主机的路由树。
+	Path string
+	// Method 实际命中的注册方法：HEAD 回落时为 GET，Any 注册时为 ANY。
+	Method string
+	// Option 注册时的 HandlerOpt。
+	Option HandlerOpt
+	// Params 路径参数；HostParams 虚拟主机参数。
+	Params     map[string]string
+	HostParams map[string]string
+	// Allowed 路径命中但方法不匹配时该路径已注册的方法（按字典序），此时 Match 返回 false。
+	Allowed []string
+}
+
+// Match 按 ServeHTTP 相同的规则（虚拟主机、HEAD 回落 GET）匹配请求但不执行 handler，
+// 供路由表测试与调试使用。host 可带端口；未命中或方法不匹配时返回 false。
+func (g *GOweb) Match(method, host, path string) (RouteMatch, bool) {
+	if g.host == nil {
+		return RouteMatch{}, false
+	}
+	snap := g.host.snapshot.Load()
+	if snap == nil {
+		return RouteMatch{}, false
+	}
+	table, hostParam := snap.resolveHost(host)
+	params, handle, leaf, allowed := table.match(path, method)
+	if leaf == nil {
+		return RouteMatch{}, false
+	}
+	m := RouteMatch{
+		Group:      leaf,
+		Path:       leaf.completePath(),
+		Params:     maps.Clone(params),
+		HostParams: maps.Clone(hostParam),
+	}
+	if m.Path == "" {
+		m.Path = "/"
+	}
+	if handle == nil {
+		if _, fallback := headFallback(leaf, method); fallback != "" {
+			m.Method = fallback
+			m.Option = leaf.options[fallback]
+			return m, true
+		}
+		for k := range allowed {
+			m.Allowed = append(m.Allowed, k)
+		}
+		sort.Strings(m.Allowed)
+		return m, false
+	}
+	m.Method = method
+	if _, ok := leaf.method[method]; !ok {
+		m.Method = ANY
+	}
+	m.Option = leaf.options[m.Method]
+	return m, true
+}
+
+// headFallback 未注册 HEAD 时回落到 GET / ANY（RFC 9110 §9.3.2），返回 handler 与命中的方法。
+func headFallback(leaf *RouterGroup, method string) (HandlerFunc, string) {
+	if method != http.MethodHead || leaf == nil {
+		return nil, ""
+	}
+	if h := leaf.method[http.MethodGet]; h != nil {
+		return h, http.MethodGet
+	}
+	if h := leaf.method[ANY]; h != nil {
+		return h, ANY
+	}
+	return nil, ""
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type AccessLogOption struct {
+	// Logger 输出目标；nil 走 slog.Default()。
+	Logger *slog.Logger
+	// Skip 返回 true 时不记录，常用于健康检查。
+	Skip func(c *web.Context) bool
+	// Message 日志消息；空走默认 "access"。
+	Message string
+}
+
+// AccessLog 在链结束后记录一条结构化访问日志。
+// status / size 取自外层 web.ResponseWriter，不受内层 Writer 包装（如 Encoding）影响；
+// 5xx 记为 Error，4xx 记为 Warn，其余 Info。
+func AccessLog(opts ...AccessLogOption) web.HandlerFunc {
+	opt := AccessLogOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Message == "" {
+		opt.Message = "access"
+	}
+
+	return func(c *web.Context) {
+		if opt.Skip != nil && opt.Skip(c) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		rw, _ := c.Writer.(web.ResponseWriter)
+		req := c.Request
+		path := req.URL.Path
+
+		c.Next()
+
+		logger := opt.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		status, size := http.StatusOK, 0
+		if rw != nil {
+			if rw.Written() {
+				status = rw.Status()
+			}
+			size = rw.Size()
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Int("size", size),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("ip", ClientIP(c)),
+			slog.String("user_agent", req.UserAgent()),
+		}
+		if id := GetRequestID(c); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		logger.LogAttrs(context.Background(), level, opt.Message, attrs...)
+	}
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+// BodyLimit 限制请求体大小：Content-Length 已知超限时直接 413；
+// 否则以 http.MaxBytesReader 包装 Body，读取超限时返回 *http.MaxBytesError，
+// 并由 net/http 在响应后关闭连接。
+func BodyLimit(limit int64) web.HandlerFunc {
+	if limit <= 0 {
+		panic("[web] BodyLimit: limit must be positive")
+	}
+	return func(c *web.Context) {
+		if c.Request.ContentLength > limit {
+			http.Error(c.Writer, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			c.Stop()
+			return
+		}
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		}
+		c.Next()
+	}
+}
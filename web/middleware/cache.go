@@ -0,0 +1,634 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type CacheOption struct {
+	// TTL 响应未声明 s-maxage / max-age 时的新鲜期；0 走默认 1 分钟。
+	TTL time.Duration
+	// StaleWhileRevalidate 过期后仍返回旧响应并在后台刷新的窗口；响应 Cache-Control 中的同名指令优先。
+	StaleWhileRevalidate time.Duration
+	// Store 存储后端；nil 时使用 MemoryCacheStore。
+	Store CacheStore
+	// KeyFunc 计算主键；nil 按 Host + RequestURI。返回空串表示该请求不走缓存。
+	KeyFunc func(c *web.Context) string
+	// VaryHeaders 始终参与缓存键的请求头；响应 Vary 中的请求头会自动加入。
+	VaryHeaders []string
+	// Statuses 可缓存的状态码；nil 走默认 200/203/204/300/301/308/404/410。
+	Statuses []int
+	// MaxBodySize 响应体超过该字节数时改为直接透传且不缓存；0 走默认 1MB。
+	MaxBodySize int
+	// DisableETag 不为缺少 ETag 的响应生成内容哈希 ETag。
+	DisableETag bool
+	// IgnoreRequestCacheControl 忽略请求中的 no-cache / no-store / max-age=0。
+	IgnoreRequestCacheControl bool
+	// CookieRequests 带 Cookie 的请求也走缓存（默认直接回源，既不读也不写缓存）。
+	// 开启后按用户区分的响应必须由 handler 设置 Cache-Control: private，或把会话 cookie 通过 KeyFunc 纳入主键。
+	CookieRequests bool
+}
+
+const (
+	defaultCacheTTL     = time.Minute
+	defaultCacheMaxBody = 1 << 20
+	// cacheTagHeader handler 声明缓存标签的响应头，写入索引后移除，不下发给客户端
+	cacheTagHeader = "Cache-Tag"
+	// cacheSweepInterval 清扫索引中已过期条目的最小间隔
+	cacheSweepInterval = time.Minute
+)
+
+var defaultCacheStatuses = []int{200, 203, 204, 300, 301, 308, 404, 410}
+
+// ResponseCache 服务端 GET 响应缓存，见 NewResponseCache。
+type ResponseCache struct {
+	opt CacheOption
+
+	mu sync.RWMutex
+	// vary 主键 → 参与二级键的请求头（规范化、排序）
+	vary      map[string][]string
+	entries   map[string]cacheIndexEntry
+	byPrimary map[string]map[string]struct{}
+	byRoute   map[string]map[string]struct{}
+	byTag     map[string]map[string]struct{}
+	lastSweep time.Time
+
+	// refreshing 正在后台刷新的键，同一键同时只刷新一次
+	refreshing sync.Map
+}
+
+type cacheIndexEntry struct {
+	primary  string
+	route    string
+	tags     []string
+	expireAt time.Time
+}
+
+// NewResponseCache 创建响应缓存，Handler 返回中间件：
+//   - 仅缓存 GET，HEAD 复用 GET 的条目；键为主键 + Vary 请求头取值
+//   - 遵循请求 / 响应 Cache-Control：no-store、private、no-cache、max-age / s-maxage、stale-while-revalidate
+//   - 带 Set-Cookie 的响应、带 Authorization 且未声明 public / s-maxage 的响应不缓存
+//   - 带 Cookie 的请求默认绕过缓存，避免把按会话生成的页面下发给其他用户（见 CacheOption.CookieRequests）
+//   - 命中时补 Age 与 X-Cache（HIT / STALE / MISS），If-None-Match / If-Modified-Since 命中时返回 304
+//   - 过期但仍在 stale 窗口内时返回旧响应，并在后台经 Context.Replay 只重新执行缓存之后的 handler 刷新
+//   - 非安全方法成功响应后失效同一主键的缓存（RFC 9111 §4.4）
+func NewResponseCache(opts ...CacheOption) *ResponseCache {
+	opt := CacheOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = defaultCacheTTL
+	}
+	if opt.Store == nil {
+		opt.Store = NewMemoryCacheStore()
+	}
+	if opt.KeyFunc == nil {
+		opt.KeyFunc = defaultCacheKey
+	}
+	if opt.Statuses == nil {
+		opt.Statuses = defaultCacheStatuses
+	}
+	if opt.MaxBodySize <= 0 {
+		opt.MaxBodySize = defaultCacheMaxBody
+	}
+	opt.VaryHeaders = normalizeVary(opt.VaryHeaders)
+	return &ResponseCache{
+		opt:       opt,
+		vary:      map[string][]string{},
+		entries:   map[string]cacheIndexEntry{},
+		byPrimary: map[string]map[string]struct{}{},
+		byRoute:   map[string]map[string]struct{}{},
+		byTag:     map[string]map[string]struct{}{},
+	}
+}
+
+// Cache 等价于 NewResponseCache(opts...).Handler()，适合不需要主动清除的场景。
+func Cache(opts ...CacheOption) web.HandlerFunc {
+	return NewResponseCache(opts...).Handler()
+}
+
+func defaultCacheKey(c *web.Context) string {
+	return c.Request.Host + c.Request.URL.RequestURI()
+}
+
+// SetCacheTags 为当前响应声明缓存标签，之后可用 PurgeTag 批量清除。需在写出响应前调用。
+func SetCacheTags(c *web.Context, tags ...string) {
+	for _, tag := range tags {
+		c.Writer.Header().Add(cacheTagHeader, tag)
+	}
+}
+
+func (rc *ResponseCache) Handler() web.HandlerFunc {
+	return func(c *web.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			rc.invalidateAfter(c)
+			return
+		default:
+			c.Next()
+			return
+		}
+		primary := rc.opt.KeyFunc(c)
+		if primary == "" || (!rc.opt.CookieRequests && c.Request.Header.Get("Cookie") != "") {
+			c.Next()
+			return
+		}
+		reqCC := parseCacheControl(c.Request.Header.Values("Cache-Control"))
+		if !rc.opt.IgnoreRequestCacheControl {
+			if _, ok := reqCC["no-store"]; ok {
+				c.Next()
+				return
+			}
+		}
+		key := rc.variantKey(primary, c.Request.Header)
+
+		if !rc.requestBypass(reqCC) {
+			if rc.serveCached(c, key, primary) {
+				return
+			}
+		}
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		rc.fill(c, primary)
+	}
+}
+
+// requestBypass 请求要求跳过缓存直接回源（no-cache / max-age=0），回源结果仍会写入缓存。
+func (rc *ResponseCache) requestBypass(cc map[string]string) bool {
+	if rc.opt.IgnoreRequestCacheControl {
+		return false
+	}
+	if _, ok := cc["no-cache"]; ok {
+		return true
+	}
+	return cc["max-age"] == "0"
+}
+
+// serveCached 命中新鲜或 stale 条目时写出响应并返回 true。
+func (rc *ResponseCache) serveCached(c *web.Context, key, primary string) bool {
+	entry, ok, err := rc.opt.Store.Get(c, key)
+	if err != nil {
+		log.Printf("[web] cache store error: %v", err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	age, fresh, stale := entry.age(time.Now())
+	if !fresh && !stale {
+		return false
+	}
+
+	h := c.Writer.Header()
+	for k, v := range entry.Header {
+		h[k] = slices.Clone(v)
+	}
+	h.Set("Age", strconv.Itoa(int(age/time.Second)))
+	if fresh {
+		h.Set("X-Cache", "HIT")
+	} else {
+		h.Set("X-Cache", "STALE")
+		rc.revalidate(c, key, primary)
+	}
+	c.Stop()
+	if entry.Status == http.StatusOK && notModified(c.Request, h) {
+		h.Del("Content-Length")
+		c.Writer.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	c.Writer.WriteHeader(entry.Status)
+	if c.Request.Method != http.MethodHead {
+		c.Writer.Write(entry.Body)
+	}
+	return true
+}
+
+// revalidate 在后台以一个脱离原请求生命周期的副本重新执行缓存之后的 handler，结果经 fill 写回缓存。
+// 缓存之前的中间件（日志、限流、鉴权等）不会为后台刷新再执行一次。
+func (rc *ResponseCache) revalidate(c *web.Context, key, primary string) {
+	if _, loaded := rc.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	req := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+	req.Method = http.MethodGet
+	req.Header.Del("If-None-Match")
+	req.Header.Del("If-Modified-Since")
+	replay := c.Replay(func(c *web.Context) { rc.fill(c, primary) })
+	go func() {
+		defer rc.refreshing.Delete(key)
+		replay(&discardResponseWriter{header: http.Header{}}, req)
+	}()
+}
+
+// fill 回源：缓冲后续链的响应，按规则写入缓存后再下发给客户端。
+func (rc *ResponseCache) fill(c *web.Context, primary string) {
+	original := c.Writer
+	cw := &captureWriter{ResponseWriter: original, limit: rc.opt.MaxBodySize}
+	c.Writer = cw
+	defer func() { c.Writer = original }()
+
+	c.Next()
+
+	if cw.passthrough {
+		return
+	}
+	status := cw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	h := original.Header()
+	tags := h.Values(cacheTagHeader)
+	h.Del(cacheTagHeader)
+
+	if entry := rc.storable(c, status, h); entry != nil {
+		if status == http.StatusOK && !rc.opt.DisableETag && h.Get("ETag") == "" {
+			sum := sha1.Sum(cw.buf.Bytes())
+			h.Set("ETag", `"`+hex.EncodeToString(sum[:12])+`"`)
+		}
+		entry.Header = h.Clone()
+		entry.Header.Del("Date")
+		entry.Body = bytes.Clone(cw.buf.Bytes())
+		entry.Route = c.RoutePath()
+		entry.Tags = splitTags(tags)
+		rc.store(c, primary, entry)
+	}
+	h.Set("X-Cache", "MISS")
+
+	if status == http.StatusOK && notModified(c.Request, h) {
+		h.Del("Content-Length")
+		original.WriteHeader(http.StatusNotModified)
+		return
+	}
+	original.WriteHeader(status)
+	original.Write(cw.buf.Bytes())
+}
+
+// storable 判定响应可否缓存；可以时返回填好新鲜期的条目。
+func (rc *ResponseCache) storable(c *web.Context, status int, h http.Header) *CachedResponse {
+	if !slices.Contains(rc.opt.Statuses, status) {
+		return nil
+	}
+	if len(h.Values("Set-Cookie")) > 0 {
+		return nil
+	}
+	if slices.Contains(normalizeVary(h.Values("Vary")), "*") {
+		return nil
+	}
+	cc := parseCacheControl(h.Values("Cache-Control"))
+	for _, d := range []string{"no-store", "private", "no-cache"} {
+		if _, ok := cc[d]; ok {
+			return nil
+		}
+	}
+	_, public := cc["public"]
+	sMaxAge, hasShared := cc["s-maxage"]
+	if c.Request.Header.Get("Authorization") != "" && !public && !hasShared {
+		return nil
+	}
+	ttl := rc.opt.TTL
+	if hasShared {
+		ttl = parseSeconds(sMaxAge)
+	} else if v, ok := cc["max-age"]; ok {
+		ttl = parseSeconds(v)
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	stale := rc.opt.StaleWhileRevalidate
+	if v, ok := cc["stale-while-revalidate"]; ok {
+		stale = parseSeconds(v)
+	}
+	return &CachedResponse{Status: status, StoredAt: time.Now(), TTL: ttl, Stale: stale}
+}
+
+// store 记录 Vary 并写入存储与索引。Vary 变化后旧变体不再可达，一并清除。
+func (rc *ResponseCache) store(c *web.Context, primary string, entry *CachedResponse) {
+	names := normalizeVary(append(entry.Header.Values("Vary"), rc.opt.VaryHeaders...))
+
+	rc.mu.Lock()
+	if prev, ok := rc.vary[primary]; !ok || !slices.Equal(prev, names) {
+		if ok {
+			rc.purgeLocked(c, rc.byPrimary[primary])
+		}
+		rc.vary[primary] = names
+	}
+	key := variantKey(primary, names, c.Request.Header)
+	rc.indexLocked(key, cacheIndexEntry{
+		primary:  primary,
+		route:    entry.Route,
+		tags:     entry.Tags,
+		expireAt: entry.StoredAt.Add(entry.TTL + entry.Stale),
+	})
+	rc.mu.Unlock()
+
+	if err := rc.opt.Store.Set(c, key, entry, entry.TTL+entry.Stale); err != nil {
+		log.Printf("[web] cache store error: %v", err)
+	}
+}
+
+// invalidateAfter 执行非安全方法，成功（2xx / 3xx）后失效同一主键的全部变体。
+func (rc *ResponseCache) invalidateAfter(c *web.Context) {
+	c.Next()
+	rw, ok := c.Writer.(web.ResponseWriter)
+	if !ok {
+		return
+	}
+	// 未写出任何内容时 net/http 会补 200
+	if status := rw.Status(); status != 0 && (status < 200 || status >= 400) {
+		return
+	}
+	if primary := rc.opt.KeyFunc(c); primary != "" {
+		rc.PurgeKey(primary)
+	}
+}
+
+func (rc *ResponseCache) variantKey(primary string, h http.Header) string {
+	rc.mu.RLock()
+	names, ok := rc.vary[primary]
+	rc.mu.RUnlock()
+	if !ok {
+		names = rc.opt.VaryHeaders
+	}
+	return variantKey(primary, names, h)
+}
+
+func variantKey(primary string, names []string, h http.Header) string {
+	if len(names) == 0 {
+		return primary
+	}
+	var b strings.Builder
+	b.WriteString(primary)
+	for _, name := range names {
+		b.WriteByte('\n')
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(h.Values(name), ","))
+	}
+	return b.String()
+}
+
+// PurgeKey 清除主键（默认 Host + RequestURI，如 example.com/users/7?full=1）下的全部变体，返回清除条数。
+func (rc *ResponseCache) PurgeKey(primary string) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.purgeLocked(context.Background(), rc.byPrimary[primary])
+}
+
+// PurgeRoute 清除某路由模式（如 /users/#id:int）下缓存的全部响应，返回清除条数。
+func (rc *ResponseCache) PurgeRoute(pattern string) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.purgeLocked(context.Background(), rc.byRoute[pattern])
+}
+
+// PurgeTag 清除声明了任一标签的响应，返回清除条数。
+func (rc *ResponseCache) PurgeTag(tags ...string) int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	n := 0
+	for _, tag := range tags {
+		n += rc.purgeLocked(context.Background(), rc.byTag[tag])
+	}
+	return n
+}
+
+// PurgeAll 清除本进程写入的全部条目，返回清除条数。
+func (rc *ResponseCache) PurgeAll() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	keys := make(map[string]struct{}, len(rc.entries))
+	for k := range rc.entries {
+		keys[k] = struct{}{}
+	}
+	return rc.purgeLocked(context.Background(), keys)
+}
+
+// purgeLocked 删除一组键；set 可能是索引自身的集合，先拷贝再删。
+func (rc *ResponseCache) purgeLocked(ctx context.Context, set map[string]struct{}) int {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		rc.unindexLocked(k)
+		if err := rc.opt.Store.Delete(ctx, k); err != nil {
+			log.Printf("[web] cache store error: %v", err)
+		}
+	}
+	return len(keys)
+}
+
+func (rc *ResponseCache) indexLocked(key string, e cacheIndexEntry) {
+	now := time.Now()
+	if now.Sub(rc.lastSweep) >= cacheSweepInterval {
+		rc.lastSweep = now
+		for k, old := range rc.entries {
+			if now.After(old.expireAt) {
+				rc.unindexLocked(k)
+			}
+		}
+	}
+	rc.unindexLocked(key)
+	rc.entries[key] = e
+	addToSet(rc.byPrimary, e.primary, key)
+	if e.route != "" {
+		addToSet(rc.byRoute, e.route, key)
+	}
+	for _, tag := range e.tags {
+		addToSet(rc.byTag, tag, key)
+	}
+}
+
+func (rc *ResponseCache) unindexLocked(key string) {
+	e, ok := rc.entries[key]
+	if !ok {
+		return
+	}
+	delete(rc.entries, key)
+	removeFromSet(rc.byPrimary, e.primary, key)
+	if _, ok := rc.byPrimary[e.primary]; !ok {
+		delete(rc.vary, e.primary)
+	}
+	removeFromSet(rc.byRoute, e.route, key)
+	for _, tag := range e.tags {
+		removeFromSet(rc.byTag, tag, key)
+	}
+}
+
+func addToSet(m map[string]map[string]struct{}, name, key string) {
+	set := m[name]
+	if set == nil {
+		set = map[string]struct{}{}
+		m[name] = set
+	}
+	set[key] = struct{}{}
+}
+
+func removeFromSet(m map[string]map[string]struct{}, name, key string) {
+	if set := m[name]; set != nil {
+		delete(set, key)
+		if len(set) == 0 {
+			delete(m, name)
+		}
+	}
+}
+
+// notModified 按 RFC 9110 §13.1：有 If-None-Match 时只比较 ETag（弱比较），否则比较 If-Modified-Since。
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(h.Get("ETag"), "W/")
+		if etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(h.Get("Last-Modified"))
+	return err == nil && !lm.After(ims)
+}
+
+// parseCacheControl 解析 Cache-Control 指令，键小写，值去引号。
+func parseCacheControl(values []string) map[string]string {
+	cc := map[string]string{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(part, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	return cc
+}
+
+func parseSeconds(v string) time.Duration {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second
+}
+
+// normalizeVary 拆分逗号列表，规范化大小写，去重排序。
+func normalizeVary(values []string) []string {
+	var names []string
+	for _, v := range values {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func splitTags(values []string) []string {
+	var tags []string
+	for _, v := range values {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// captureWriter 缓冲后续链的响应；超过 limit、Flush 或 Hijack 后转为透传且不再缓存。
+type captureWriter struct {
+	http.ResponseWriter
+	limit int
+
+	status      int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+func (w *captureWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = code
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.buf.Len()+len(b) > w.limit {
+		if err := w.spill(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+func (w *captureWriter) Flush() {
+	if !w.passthrough {
+		_ = w.spill()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// spill 放弃缓存，把已缓冲的内容原样写出。
+func (w *captureWriter) spill() error {
+	w.passthrough = true
+	w.ResponseWriter.Header().Del(cacheTagHeader)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// discardResponseWriter 后台刷新时的占位 writer，响应由 fill 写入缓存后丢弃。
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Rehtt/Kit/maps"
+)
+
+// CachedResponse 缓存的一份完整响应。字段均导出，便于共享后端序列化。
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// StoredAt 写入时间；Age 与新鲜度据此计算。
+	StoredAt time.Time
+	// TTL 新鲜期；Stale 过期后仍可返回旧响应并后台刷新的时长。
+	TTL   time.Duration
+	Stale time.Duration
+	// Route 命中的路由模式；Tags 响应声明的缓存标签。用于按路由 / 标签清除。
+	Route string
+	Tags  []string
+}
+
+// age 返回当前年龄与所处阶段：fresh 新鲜，stale 处于 stale-while-revalidate 窗口。
+func (r *CachedResponse) age(now time.Time) (age time.Duration, fresh, stale bool) {
+	age = now.Sub(r.StoredAt)
+	if age < 0 {
+		age = 0
+	}
+	return age, age < r.TTL, age >= r.TTL && age < r.TTL+r.Stale
+}
+
+// CacheStore 响应缓存存储。ttl 为条目应保留的总时长（新鲜期 + stale 窗口）。
+// 按路由 / 标签清除依赖进程内索引，共享后端时只清除本进程写入过的条目。
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryCacheStore 基于 maps.ConcurrentMap 的进程内存储，条目按 TTL 自动淘汰。
+type MemoryCacheStore struct {
+	items *maps.ConcurrentMap[*CachedResponse]
+}
+
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{items: maps.NewConcurrentMap[*CachedResponse]()}
+}
+
+func (s *MemoryCacheStore) Get(_ context.Context, key string) (*CachedResponse, bool, error) {
+	resp, ok := s.items.Get(key)
+	return resp, ok, nil
+}
+
+func (s *MemoryCacheStore) Set(_ context.Context, key string, resp *CachedResponse, ttl time.Duration) error {
+	// ConcurrentMap 按整秒判定过期，多留 1s 余量；新鲜度仍以 StoredAt/TTL 为准
+	s.items.Set(key, resp, ttl+time.Second)
+	return nil
+}
+
+func (s *MemoryCacheStore) Delete(_ context.Context, key string) error {
+	s.items.Delete(key)
+	return nil
+}
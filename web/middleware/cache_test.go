@@ -0,0 +1,284 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func cacheReq(g *web.GOweb, method, path string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCacheHitAndConditional(t *testing.T) {
+	var calls atomic.Int32
+	g := web.New()
+	g.Middlewares(Cache())
+	g.GET("/users/#id", func(c *web.Context) {
+		n := calls.Add(1)
+		c.Writer.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(c.Writer, "user %s #%d", c.GetUrlPathParam("id"), n)
+	})
+
+	first := cacheReq(g, "GET", "/users/1")
+	if first.Header().Get("X-Cache") != "MISS" || first.Body.String() != "user 1 #1" {
+		t.Fatalf("first: %s %q", first.Header().Get("X-Cache"), first.Body.String())
+	}
+	etag := first.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("ETag not generated")
+	}
+
+	hit := cacheReq(g, "GET", "/users/1")
+	if hit.Header().Get("X-Cache") != "HIT" || hit.Body.String() != "user 1 #1" || hit.Header().Get("Age") == "" {
+		t.Fatalf("hit: %v %q", hit.Header(), hit.Body.String())
+	}
+	if got := cacheReq(g, "GET", "/users/2").Body.String(); got != "user 2 #2" {
+		t.Fatalf("other path = %q", got)
+	}
+
+	nm := cacheReq(g, "GET", "/users/1", "If-None-Match", `W/"x", `+etag)
+	if nm.Code != http.StatusNotModified || nm.Body.Len() != 0 || nm.Header().Get("ETag") != etag {
+		t.Fatalf("conditional: %d %q", nm.Code, nm.Body.String())
+	}
+	head := cacheReq(g, "HEAD", "/users/1")
+	if head.Header().Get("X-Cache") != "HIT" || head.Body.Len() != 0 {
+		t.Fatalf("head: %v %q", head.Header(), head.Body.String())
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("handler calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestCacheVaryAndCacheControl(t *testing.T) {
+	var calls atomic.Int32
+	g := web.New()
+	g.Middlewares(Cache())
+	g.GET("/greet", func(c *web.Context) {
+		calls.Add(1)
+		c.Writer.Header().Set("Vary", "Accept-Language")
+		c.WriteString("hello " + c.Request.Header.Get("Accept-Language"))
+	})
+	g.GET("/private", func(c *web.Context) {
+		calls.Add(1)
+		c.Writer.Header().Set("Cache-Control", "private, max-age=60")
+	})
+	g.GET("/cookie", func(c *web.Context) {
+		calls.Add(1)
+		http.SetCookie(c.Writer, &http.Cookie{Name: "a", Value: "b"})
+	})
+
+	for range 2 {
+		if got := cacheReq(g, "GET", "/greet", "Accept-Language", "en").Body.String(); got != "hello en" {
+			t.Fatalf("en = %q", got)
+		}
+		if got := cacheReq(g, "GET", "/greet", "Accept-Language", "zh").Body.String(); got != "hello zh" {
+			t.Fatalf("zh = %q", got)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("vary: calls = %d, want 2", calls.Load())
+	}
+
+	calls.Store(0)
+	for range 2 {
+		cacheReq(g, "GET", "/private")
+		cacheReq(g, "GET", "/cookie")
+	}
+	if calls.Load() != 4 {
+		t.Fatalf("uncacheable: calls = %d, want 4", calls.Load())
+	}
+
+	// 请求 no-cache 强制回源并刷新缓存，no-store 完全绕过
+	calls.Store(0)
+	if rec := cacheReq(g, "GET", "/greet", "Accept-Language", "en", "Cache-Control", "no-cache"); rec.Header().Get("X-Cache") != "MISS" {
+		t.Fatalf("no-cache: %v", rec.Header())
+	}
+	if rec := cacheReq(g, "GET", "/greet", "Accept-Language", "en", "Cache-Control", "no-store"); rec.Header().Get("X-Cache") != "" {
+		t.Fatalf("no-store: %v", rec.Header())
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("request cache-control: calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int32
+	g := web.New()
+	g.Middlewares(Cache(CacheOption{TTL: 30 * time.Millisecond, StaleWhileRevalidate: time.Minute}))
+	g.GET("/v", func(c *web.Context) {
+		fmt.Fprintf(c.Writer, "v%d", version.Add(1))
+	})
+
+	cacheReq(g, "GET", "/v")
+	time.Sleep(50 * time.Millisecond)
+	stale := cacheReq(g, "GET", "/v")
+	if stale.Header().Get("X-Cache") != "STALE" || stale.Body.String() != "v1" {
+		t.Fatalf("stale: %s %q", stale.Header().Get("X-Cache"), stale.Body.String())
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		rec := cacheReq(g, "GET", "/v")
+		if rec.Header().Get("X-Cache") == "HIT" && rec.Body.String() == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("not revalidated: %s %q", rec.Header().Get("X-Cache"), rec.Body.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if version.Load() != 2 {
+		t.Fatalf("handler calls = %d, want 2", version.Load())
+	}
+}
+
+func TestCacheRevalidateSkipsOuterMiddleware(t *testing.T) {
+	var outer, inner, handler atomic.Int32
+	g := web.New()
+	g.Middlewares(
+		func(c *web.Context) { outer.Add(1) },
+		Cache(CacheOption{TTL: 30 * time.Millisecond, StaleWhileRevalidate: time.Minute}),
+		func(c *web.Context) { inner.Add(1) },
+	)
+	g.GET("/v/#id", func(c *web.Context) {
+		fmt.Fprintf(c.Writer, "%s-%d", c.GetUrlPathParam("id"), handler.Add(1))
+	})
+
+	requests := int32(0)
+	get := func() string {
+		requests++
+		return cacheReq(g, "GET", "/v/7").Body.String()
+	}
+	get()
+	time.Sleep(50 * time.Millisecond)
+	get()
+	deadline := time.Now().Add(time.Second)
+	for get() != "7-2" {
+		if time.Now().After(deadline) {
+			t.Fatal("not revalidated")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// 后台刷新只重跑缓存之后的中间件与 handler，路由参数沿用原请求
+	if outer.Load() != requests || inner.Load() != 2 || handler.Load() != 2 {
+		t.Fatalf("outer=%d (requests %d) inner=%d handler=%d", outer.Load(), requests, inner.Load(), handler.Load())
+	}
+}
+
+func TestCacheBypassesCookieRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       CacheOption
+		wantCalls int32
+	}{
+		{"default bypass", CacheOption{}, 3},
+		{"opt in", CacheOption{CookieRequests: true}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			g := web.New()
+			g.Middlewares(Cache(tt.opt))
+			g.GET("/me", func(c *web.Context) {
+				calls.Add(1)
+				c.WriteString("hello")
+			})
+			cacheReq(g, "GET", "/me", "Cookie", "sid=alice")
+			cacheReq(g, "GET", "/me", "Cookie", "sid=bob")
+			cacheReq(g, "GET", "/me", "Cookie", "sid=bob")
+			if calls.Load() != tt.wantCalls {
+				t.Fatalf("handler calls = %d, want %d", calls.Load(), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	var calls atomic.Int32
+	rc := NewResponseCache()
+	g := web.New()
+	g.Middlewares(rc.Handler())
+	g.GET("/posts/#id", func(c *web.Context) {
+		calls.Add(1)
+		SetCacheTags(c, "posts", "post:"+c.GetUrlPathParam("id"))
+		c.WriteString("post")
+	})
+	g.POST("/posts/#id", func(c *web.Context) {})
+	g.GET("/about", func(c *web.Context) {
+		calls.Add(1)
+		c.WriteString("about")
+	})
+
+	warm := func() {
+		for _, p := range []string{"/posts/1", "/posts/2", "/about"} {
+			if rec := cacheReq(g, "GET", p); rec.Header().Get("Cache-Tag") != "" {
+				t.Fatalf("Cache-Tag leaked: %v", rec.Header())
+			}
+		}
+	}
+	expectCalls := func(want int32) {
+		t.Helper()
+		if got := calls.Swap(0); got != want {
+			t.Fatalf("handler calls = %d, want %d", got, want)
+		}
+	}
+	warm()
+	expectCalls(3)
+
+	if n := rc.PurgeTag("post:1"); n != 1 {
+		t.Fatalf("PurgeTag = %d", n)
+	}
+	warm()
+	expectCalls(1)
+
+	if n := rc.PurgeRoute("/posts/#id"); n != 2 {
+		t.Fatalf("PurgeRoute = %d", n)
+	}
+	warm()
+	expectCalls(2)
+
+	cacheReq(g, "POST", "/posts/2")
+	warm()
+	expectCalls(1)
+
+	if n := rc.PurgeKey("example.com/about"); n != 1 {
+		t.Fatalf("PurgeKey = %d", n)
+	}
+	if n := rc.PurgeAll(); n != 2 {
+		t.Fatalf("PurgeAll = %d", n)
+	}
+	warm()
+	expectCalls(3)
+}
+
+func TestCacheLargeBodyPassthrough(t *testing.T) {
+	var calls atomic.Int32
+	g := web.New()
+	g.Middlewares(Cache(CacheOption{MaxBodySize: 16}))
+	body := strings.Repeat("x", 64)
+	g.GET("/big", func(c *web.Context) {
+		calls.Add(1)
+		c.WriteString(body[:8])
+		c.WriteString(body[8:])
+	})
+	for range 2 {
+		if got := cacheReq(g, "GET", "/big").Body.String(); got != body {
+			t.Fatalf("body = %q", got)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
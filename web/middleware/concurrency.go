@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type ConcurrencyLimitOption struct {
+	// Wait 无空位时最多排队等待的时长；0 表示立即拒绝。
+	Wait time.Duration
+	// OnLimited 排队超时或请求取消时调用；nil 走默认 503 + Retry-After。
+	OnLimited web.HandlerFunc
+}
+
+// ConcurrencyLimit 限制同时处理中的请求数，用于保护慢接口或下游资源。
+// 空位在后续链返回（包括 panic）后释放。
+func ConcurrencyLimit(n int, opts ...ConcurrencyLimitOption) web.HandlerFunc {
+	if n <= 0 {
+		panic("[web] ConcurrencyLimit: n must be positive")
+	}
+	opt := ConcurrencyLimitOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.OnLimited == nil {
+		opt.OnLimited = defaultOnConcurrencyLimited
+	}
+	sem := make(chan struct{}, n)
+
+	return func(c *web.Context) {
+		if !acquire(c, sem, opt.Wait) {
+			c.Stop()
+			opt.OnLimited(c)
+			return
+		}
+		defer func() { <-sem }()
+		c.Next()
+	}
+}
+
+func acquire(c *web.Context, sem chan struct{}, wait time.Duration) bool {
+	select {
+	case sem <- struct{}{}:
+		return true
+	default:
+	}
+	if wait <= 0 {
+		return false
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Done():
+		return false
+	}
+}
+
+func defaultOnConcurrencyLimited(c *web.Context) {
+	c.Writer.Header().Set("Retry-After", "1")
+	http.Error(c.Writer, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type CORSOption struct {
+	// AllowOrigins 允许的来源：精确值、"*" 或 "https://*.example.com" 形式的子域通配。
+	// 为空且 AllowOriginFunc 为 nil 时允许全部。
+	AllowOrigins []string
+	// AllowOriginFunc 自定义判定，优先于 AllowOrigins。
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods 预检返回的方法；nil 走默认 GET/POST/PUT/PATCH/DELETE/HEAD。
+	AllowMethods []string
+	// AllowHeaders 预检返回的请求头；nil 时回显 Access-Control-Request-Headers。
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	// MaxAge 预检缓存时长；0 不下发。
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodHead,
+}
+
+// CORS 处理跨域：普通请求补齐响应头后继续；预检请求（OPTIONS + Access-Control-Request-Method）
+// 直接 204 短路。GOweb 对未注册 OPTIONS 的路由会自动应答并仍走中间件链，因此无需手动注册 OPTIONS。
+// AllowCredentials 时不会返回 "*"，而是回显具体 Origin。
+func CORS(opts ...CORSOption) web.HandlerFunc {
+	opt := CORSOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	methods := opt.AllowMethods
+	if methods == nil {
+		methods = defaultCORSMethods
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opt.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(opt.ExposeHeaders, ", ")
+	maxAge := ""
+	if opt.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(opt.MaxAge / time.Second))
+	}
+	allowAll := opt.AllowOriginFunc == nil && (len(opt.AllowOrigins) == 0 || containsString(opt.AllowOrigins, "*"))
+
+	allowed := func(origin string) bool {
+		if opt.AllowOriginFunc != nil {
+			return opt.AllowOriginFunc(origin)
+		}
+		if allowAll {
+			return true
+		}
+		for _, o := range opt.AllowOrigins {
+			if matchOrigin(o, origin) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *web.Context) {
+		origin := c.Request.Header.Get("Origin")
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions &&
+			c.Request.Header.Get("Access-Control-Request-Method") != ""
+		if !allowed(origin) {
+			if preflight {
+				c.Writer.WriteHeader(http.StatusForbidden)
+				c.Stop()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll && !opt.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opt.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				h.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if reqHeaders := c.Request.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+			h.Set("Access-Control-Allow-Headers", reqHeaders)
+		}
+		if maxAge != "" {
+			h.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.Writer.WriteHeader(http.StatusNoContent)
+		c.Stop()
+	}
+}
+
+// matchOrigin 支持 scheme://*.domain 通配，通配只匹配子域不匹配裸域。
+func matchOrigin(pattern, origin string) bool {
+	if pattern == origin {
+		return true
+	}
+	scheme, rest, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	prefix := scheme + "://"
+	if !strings.HasPrefix(origin, prefix) {
+		return false
+	}
+	host := origin[len(prefix):]
+	return strings.HasSuffix(host, "."+rest) && len(host) > len(rest)+1
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func TestRequestIDGeneratedAndPropagated(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(RequestID())
+	var seen string
+	g.GET("/x", func(c *web.Context) { seen = GetRequestID(c) })
+
+	rec := runReq(t, g, "GET", "/x", "")
+	id := rec.Header().Get("X-Request-ID")
+	if len(id) != 32 || id != seen {
+		t.Fatalf("header=%q ctx=%q", id, seen)
+	}
+}
+
+func TestRequestIDTrustIncoming(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(RequestID(RequestIDOption{TrustIncoming: true}))
+	g.GET("/x", func(c *web.Context) {})
+
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Request-ID"); got != "abc-123" {
+		t.Fatalf("got %q", got)
+	}
+
+	req.Header.Set("X-Request-ID", "bad id\n")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if got := rec.Header().Get("X-Request-ID"); got == "bad id\n" || got == "" {
+		t.Fatalf("invalid incoming id accepted: %q", got)
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(RealIP(RealIPOption{TrustedProxies: []string{"10.0.0.0/8"}}))
+	var ip string
+	g.GET("/x", func(c *web.Context) { ip = ClientIP(c) })
+
+	cases := []struct {
+		remote, xff, want string
+	}{
+		// 直连不可信：忽略转发头。
+		{"1.2.3.4:1000", "9.9.9.9", "1.2.3.4"},
+		// 可信代理：自右向左跳过可信段。
+		{"10.0.0.1:1000", "6.6.6.6, 7.7.7.7, 10.0.0.2", "7.7.7.7"},
+		{"10.0.0.1:1000", "", "10.0.0.1"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.RemoteAddr = tc.remote
+		if tc.xff != "" {
+			req.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		g.ServeHTTP(httptest.NewRecorder(), req)
+		if ip != tc.want {
+			t.Fatalf("remote=%s xff=%q got %q want %q", tc.remote, tc.xff, ip, tc.want)
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	g := web.New()
+	g.HeadMiddleware(RequestID())
+	g.Middlewares(AccessLog(AccessLogOption{Logger: logger}))
+	g.GET("/x", func(c *web.Context) {
+		c.Writer.WriteHeader(http.StatusTeapot)
+		c.WriteString("hi")
+	})
+	runReq(t, g, "GET", "/x", "")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("log %q: %v", buf.String(), err)
+	}
+	if entry["status"] != float64(418) || entry["size"] != float64(2) || entry["level"] != "WARN" {
+		t.Fatalf("entry = %v", entry)
+	}
+	if entry["path"] != "/x" || entry["request_id"] == "" {
+		t.Fatalf("entry = %v", entry)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	g := web.New()
+	var got any
+	var after bool
+	g.Middlewares(Recovery(RecoveryOption{Handler: func(c *web.Context, rec any, stack []byte) {
+		got = rec
+		if len(stack) == 0 {
+			t.Error("empty stack")
+		}
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+	}}))
+	g.HeadMiddleware(func(c *web.Context) {})
+	g.GET("/x", func(c *web.Context) { panic("boom") })
+	g.FootMiddleware(func(c *web.Context) { after = true })
+
+	rec := runReq(t, g, "GET", "/x", "")
+	if got != "boom" || rec.Code != http.StatusInternalServerError || after {
+		t.Fatalf("got=%v code=%d after=%v", got, rec.Code, after)
+	}
+}
+
+func TestCORSPreflightWithoutOptionsRoute(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(CORS(CORSOption{
+		AllowOrigins:     []string{"https://*.example.com"},
+		AllowCredentials: true,
+		MaxAge:           time.Minute,
+	}))
+	g.GET("/x", func(c *web.Context) { c.WriteString("ok") })
+
+	req := httptest.NewRequest("OPTIONS", "/x", nil)
+	req.Header.Set("Origin", "https://a.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	req.Header.Set("Access-Control-Request-Headers", "X-Token")
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("code = %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://a.example.com" ||
+		h.Get("Access-Control-Allow-Credentials") != "true" ||
+		h.Get("Access-Control-Allow-Headers") != "X-Token" ||
+		h.Get("Access-Control-Max-Age") != "60" {
+		t.Fatalf("headers = %v", h)
+	}
+
+	req = httptest.NewRequest("GET", "/x", nil)
+	req.Header.Set("Origin", "https://example.com")
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Body.String() != "ok" {
+		t.Fatalf("bare domain should not match: %v", rec.Header())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(Timeout(20 * time.Millisecond))
+	var ran bool
+	g.GET("/slow", func(c *web.Context) {
+		select {
+		case <-c.Done():
+		case <-time.After(time.Second):
+			t.Error("ctx not cancelled")
+		}
+	})
+	g.FootMiddleware(func(c *web.Context) { ran = true })
+	g.GET("/fast", TimeoutHandler(time.Second, func(c *web.Context) { c.WriteString("ok") }))
+
+	rec := runReq(t, g, "GET", "/slow", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("code = %d", rec.Code)
+	}
+	if ran {
+		t.Fatal("footer should be skipped after timeout")
+	}
+	if rec := runReq(t, g, "GET", "/fast", ""); rec.Body.String() != "ok" {
+		t.Fatalf("body = %q", rec.Body.String())
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(BodyLimit(4))
+	var readErr error
+	g.POST("/x", func(c *web.Context) { _, readErr = c.ReadAll() })
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("POST", "/x", strings.NewReader("123456")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("code = %d", rec.Code)
+	}
+
+	// 未知长度时由 MaxBytesReader 在读取阶段拦截。
+	req := httptest.NewRequest("POST", "/x", io.MultiReader(strings.NewReader("123"), strings.NewReader("456")))
+	req.ContentLength = -1
+	g.ServeHTTP(httptest.NewRecorder(), req)
+	var mbe *http.MaxBytesError
+	if !errors.As(readErr, &mbe) {
+		t.Fatalf("readErr = %v", readErr)
+	}
+}
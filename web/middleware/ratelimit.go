@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type RateLimitOption struct {
+	// KeyFunc 计算限流维度；nil 按 ClientIP。返回空串表示该请求不参与限流。
+	KeyFunc func(c *web.Context) string
+	// Store 状态存储；nil 时每个中间件独占一个 MemoryRateLimitStore。
+	Store RateLimitStore
+	// Prefix 多个限流器共享同一 Store 时用于区分 key。
+	Prefix string
+	// OnLimited 被限流时调用，此时 RateLimit-* 与 Retry-After 已写入；nil 走默认 429。
+	OnLimited web.HandlerFunc
+	// DisableHeaders 不下发 RateLimit-* 响应头（Retry-After 仍会下发）。
+	DisableHeaders bool
+}
+
+// KeyByIP 按 ClientIP 限流，配合 RealIP 中间件可识别代理后的真实地址。
+func KeyByIP(c *web.Context) string {
+	return ClientIP(c)
+}
+
+// KeyByHeader 按请求头限流，如 API Key；缺失该头的请求不参与限流。
+func KeyByHeader(name string) func(c *web.Context) string {
+	return func(c *web.Context) string {
+		return c.Request.Header.Get(name)
+	}
+}
+
+// TokenBucket 令牌桶限流：每秒补充 rate 个令牌，最多积攒 burst 个，允许短时突发。
+func TokenBucket(rate float64, burst int, opts ...RateLimitOption) web.HandlerFunc {
+	if rate <= 0 || burst <= 0 {
+		panic("[web] TokenBucket: rate and burst must be positive")
+	}
+	window := time.Duration(float64(burst) / rate * float64(time.Second))
+	return rateLimit(window, opts, func(c *web.Context, key string, store RateLimitStore) (RateLimitResult, error) {
+		return store.TakeToken(c, key, rate, burst)
+	})
+}
+
+// SlidingWindow 滑动窗口限流：任意 window 时长内最多 limit 次，窗口边界不会出现双倍突发。
+func SlidingWindow(limit int, window time.Duration, opts ...RateLimitOption) web.HandlerFunc {
+	if limit <= 0 || window <= 0 {
+		panic("[web] SlidingWindow: limit and window must be positive")
+	}
+	return rateLimit(window, opts, func(c *web.Context, key string, store RateLimitStore) (RateLimitResult, error) {
+		return store.TakeWindow(c, key, limit, window)
+	})
+}
+
+func rateLimit(window time.Duration, opts []RateLimitOption,
+	take func(c *web.Context, key string, store RateLimitStore) (RateLimitResult, error),
+) web.HandlerFunc {
+	opt := RateLimitOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.KeyFunc == nil {
+		opt.KeyFunc = KeyByIP
+	}
+	if opt.Store == nil {
+		opt.Store = NewMemoryRateLimitStore()
+	}
+	if opt.OnLimited == nil {
+		opt.OnLimited = defaultOnLimited
+	}
+	policyWindow := ";w=" + strconv.Itoa(ceilSeconds(window))
+
+	return func(c *web.Context) {
+		key := opt.KeyFunc(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+		res, err := take(c, opt.Prefix+key, opt.Store)
+		if err != nil {
+			// 存储故障时放行，避免限流组件拖垮整个服务
+			log.Printf("[web] ratelimit store error: %v", err)
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		if !opt.DisableHeaders {
+			h.Set("RateLimit-Policy", strconv.Itoa(res.Limit)+policyWindow)
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(max(res.Remaining, 0)))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		}
+		if res.Allowed {
+			c.Next()
+			return
+		}
+		h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+		c.Stop()
+		opt.OnLimited(c)
+	}
+}
+
+func defaultOnLimited(c *web.Context) {
+	http.Error(c.Writer, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rehtt/Kit/maps"
+)
+
+// RateLimitResult 一次限流判定的结果。
+type RateLimitResult struct {
+	Allowed bool
+	// Limit 策略上限：令牌桶为桶容量，滑动窗口为窗口内请求数。
+	Limit int
+	// Remaining 本次判定后剩余的额度。
+	Remaining int
+	// Reset 额度完全恢复所需时长。
+	Reset time.Duration
+	// RetryAfter 被拒绝时距离下次可能放行的时长。
+	RetryAfter time.Duration
+}
+
+// RateLimitStore 限流状态存储。接口按算法划分而不是暴露 get/set，
+// 同一 key 的“读取-判定-写回”需在实现内部原子完成（如 Redis 可用 Lua 脚本实现）。
+type RateLimitStore interface {
+	// TakeToken 令牌桶：每秒补充 rate 个令牌，容量 burst，取一个令牌。
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (RateLimitResult, error)
+	// TakeWindow 滑动窗口：任意 window 时长内最多 limit 次。
+	TakeWindow(ctx context.Context, key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore 基于 maps.ConcurrentMap 的进程内存储，条目按 TTL 自动淘汰。
+//
+// 状态按时间片（令牌桶为填满一桶的时长，滑动窗口为窗口长度）分 key 存放，
+// 每个条目只在创建时 Set 一次、此后原地更新，过期淘汰不会丢失仍在使用的状态。
+type MemoryRateLimitStore struct {
+	buckets *maps.ConcurrentMap[*gcraState]
+	windows *maps.ConcurrentMap[*atomic.Int64]
+	// 只保护条目创建，命中路径不加锁
+	createMu sync.Mutex
+}
+
+type gcraState struct {
+	mu sync.Mutex
+	// tat 理论到达时间（GCRA），桶满时 <= now
+	tat time.Time
+	// next 指向下一时间片的状态，跨片时旧引用据此跳转
+	next *gcraState
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: maps.NewConcurrentMap[*gcraState](),
+		windows: maps.NewConcurrentMap[*atomic.Int64](),
+	}
+}
+
+// TakeToken 以 GCRA 实现令牌桶，只需保存一个时间戳。
+func (s *MemoryRateLimitStore) TakeToken(_ context.Context, key string, rate float64, burst int) (RateLimitResult, error) {
+	now := time.Now()
+	interval := max(time.Duration(float64(time.Second)/rate), 1)
+	period := interval * time.Duration(burst)
+	epoch := now.UnixNano() / int64(period)
+	res := RateLimitResult{Limit: burst}
+
+	st := s.bucket(key, epoch, 2*period+time.Second)
+	st.mu.Lock()
+	for st.next != nil {
+		next := st.next
+		st.mu.Unlock()
+		st = next
+		st.mu.Lock()
+	}
+	defer st.mu.Unlock()
+
+	tat := st.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(interval)
+	if allowAt := newTat.Add(-period); now.Before(allowAt) {
+		res.Reset = tat.Sub(now)
+		res.RetryAfter = allowAt.Sub(now)
+		return res, nil
+	}
+	st.tat = newTat
+	res.Allowed = true
+	res.Remaining = int((now.Add(period).Sub(newTat)) / interval)
+	res.Reset = newTat.Sub(now)
+	return res, nil
+}
+
+func (s *MemoryRateLimitStore) bucket(key string, epoch int64, ttl time.Duration) *gcraState {
+	curKey := key + "|" + strconv.FormatInt(epoch, 10)
+	if st, ok := s.buckets.Get(curKey); ok {
+		return st
+	}
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+	if st, ok := s.buckets.Get(curKey); ok {
+		return st
+	}
+	st := &gcraState{}
+	// 承接上一时间片的 tat；更早的片即使存在，其 tat 也早已过去，等价于满桶
+	if prev, ok := s.buckets.Get(key + "|" + strconv.FormatInt(epoch-1, 10)); ok {
+		prev.mu.Lock()
+		for prev.next != nil {
+			next := prev.next
+			prev.mu.Unlock()
+			prev = next
+			prev.mu.Lock()
+		}
+		st.tat = prev.tat
+		prev.next = st
+		prev.mu.Unlock()
+	}
+	s.buckets.Set(curKey, st, ttl)
+	return st
+}
+
+// TakeWindow 以“上一窗口按剩余比例加权 + 当前窗口计数”近似滑动窗口。
+func (s *MemoryRateLimitStore) TakeWindow(_ context.Context, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	now := time.Now()
+	epoch := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - epoch*int64(window))
+	weight := 1 - float64(elapsed)/float64(window)
+	res := RateLimitResult{Limit: limit, Reset: window - elapsed}
+
+	var prev int64
+	if p, ok := s.windows.Get(key + "|" + strconv.FormatInt(epoch-1, 10)); ok {
+		prev = p.Load()
+	}
+	cur := s.window(key, epoch, 2*window+time.Second)
+	for {
+		n := cur.Load()
+		estimate := float64(prev)*weight + float64(n)
+		if estimate+1 > float64(limit) {
+			res.RetryAfter = windowRetryAfter(prev, n, limit, window, elapsed)
+			return res, nil
+		}
+		if cur.CompareAndSwap(n, n+1) {
+			res.Allowed = true
+			res.Remaining = int(math.Floor(float64(limit) - estimate - 1))
+			return res, nil
+		}
+	}
+}
+
+// windowRetryAfter 计算上一窗口权重衰减到足以放行一次所需的时长；
+// 当前窗口已满时只能等到下个窗口。
+func windowRetryAfter(prev, cur int64, limit int, window, elapsed time.Duration) time.Duration {
+	room := int64(limit) - 1 - cur
+	if room < 0 || prev == 0 {
+		return window - elapsed
+	}
+	need := time.Duration(math.Ceil(float64(window) * float64(prev-room) / float64(prev)))
+	if need <= elapsed {
+		return 0
+	}
+	return need - elapsed
+}
+
+func (s *MemoryRateLimitStore) window(key string, epoch int64, ttl time.Duration) *atomic.Int64 {
+	curKey := key + "|" + strconv.FormatInt(epoch, 10)
+	if n, ok := s.windows.Get(curKey); ok {
+		return n
+	}
+	s.createMu.Lock()
+	defer s.createMu.Unlock()
+	if n, ok := s.windows.Get(curKey); ok {
+		return n
+	}
+	n := new(atomic.Int64)
+	s.windows.Set(curKey, n, ttl)
+	return n
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func TestTokenBucket(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(TokenBucket(1, 2))
+	g.GET("/x", func(c *web.Context) { c.WriteString("ok") })
+
+	for i, want := range []string{"1", "0"} {
+		rec := runReq(t, g, "GET", "/x", "")
+		if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Remaining") != want {
+			t.Fatalf("#%d code=%d remaining=%q", i, rec.Code, rec.Header().Get("RateLimit-Remaining"))
+		}
+	}
+	rec := runReq(t, g, "GET", "/x", "")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("code=%d retry=%q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Policy") != "2;w=2" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+
+	// 不同 IP 互不影响。
+	req := httptest.NewRequest("GET", "/x", nil)
+	req.RemoteAddr = "5.6.7.8:1"
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("other ip code=%d", rec.Code)
+	}
+}
+
+func TestSlidingWindowByHeader(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(SlidingWindow(2, time.Minute, RateLimitOption{KeyFunc: KeyByHeader("X-API-Key")}))
+	g.GET("/x", func(c *web.Context) {})
+
+	do := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/x", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		g.ServeHTTP(rec, req)
+		return rec
+	}
+	do("a")
+	do("a")
+	rec := do("a")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("code=%d headers=%v", rec.Code, rec.Header())
+	}
+	if do("b").Code != http.StatusOK {
+		t.Fatal("key b should not be limited")
+	}
+	// 缺少 key 的请求不参与限流。
+	if rec := do(""); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Fatalf("keyless code=%d headers=%v", rec.Code, rec.Header())
+	}
+}
+
+func TestMemoryRateLimitStoreConcurrent(t *testing.T) {
+	s := NewMemoryRateLimitStore()
+	var mu sync.Mutex
+	var allowed, windowAllowed int
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r1, _ := s.TakeToken(context.Background(), "k", 0.001, 10)
+			r2, _ := s.TakeWindow(context.Background(), "k", 10, time.Hour)
+			mu.Lock()
+			defer mu.Unlock()
+			if r1.Allowed {
+				allowed++
+			}
+			if r2.Allowed {
+				windowAllowed++
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 || windowAllowed != 10 {
+		t.Fatalf("token=%d window=%d", allowed, windowAllowed)
+	}
+}
+
+func TestWindowRetryAfter(t *testing.T) {
+	// 上一窗口 10 次、当前 0 次、上限 10：权重需降到 0.9 以下，即窗口过去 10% 后放行。
+	if got := windowRetryAfter(10, 0, 10, 10*time.Second, 0); got != time.Second {
+		t.Fatalf("got %v", got)
+	}
+	// 当前窗口已满只能等下个窗口。
+	if got := windowRetryAfter(0, 10, 10, 10*time.Second, 3*time.Second); got != 7*time.Second {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	g := web.New()
+	g.HeadMiddleware(ConcurrencyLimit(1))
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	g.GET("/x", func(c *web.Context) {
+		entered <- struct{}{}
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/x", nil))
+	}()
+	<-entered
+
+	rec := runReq(t, g, "GET", "/x", "")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("code=%d", rec.Code)
+	}
+	close(release)
+	<-done
+
+	go func() { <-entered }()
+	if rec := runReq(t, g, "GET", "/x", ""); rec.Code != http.StatusOK {
+		t.Fatalf("after release code=%d", rec.Code)
+	}
+}
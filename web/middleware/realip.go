@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type RealIPOption struct {
+	// TrustedProxies 可信代理的 IP 或 CIDR；只有直连地址命中时才解析转发头。
+	// nil 表示不信任任何代理，始终使用 RemoteAddr。
+	TrustedProxies []string
+	// Headers 按顺序尝试的转发头；nil 走默认 X-Forwarded-For、X-Real-IP。
+	Headers []string
+}
+
+var defaultRealIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+type realIPKey struct{}
+
+// RealIP 从可信代理的转发头中解析客户端 IP 并挂到 ctx，经 ClientIP 读取。
+// X-Forwarded-For 自右向左跳过可信代理，取第一个不可信地址，避免客户端伪造最左值。
+func RealIP(opts ...RealIPOption) web.HandlerFunc {
+	opt := RealIPOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	headers := opt.Headers
+	if headers == nil {
+		headers = defaultRealIPHeaders
+	}
+	trusted := parsePrefixes(opt.TrustedProxies)
+
+	return func(c *web.Context) {
+		remote := remoteIP(c.Request.RemoteAddr)
+		ip := remote
+		if isTrusted(trusted, remote) {
+			for _, h := range headers {
+				if v := fromHeader(c.Request.Header.Values(h), trusted); v != "" {
+					ip = v
+					break
+				}
+			}
+		}
+		c.SetContextValue(realIPKey{}, ip)
+		c.Next()
+	}
+}
+
+// ClientIP 返回 RealIP 解析的地址；未启用 RealIP 时回退到 RemoteAddr。
+func ClientIP(c *web.Context) string {
+	if ip := GetRealIP(c); ip != "" {
+		return ip
+	}
+	return remoteIP(c.Request.RemoteAddr)
+}
+
+// GetRealIP 取 RealIP 中间件写入的地址；未启用时返回空串。
+func GetRealIP(ctx context.Context) string {
+	ip, _ := ctx.Value(realIPKey{}).(string)
+	return ip
+}
+
+func parsePrefixes(list []string) []netip.Prefix {
+	out := make([]netip.Prefix, 0, len(list))
+	for _, s := range list {
+		if p, err := netip.ParsePrefix(s); err == nil {
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			panic("[web] RealIP: invalid trusted proxy " + s)
+		}
+		out = append(out, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return out
+}
+
+func isTrusted(trusted []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// fromHeader 合并同名多行头后自右向左查找第一个不可信的合法 IP。
+func fromHeader(values []string, trusted []netip.Prefix) string {
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, strings.Split(v, ",")...)
+	}
+	for i := len(parts) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(parts[i])
+		if _, err := netip.ParseAddr(ip); err != nil {
+			continue
+		}
+		if !isTrusted(trusted, ip) {
+			return ip
+		}
+	}
+	return ""
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const defaultStackSize = 64 << 10
+
+type RecoveryOption struct {
+	// Handler 自定义处理；nil 时打印堆栈并在 header 未发出时写 500。
+	Handler func(c *web.Context, rec any, stack []byte)
+	// StackSize 堆栈缓冲字节数；0 走默认 64KB。
+	StackSize int
+}
+
+// Recovery 在中间件层捕获 panic 并附带堆栈，之后短路剩余链。
+// 与 GOweb.OnPanic 的区别在于可按分组挂载并拿到 request 级上下文（如 RequestID）；
+// http.ErrAbortHandler 仍向上抛给 stdlib。
+func Recovery(opts ...RecoveryOption) web.HandlerFunc {
+	opt := RecoveryOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.StackSize <= 0 {
+		opt.StackSize = defaultStackSize
+	}
+
+	return func(c *web.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			buf := make([]byte, opt.StackSize)
+			stack := buf[:runtime.Stack(buf, false)]
+			c.Stop()
+			if opt.Handler != nil {
+				opt.Handler(c, rec, stack)
+				return
+			}
+			defaultRecover(c, rec, stack)
+		}()
+		c.Next()
+	}
+}
+
+func defaultRecover(c *web.Context, rec any, stack []byte) {
+	if id := GetRequestID(c); id != "" {
+		log.Printf("[web] panic recovered %s %s request_id=%s: %v\n%s",
+			c.Request.Method, c.Request.URL.Path, id, rec, stack)
+	} else {
+		log.Printf("[web] panic recovered %s %s: %v\n%s",
+			c.Request.Method, c.Request.URL.Path, rec, stack)
+	}
+	if rw, ok := c.Writer.(web.ResponseWriter); ok && rw.Written() {
+		return
+	}
+	http.Error(c.Writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const defaultRequestIDHeader = "X-Request-ID"
+
+type RequestIDOption struct {
+	// Header 读取 / 回写的头名；空走默认 X-Request-ID。
+	Header string
+	// Generator 生成新 ID；nil 走默认 16 字节随机 hex。
+	Generator func() string
+	// TrustIncoming 为 true 时沿用上游传入的合法 ID，便于跨服务串联。
+	TrustIncoming bool
+}
+
+type requestIDKey struct{}
+
+// RequestID 为每个请求分配 ID：写入响应头并挂到 ctx，下游可经 GetRequestID 读取，
+// 也可把 *web.Context 直接作为 context.Context 透传给出站调用。
+func RequestID(opts ...RequestIDOption) web.HandlerFunc {
+	opt := RequestIDOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Header == "" {
+		opt.Header = defaultRequestIDHeader
+	}
+	if opt.Generator == nil {
+		opt.Generator = newRequestID
+	}
+
+	return func(c *web.Context) {
+		id := ""
+		if opt.TrustIncoming {
+			if in := c.Request.Header.Get(opt.Header); validRequestID(in) {
+				id = in
+			}
+		}
+		if id == "" {
+			id = opt.Generator()
+		}
+		c.Writer.Header().Set(opt.Header, id)
+		c.SetContextValue(requestIDKey{}, id)
+		c.Next()
+	}
+}
+
+// GetRequestID 取 RequestID 中间件写入的 ID；未启用时返回空串。
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID 把 ID 挂到任意 ctx，便于在 goroutine / 出站请求中继续传递。
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// validRequestID 限制长度与字符集，防止日志注入。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
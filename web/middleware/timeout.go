@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type TimeoutOption struct {
+	// OnTimeout 超时且尚未写出响应时调用；nil 走默认 503。
+	OnTimeout web.HandlerFunc
+}
+
+// Timeout 给后续链的 ctx 设置 deadline：到期后 ctx.Done() 触发、剩余 handler 被短路，
+// 正在执行的 handler 需自行观察 ctx.Done()（*Context 为池化资源，不能另起 goroutine 强行中断）。
+// 链结束时已超时且未写出响应，则写 503。
+func Timeout(d time.Duration, opts ...TimeoutOption) web.HandlerFunc {
+	onTimeout := defaultOnTimeout
+	if len(opts) > 0 && opts[0].OnTimeout != nil {
+		onTimeout = opts[0].OnTimeout
+	}
+	return func(c *web.Context) {
+		runWithTimeout(c, d, onTimeout, c.Next)
+	}
+}
+
+// TimeoutHandler 为单个路由 handler 包一层超时，适合只有个别慢接口的场景。
+func TimeoutHandler(d time.Duration, h web.HandlerFunc, opts ...TimeoutOption) web.HandlerFunc {
+	onTimeout := defaultOnTimeout
+	if len(opts) > 0 && opts[0].OnTimeout != nil {
+		onTimeout = opts[0].OnTimeout
+	}
+	return func(c *web.Context) {
+		runWithTimeout(c, d, onTimeout, func() { h(c) })
+	}
+}
+
+func runWithTimeout(c *web.Context, d time.Duration, onTimeout web.HandlerFunc, next func()) {
+	parent := c.Context
+	ctx, cancel := context.WithTimeout(parent, d)
+	c.Context = ctx
+	defer func() {
+		cancel()
+		c.Context = parent
+	}()
+
+	next()
+
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	if rw, ok := c.Writer.(web.ResponseWriter); ok && rw.Written() {
+		return
+	}
+	onTimeout(c)
+}
+
+func defaultOnTimeout(c *web.Context) {
+	http.Error(c.Writer, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
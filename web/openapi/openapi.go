@@ -0,0 +1,301 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/Rehtt/Kit/web"
+	"gopkg.in/yaml.v3"
+)
+
+const Version = "3.1.0"
+
+// Document 是 OpenAPI 3.1 文档的子集，仅覆盖由路由表推导得出的部分。
+type Document struct {
+	OpenAPI    string               `json:"openapi" yaml:"openapi"`
+	Info       Info                 `json:"info" yaml:"info"`
+	Servers    []Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths" yaml:"paths"`
+	Components *Components          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url" yaml:"url"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// PathItem 以小写方法名为 key。
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses" yaml:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Option 调整生成结果。
+type Option func(*generator)
+
+// WithServers 写入 servers 字段。
+func WithServers(servers ...Server) Option {
+	return func(gen *generator) {
+		gen.doc.Servers = append(gen.doc.Servers, servers...)
+	}
+}
+
+// WithFilter 只保留 keep 返回 true 的路由，常用于隐藏文档自身或内部接口。
+func WithFilter(keep func(web.RouterInfo) bool) Option {
+	return func(gen *generator) {
+		gen.filter = keep
+	}
+}
+
+type generator struct {
+	doc    *Document
+	filter func(web.RouterInfo) bool
+	schema *schemaBuilder
+}
+
+// anyMethods 为 ANY 路由展开的方法集。
+var anyMethods = []string{"get", "post", "put", "patch", "delete"}
+
+// Generate 遍历 g.List() 生成文档：`#name` / `#name:type` 转为 `{name}`，
+// `#...` 转为 `{wildcard}`（OpenAPI 无多段参数，仅作标注）。
+// 路由名作为 operationId；同一名字覆盖多个方法（如 ANY 路由）时追加 `_get` 等方法后缀。
+func Generate(g *web.GOweb, info Info, opts ...Option) *Document {
+	gen := &generator{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]*PathItem),
+		},
+		schema: newSchemaBuilder(),
+	}
+	for _, opt := range opts {
+		opt(gen)
+	}
+	var routes []web.RouterInfo
+	// nameUses 统计每个路由名覆盖的 operation 数，用于保持 operationId 唯一。
+	nameUses := make(map[string]int)
+	for _, route := range g.List() {
+		if gen.filter != nil && !gen.filter(route) {
+			continue
+		}
+		routes = append(routes, route)
+		if route.Option.Name != "" {
+			nameUses[route.Option.Name] += len(routeMethods(route))
+		}
+	}
+	for _, route := range routes {
+		path, params := convertPath(route.Path)
+		item := gen.doc.Paths[path]
+		if item == nil {
+			item = &PathItem{}
+			gen.doc.Paths[path] = item
+		}
+		for _, m := range routeMethods(route) {
+			op := gen.operation(m, route.Option, params)
+			if nameUses[route.Option.Name] > 1 {
+				op.OperationID += "_" + m
+			}
+			// /users/#id 与 /users/#id:int 同为 /users/{id}：保留先出现的 operation，路径参数合并为 anyOf。
+			if prev := (*item)[m]; prev != nil {
+				mergePathParams(prev, op)
+				continue
+			}
+			(*item)[m] = op
+		}
+	}
+	if len(gen.schema.components) > 0 {
+		gen.doc.Components = &Components{Schemas: gen.schema.components}
+	}
+	return gen.doc
+}
+
+// routeMethods 返回路由对应的小写方法名，ANY 展开为 anyMethods。
+func routeMethods(route web.RouterInfo) []string {
+	if route.Method == web.ANY {
+		return anyMethods
+	}
+	return []string{strings.ToLower(route.Method)}
+}
+
+// mergePathParams 把 src 的路径参数约束并入 dst：同名参数 schema 不同时改为 anyOf。
+// 参数可能被 ANY 展开的多个 operation 共享，因此替换而非原地修改。
+func mergePathParams(dst, src *Operation) {
+	for _, sp := range src.Parameters {
+		if sp.In != "path" {
+			continue
+		}
+		for i, dp := range dst.Parameters {
+			if dp.In != "path" || dp.Name != sp.Name || reflect.DeepEqual(dp.Schema, sp.Schema) {
+				continue
+			}
+			merged := *dp
+			if len(dp.Schema.AnyOf) > 0 {
+				merged.Schema = &Schema{AnyOf: append(slices.Clone(dp.Schema.AnyOf), sp.Schema)}
+			} else {
+				merged.Schema = &Schema{AnyOf: []*Schema{dp.Schema, sp.Schema}}
+			}
+			dst.Parameters[i] = &merged
+		}
+	}
+}
+
+func (gen *generator) operation(method string, opt web.HandlerOpt, pathParams []*Parameter) *Operation {
+	op := &Operation{
+		OperationID: opt.Name,
+		Summary:     opt.Summary,
+		Description: opt.Description,
+		Tags:        opt.Tags,
+		Deprecated:  opt.Deprecated,
+		Responses:   map[string]*Response{},
+	}
+	// 路径参数以路由模式为准，Request 中的同名 path 字段只补充文档不重复声明。
+	seen := make(map[string]bool, len(pathParams))
+	for _, p := range pathParams {
+		op.Parameters = append(op.Parameters, p)
+		seen["path:"+p.Name] = true
+	}
+	if opt.Request != nil {
+		params, body := gen.schema.request(opt.Request, method)
+		for _, p := range params {
+			if seen[p.In+":"+p.Name] {
+				continue
+			}
+			seen[p.In+":"+p.Name] = true
+			op.Parameters = append(op.Parameters, p)
+		}
+		op.RequestBody = body
+	}
+	resp := &Response{Description: http.StatusText(http.StatusOK)}
+	if opt.Response != nil {
+		resp.Content = map[string]*MediaType{
+			"application/json": {Schema: gen.schema.of(opt.Response)},
+		}
+	}
+	op.Responses["200"] = resp
+	return op
+}
+
+// convertPath 把 "/users/#id:int/#..." 转为 "/users/{id}/{wildcard}" 并产出路径参数。
+func convertPath(p string) (string, []*Parameter) {
+	if p == "/" {
+		return p, nil
+	}
+	var params []*Parameter
+	segs := strings.Split(strings.Trim(p, "/"), "/")
+	for i, seg := range segs {
+		if !strings.HasPrefix(seg, "#") {
+			continue
+		}
+		if seg == "#..." {
+			segs[i] = "{wildcard}"
+			params = append(params, &Parameter{Name: "wildcard", In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			continue
+		}
+		name, spec, _ := strings.Cut(seg[1:], ":")
+		segs[i] = "{" + name + "}"
+		params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: constraintSchema(spec)})
+	}
+	return "/" + strings.Join(segs, "/"), params
+}
+
+// constraintSchema 把路由参数约束映射为 schema。
+func constraintSchema(spec string) *Schema {
+	switch spec {
+	case "int":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "uint":
+		zero := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero}
+	case "float":
+		return &Schema{Type: "number", Format: "double"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "uuid":
+		return &Schema{Type: "string", Format: "uuid"}
+	case "alpha":
+		return &Schema{Type: "string", Pattern: "^[A-Za-z]+$"}
+	case "alnum":
+		return &Schema{Type: "string", Pattern: "^[A-Za-z0-9]+$"}
+	}
+	if strings.HasPrefix(spec, "regex(") && strings.HasSuffix(spec, ")") {
+		return &Schema{Type: "string", Pattern: "^(?:" + spec[len("regex("):len(spec)-1] + ")$"}
+	}
+	return &Schema{Type: "string"}
+}
+
+// Handler 返回可挂载的文档 handler，每次请求按当前路由表重新生成（兼容热更新）。
+// 路径以 .yaml / .yml 结尾、?format=yaml 或 Accept 含 yaml 时输出 YAML，否则 JSON。
+func Handler(g *web.GOweb, info Info, opts ...Option) web.HandlerFunc {
+	return func(ctx *web.Context) {
+		doc := Generate(g, info, opts...)
+		if wantYAML(ctx.Request) {
+			data, err := yaml.Marshal(doc)
+			if err != nil {
+				http.Error(ctx.Writer, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			ctx.Writer.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+			ctx.Write(data)
+			return
+		}
+		data, err := json.Marshal(doc)
+		if err != nil {
+			http.Error(ctx.Writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ctx.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+		ctx.Write(data)
+	}
+}
+
+func wantYAML(r *http.Request) bool {
+	if strings.HasSuffix(r.URL.Path, ".yaml") || strings.HasSuffix(r.URL.Path, ".yml") {
+		return true
+	}
+	if r.URL.Query().Get("format") == "yaml" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "yaml")
+}
@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type createUser struct {
+	ID    int    `path:"id"`
+	Trace string `header:"X-Trace" validate:"required"`
+	Name  string `json:"name" validate:"required,min=2,max=32"`
+	Role  string `json:"role" validate:"oneof=admin user"`
+}
+
+type user struct {
+	Name    string  `json:"name"`
+	Friends []*user `json:"friends,omitempty"`
+}
+
+type listQuery struct {
+	Page int `query:"page" validate:"min=1"`
+}
+
+func newTestApp() *web.GOweb {
+	g := web.New()
+	g.GET("/users", func(ctx *web.Context) {}, web.HandlerOpt{Name: "listUsers", Request: listQuery{}, Response: []user{}})
+	g.POST("/users/#id:int", func(ctx *web.Context) {}, web.HandlerOpt{
+		Name:     "createUser",
+		Summary:  "create",
+		Tags:     []string{"user"},
+		Request:  createUser{},
+		Response: user{},
+	})
+	g.GET("/files/#...", func(ctx *web.Context) {})
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	doc := Generate(newTestApp(), Info{Title: "t", Version: "1"})
+
+	if doc.OpenAPI != Version {
+		t.Fatalf("openapi = %q", doc.OpenAPI)
+	}
+	post := (*doc.Paths["/users/{id}"])["post"]
+	if post == nil {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	if post.OperationID != "createUser" || post.Summary != "create" {
+		t.Fatalf("op = %+v", post)
+	}
+	var got []string
+	for _, p := range post.Parameters {
+		got = append(got, p.In+":"+p.Name+":"+p.Schema.Type)
+	}
+	if strings.Join(got, ",") != "path:id:integer,header:X-Trace:string" {
+		t.Fatalf("params = %v", got)
+	}
+	body := post.RequestBody.Content["application/json"].Schema
+	if len(body.Properties) != 2 || body.Properties["name"].MinLength == nil || *body.Properties["name"].MinLength != 2 {
+		t.Fatalf("body = %+v", body)
+	}
+	if len(body.Properties["role"].Enum) != 2 || strings.Join(body.Required, ",") != "name" {
+		t.Fatalf("body = %+v", body)
+	}
+	if ref := post.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/user" {
+		t.Fatalf("response ref = %q", ref)
+	}
+	// 递归类型经 $ref 收敛。
+	friends := doc.Components.Schemas["user"].Properties["friends"]
+	if friends.Items == nil || friends.Items.Ref != "#/components/schemas/user" {
+		t.Fatalf("friends = %+v", friends)
+	}
+
+	list := (*doc.Paths["/users"])["get"]
+	if list.RequestBody != nil || len(list.Parameters) != 1 || list.Parameters[0].In != "query" {
+		t.Fatalf("list = %+v", list)
+	}
+	if _, ok := doc.Paths["/files/{wildcard}"]; !ok {
+		t.Fatalf("catch-all path missing: %v", doc.Paths)
+	}
+}
+
+func TestHandlerFormats(t *testing.T) {
+	g := newTestApp()
+	h := Handler(g, Info{Title: "t", Version: "1"}, WithFilter(func(r web.RouterInfo) bool {
+		return !strings.HasPrefix(r.Path, "/openapi")
+	}))
+	g.GET("/openapi.json", h)
+	g.GET("/openapi.yaml", h)
+
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+	var doc Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if _, ok := doc.Paths["/openapi.json"]; ok {
+		t.Fatal("filter ignored")
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+
+	rec = httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.yaml", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/yaml") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "openapi: 3.1.0") {
+		t.Fatalf("yaml body = %q", rec.Body.String())
+	}
+}
+
+func TestValidateEnumKinds(t *testing.T) {
+	type req struct {
+		Level  int     `json:"level" validate:"oneof=1 2 x"`
+		Ratio  float64 `json:"ratio" validate:"oneof=0.5 1"`
+		On     *bool   `json:"on" validate:"oneof=true"`
+		Mode   string  `json:"mode" validate:"oneof=a b"`
+		Status uint8   `query:"status" validate:"required,oneof=3 300"`
+	}
+	g := web.New()
+	g.POST("/x", func(ctx *web.Context) {}, web.HandlerOpt{Request: req{}})
+	doc := Generate(g, Info{Title: "t", Version: "1"})
+
+	data, _ := json.Marshal((*doc.Paths["/x"])["post"])
+	// 候选值按字段类型输出；无法转换的 x、超出 uint8 的 300 被忽略
+	for _, want := range []string{
+		`"level":{"type":"integer","format":"int64","enum":[1,2]}`,
+		`"ratio":{"type":"number","format":"double","enum":[0.5,1]}`,
+		`"enum":[true]`,
+		`"mode":{"type":"string","enum":["a","b"]}`,
+		`"enum":[3]`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("missing %s in %s", want, data)
+		}
+	}
+}
+
+func TestOperationIDsUnique(t *testing.T) {
+	g := web.New()
+	g.Any("/ping", func(ctx *web.Context) {}, web.HandlerOpt{Name: "ping"})
+	g.GET("/items", func(ctx *web.Context) {}, web.HandlerOpt{Name: "items"})
+	g.POST("/items", func(ctx *web.Context) {}, web.HandlerOpt{Name: "items"})
+	g.GET("/health", func(ctx *web.Context) {}, web.HandlerOpt{Name: "health"})
+	doc := Generate(g, Info{Title: "t", Version: "1"})
+
+	seen := map[string]bool{}
+	for path, item := range doc.Paths {
+		for m, op := range *item {
+			if seen[op.OperationID] {
+				t.Fatalf("duplicate operationId %q at %s %s", op.OperationID, m, path)
+			}
+			seen[op.OperationID] = true
+		}
+	}
+	if (*doc.Paths["/ping"])["delete"].OperationID != "ping_delete" {
+		t.Fatalf("ping = %+v", (*doc.Paths["/ping"])["delete"])
+	}
+	if (*doc.Paths["/items"])["post"].OperationID != "items_post" {
+		t.Fatalf("items = %+v", (*doc.Paths["/items"])["post"])
+	}
+	if (*doc.Paths["/health"])["get"].OperationID != "health" {
+		t.Fatalf("health = %+v", (*doc.Paths["/health"])["get"])
+	}
+}
+
+func TestPathCollisionMerged(t *testing.T) {
+	g := web.New()
+	g.GET("/items/#id", func(ctx *web.Context) {}, web.HandlerOpt{Summary: "by name"})
+	g.GET("/items/#id:int", func(ctx *web.Context) {}, web.HandlerOpt{Summary: "by id"})
+	g.DELETE("/items/#id:int", func(ctx *web.Context) {})
+	doc := Generate(g, Info{Title: "t", Version: "1"})
+
+	if len(doc.Paths) != 1 {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	item := *doc.Paths["/items/{id}"]
+	get := item["get"]
+	if get == nil || item["delete"] == nil {
+		t.Fatalf("item = %v", item)
+	}
+	schema := get.Parameters[0].Schema
+	if len(schema.AnyOf) != 2 || schema.AnyOf[0].Type != "string" || schema.AnyOf[1].Type != "integer" {
+		t.Fatalf("id schema = %+v", schema)
+	}
+	// DELETE 只有 int 路由，参数不受合并影响。
+	if item["delete"].Parameters[0].Schema.Type != "integer" {
+		t.Fatalf("delete id = %+v", item["delete"].Parameters[0].Schema)
+	}
+}
@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"encoding"
+	"mime/multipart"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+// Schema 是 JSON Schema 2020-12 中 OpenAPI 常用的子集。
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	schemaNameReplacer  = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemaBuilder 把具名 struct 收敛到 components.schemas，以 $ref 引用，天然处理递归类型。
+type schemaBuilder struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+func (b *schemaBuilder) of(v any) *Schema {
+	return b.typeSchema(reflect.TypeOf(v))
+}
+
+func (b *schemaBuilder) typeSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		if t == fileHeaderType {
+			return &Schema{Type: "string", Format: "binary"}
+		}
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	}
+	if t.Kind() != reflect.Struct && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)) {
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t, nil)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.componentName(t)
+			b.names[t] = name
+			// 先占位再展开，递归引用自身时直接得到 $ref。
+			b.components[name] = &Schema{}
+			*b.components[name] = *b.structSchema(t, nil)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// componentName 优先用类型名，不同包重名时追加包路径尾段区分。
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	name := schemaNameReplacer.ReplaceAllString(t.Name(), "_")
+	if _, taken := b.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndexByte(pkg, '/'); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	name = schemaNameReplacer.ReplaceAllString(pkg+"."+t.Name(), "_")
+	for i := 2; ; i++ {
+		candidate := name + strconv.Itoa(i)
+		if _, taken := b.components[candidate]; !taken {
+			return candidate
+		}
+	}
+}
+
+// structSchema 按 json tag 展开字段；include 非空时只保留返回 true 的字段。
+func (b *schemaBuilder) structSchema(t reflect.Type, include func(reflect.StructField) bool) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	eachField(t, func(sf reflect.StructField) {
+		if include != nil && !include(sf) {
+			return
+		}
+		name, ok := jsonName(sf)
+		if !ok {
+			return
+		}
+		fs := b.typeSchema(sf.Type)
+		if applyValidate(fs, t, sf) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	})
+	return s
+}
+
+// request 把请求样例拆成参数（path/query/header）与 body（json / form）。
+func (b *schemaBuilder) request(v any, method string) ([]*Parameter, *RequestBody) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	noBody := method == "get" || method == "head" || method == "delete"
+	if t.Kind() != reflect.Struct {
+		if noBody {
+			return nil, nil
+		}
+		return nil, jsonBody(b.typeSchema(t))
+	}
+
+	var params []*Parameter
+	var hasBind, hasForm, hasFile bool
+	eachField(t, func(sf reflect.StructField) {
+		for _, in := range [...]string{web.TagPath, web.TagQuery, web.TagHeader} {
+			name := tagName(sf.Tag.Get(in))
+			if name == "" {
+				continue
+			}
+			hasBind = true
+			ps := b.typeSchema(sf.Type)
+			required := applyValidate(ps, t, sf) || in == web.TagPath
+			params = append(params, &Parameter{Name: name, In: in, Required: required, Schema: ps})
+		}
+		if tagName(sf.Tag.Get(web.TagForm)) != "" {
+			hasForm = true
+			if ft := sf.Type; ft == fileHeaderType || (ft.Kind() == reflect.Slice && ft.Elem() == fileHeaderType) {
+				hasFile = true
+			}
+		}
+	})
+	if noBody {
+		return params, nil
+	}
+
+	var body *RequestBody
+	if hasForm {
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		eachField(t, func(sf reflect.StructField) {
+			name := tagName(sf.Tag.Get(web.TagForm))
+			if name == "" {
+				return
+			}
+			fs := b.typeSchema(sf.Type)
+			if applyValidate(fs, t, sf) {
+				form.Required = append(form.Required, name)
+			}
+			form.Properties[name] = fs
+		})
+		ct := "application/x-www-form-urlencoded"
+		if hasFile {
+			ct = "multipart/form-data"
+		}
+		body = &RequestBody{Content: map[string]*MediaType{ct: {Schema: form}}}
+	}
+
+	isBodyField := func(sf reflect.StructField) bool {
+		if _, ok := sf.Tag.Lookup("json"); ok {
+			return true
+		}
+		for _, in := range [...]string{web.TagPath, web.TagQuery, web.TagHeader, web.TagForm} {
+			if tagName(sf.Tag.Get(in)) != "" {
+				return false
+			}
+		}
+		return true
+	}
+	var js *Schema
+	if !hasBind && !hasForm {
+		js = b.typeSchema(t)
+	} else if s := b.structSchema(t, isBodyField); len(s.Properties) > 0 {
+		js = s
+	}
+	if js != nil {
+		if body == nil {
+			body = jsonBody(js)
+		} else {
+			body.Content["application/json"] = &MediaType{Schema: js}
+		}
+	}
+	return params, body
+}
+
+func jsonBody(s *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: map[string]*MediaType{"application/json": {Schema: s}}}
+}
+
+// eachField 遍历导出字段，匿名嵌入 struct 平铺展开（与 encoding/json 一致）。
+func eachField(t reflect.Type, fn func(reflect.StructField)) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+				eachField(ft, fn)
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		fn(sf)
+	}
+}
+
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := tagName(tag); name != "" {
+		return name, true
+	}
+	return sf.Name, true
+}
+
+func tagName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// applyValidate 把 `validate` tag 映射为 schema 约束，返回字段是否 required。
+// 规则由 web.ParseRules 解析，语义与 web.Validate 一致：数值约束取值，字符串约束长度，切片约束元素数。
+func applyValidate(s *Schema, owner reflect.Type, sf reflect.StructField) (required bool) {
+	ft := sf.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	// 非法 tag 已在注册 HandlerOpt.Request 时 panic；其余类型（如 Response）出错时不输出约束。
+	rules, _ := web.ParseRules(owner, sf)
+	for _, r := range rules {
+		switch r.Name {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			setBound(s, ft, r.Name, r.Num)
+		case "oneof":
+			for _, o := range r.OneOf {
+				if v, ok := enumValue(ft, o); ok {
+					s.Enum = append(s.Enum, v)
+				}
+			}
+		case "regex":
+			s.Pattern = r.Param
+		}
+	}
+	return
+}
+
+// enumValue 把 oneof 候选值转换为字段类型对应的 JSON 值；无法转换的候选值 web.Validate 也不会通过，直接忽略。
+func enumValue(ft reflect.Type, o string) (any, bool) {
+	switch ft.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(o, 10, ft.Bits())
+		return n, err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(o, 10, ft.Bits())
+		return n, err == nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(o, ft.Bits())
+		return n, err == nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(o)
+		return b, err == nil
+	}
+	return o, true
+}
+
+func setBound(s *Schema, ft reflect.Type, rule string, n float64) {
+	i := int(n)
+	switch ft.Kind() {
+	case reflect.String:
+		if rule != "max" {
+			s.MinLength = &i
+		}
+		if rule != "min" {
+			s.MaxLength = &i
+		}
+	case reflect.Map:
+	case reflect.Slice, reflect.Array:
+		if rule != "max" {
+			s.MinItems = &i
+		}
+		if rule != "min" {
+			s.MaxItems = &i
+		}
+	default:
+		if rule != "max" {
+			s.Minimum = &n
+		}
+		if rule != "min" {
+			s.Maximum = &n
+		}
+	}
+}
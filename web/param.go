@@ -0,0 +1,147 @@
+package web
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// paramConstraint 是 `#name:type` 段上的约束；spec 为冒号后的原文，用于冲突检测。
+type paramConstraint struct {
+	spec  string
+	check func(string) bool
+}
+
+var (
+	paramTypesMu sync.RWMutex
+	paramTypes   = map[string]func(string) bool{
+		"int": func(s string) bool {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		},
+		"uint": func(s string) bool {
+			_, err := strconv.ParseUint(s, 10, 64)
+			return err == nil
+		},
+		"float": func(s string) bool {
+			_, err := strconv.ParseFloat(s, 64)
+			return err == nil
+		},
+		"bool": func(s string) bool {
+			_, err := strconv.ParseBool(s)
+			return err == nil
+		},
+		"alpha": func(s string) bool {
+			return s != "" && strings.IndexFunc(s, func(r rune) bool {
+				return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+			}) < 0
+		},
+		"alnum": func(s string) bool {
+			return s != "" && strings.IndexFunc(s, func(r rune) bool {
+				return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+			}) < 0
+		},
+		"uuid": isUUID,
+	}
+)
+
+// RegisterParamType 注册自定义路径参数类型，之后可用 `#name:typ` 引用。
+// 需在注册路由之前调用；同名覆盖。
+func RegisterParamType(typ string, check func(string) bool) {
+	if typ == "" || check == nil {
+		panic("[web] RegisterParamType: empty type or nil check")
+	}
+	paramTypesMu.Lock()
+	paramTypes[typ] = check
+	paramTypesMu.Unlock()
+}
+
+// splitParamSegment 把 "#id:int" 拆为 ("id", "int")；无约束时 spec 为空。
+func splitParamSegment(seg string) (name, spec string) {
+	name, spec, _ = strings.Cut(seg[len(paramPrefix):], ":")
+	return
+}
+
+// parseParamConstraint 解析约束 spec：内置 / 注册类型名，或 regex(...)。
+// regex 需完整匹配整段，且不能包含 '/'。
+func parseParamConstraint(spec string) (*paramConstraint, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(spec, "regex(") && strings.HasSuffix(spec, ")") {
+		expr := spec[len("regex(") : len(spec)-1]
+		re, err := regexp.Compile("^(?:" + expr + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("非法参数正则 %q: %v", expr, err)
+		}
+		return &paramConstraint{spec: spec, check: re.MatchString}, nil
+	}
+	paramTypesMu.RLock()
+	check, ok := paramTypes[spec]
+	paramTypesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知参数类型: %q", spec)
+	}
+	return &paramConstraint{spec: spec, check: check}, nil
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GetUrlPathParamInt 读取 `#name:int` 这类已在匹配阶段校验过的参数。
+// 参数不存在或未加约束且不是整数时返回错误。
+func (c *Context) GetUrlPathParamInt(key string) (int, error) {
+	v, err := c.GetUrlPathParamInt64(key)
+	return int(v), err
+}
+
+func (c *Context) GetUrlPathParamInt64(key string) (int64, error) {
+	v, ok := c.param[key]
+	if !ok {
+		return 0, fmt.Errorf("[web] path param %q not found", key)
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+func (c *Context) GetUrlPathParamUint64(key string) (uint64, error) {
+	v, ok := c.param[key]
+	if !ok {
+		return 0, fmt.Errorf("[web] path param %q not found", key)
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
+
+func (c *Context) GetUrlPathParamFloat64(key string) (float64, error) {
+	v, ok := c.param[key]
+	if !ok {
+		return 0, fmt.Errorf("[web] path param %q not found", key)
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func (c *Context) GetUrlPathParamBool(key string) (bool, error) {
+	v, ok := c.param[key]
+	if !ok {
+		return false, fmt.Errorf("[web] path param %q not found", key)
+	}
+	return strconv.ParseBool(v)
+}
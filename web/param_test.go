@@ -0,0 +1,140 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTypedParamFallsThrough(t *testing.T) {
+	g := New()
+	g.GET("/users/#id:int", func(ctx *Context) {
+		id, err := ctx.GetUrlPathParamInt("id")
+		if err != nil {
+			t.Errorf("GetUrlPathParamInt() error = %v", err)
+		}
+		ctx.WriteString("int:" + strconv.Itoa(id))
+	})
+	g.GET("/users/#uid:uuid", func(ctx *Context) { ctx.WriteString("uuid:" + ctx.GetUrlPathParam("uid")) })
+	g.GET("/users/#name", func(ctx *Context) { ctx.WriteString("name:" + ctx.GetUrlPathParam("name")) })
+
+	cases := map[string]string{
+		"/users/42": "int:42",
+		"/users/123e4567-e89b-12d3-a456-426614174000": "uuid:123e4567-e89b-12d3-a456-426614174000",
+		"/users/alice": "name:alice",
+	}
+	for path, want := range cases {
+		if got := runRequest(t, g, "GET", path); got != want {
+			t.Fatalf("path %s want %q got %q", path, want, got)
+		}
+	}
+}
+
+func TestTypedParamNoMatchIs404(t *testing.T) {
+	g := New()
+	g.GET("/items/#id:uint", func(ctx *Context) { ctx.WriteString("ok") })
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, httptest.NewRequest("GET", "/items/-1", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestRegexParam(t *testing.T) {
+	g := New()
+	g.GET("/posts/#slug:regex([a-z0-9-]+)", func(ctx *Context) { ctx.WriteString(ctx.GetUrlPathParam("slug")) })
+	g.GET("/posts/#...", func(ctx *Context) { ctx.WriteString("fb") })
+
+	if got := runRequest(t, g, "GET", "/posts/hello-world"); got != "hello-world" {
+		t.Fatalf("got %q", got)
+	}
+	// 正则需完整匹配整段。
+	if got := runRequest(t, g, "GET", "/posts/Hello"); got != "fb" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestTypedParamBacktracksToLaterBranch(t *testing.T) {
+	g := New()
+	g.GET("/a/#id:int/x", func(ctx *Context) { ctx.WriteString("int") })
+	g.GET("/a/#name/y", func(ctx *Context) { ctx.WriteString("name:" + ctx.GetUrlPathParam("name")) })
+
+	if got := runRequest(t, g, "GET", "/a/1/x"); got != "int" {
+		t.Fatalf("got %q", got)
+	}
+	if got := runRequest(t, g, "GET", "/a/1/y"); got != "name:1" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestTypedParamConflicts(t *testing.T) {
+	cases := []struct {
+		name  string
+		paths []string
+	}{
+		{"unknown type", []string{"/x/#id:nope"}},
+		{"bad regex", []string{"/x/#id:regex(()"}},
+		{"empty type", []string{"/x/#id:"}},
+		{"same type different name", []string{"/x/#id:int", "/x/#n:int"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := New()
+			defer func() {
+				if r := recover(); r == nil || !strings.HasPrefix(r.(string), "[web]") {
+					t.Fatalf("expected [web] panic, got %v", r)
+				}
+			}()
+			for _, p := range c.paths {
+				g.GET(p, func(ctx *Context) {})
+			}
+		})
+	}
+}
+
+func TestTypedParamSameSegmentReused(t *testing.T) {
+	g := New()
+	g.GET("/x/#id:int", func(ctx *Context) { ctx.WriteString("get") })
+	g.POST("/x/#id:int", func(ctx *Context) { ctx.WriteString("post") })
+	g.GET("/x/#id:int/detail", func(ctx *Context) { ctx.WriteString("detail") })
+
+	if got := runRequest(t, g, "POST", "/x/1"); got != "post" {
+		t.Fatalf("got %q", got)
+	}
+	if got := runRequest(t, g, "GET", "/x/1/detail"); got != "detail" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRegisterParamType(t *testing.T) {
+	RegisterParamType("hex", func(s string) bool {
+		_, err := strconv.ParseUint(s, 16, 64)
+		return err == nil
+	})
+	g := New()
+	g.GET("/c/#v:hex", func(ctx *Context) { ctx.WriteString("hex") })
+	if got := runRequest(t, g, "GET", "/c/ff"); got != "hex" {
+		t.Fatalf("got %q", got)
+	}
+	if got := runRequest(t, g, "GET", "/c/zz"); !strings.Contains(got, "404") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestParamBacktrackRestoresOuterValue(t *testing.T) {
+	g := New()
+	g.GET("/a/#id/b/#id:int", func(ctx *Context) { ctx.WriteString("typed " + ctx.GetUrlPathParam("id")) })
+	g.GET("/a/#id/b/#name/c", func(ctx *Context) {
+		ctx.WriteString(ctx.GetUrlPathParam("id") + " " + ctx.GetUrlPathParam("name"))
+	})
+
+	if got := runRequest(t, g, "GET", "/a/1/b/2"); got != "typed 2" {
+		t.Fatalf("got %q", got)
+	}
+	// #id:int 分支失败回溯后，外层的 id 应恢复为 1，而不是被删除
+	if got := runRequest(t, g, "GET", "/a/1/b/2/c"); got != "1 2" {
+		t.Fatalf("got %q, want %q", got, "1 2")
+	}
+}
@@ -39,28 +39,40 @@ type registry struct {
 	mu          sync.Mutex
 	snapshot    atomic.Pointer[routeSnapshot]
 	globalCount uint32 // 仅在 mu 持有期间被修改
+
+	// main 为默认（兜底）路由树的根，hosts 为 GOweb.Host 注册的各虚拟主机路由树
+	main  *RouterGroup
+	hosts []*hostRoute
 }
 
 // routeSnapshot 是某一时刻路由表的不可变快照；ServeHTTP 完全依赖它做匹配。
+// 顶层快照描述默认路由树，并携带各虚拟主机的子快照（子快照的 host 相关字段为空）。
 type routeSnapshot struct {
 	root       *RouterGroup
 	flatRoutes map[string]*RouterGroup // normalize 后的纯静态路径 -> 叶子节点
 	hasDynamic bool                    // 是否含 #name / #...
+	names      map[string]*RouterGroup // HandlerOpt.Name -> 叶子节点，供 URLFor 使用；顶层为全部路由树合并
+
+	ownNames     map[string]*RouterGroup // 仅本路由树的 names
+	exactHosts   map[string]*routeSnapshot
+	hostPatterns []*hostRoute // 按优先级排序，table 为对应子快照
 }
 
 // RouterGroup 是段级压缩前缀树（radix tree）的节点。
 //
 // 一条边由若干「路径段」组成，存放于 segments：
 //   - kindStatic   : 普通段链，如 ["api","v1","users"]
-//   - kindParam    : 单段，形如 ["#name"]
+//   - kindParam    : 单段，形如 ["#name"] 或带约束的 ["#name:int"]
 //   - kindCatchAll : 单段 ["#..."]
 //
 // 同一节点同时最多拥有：
 //   - 任意多个静态子节点（按首段分桶到 staticKids）
+//   - 任意多个 typedKids（带约束的参数，同约束不同名字会冲突）
 //   - 至多一个 paramKid（不同名字会冲突）
 //   - 至多一个 catchAllKid
 //
-// 匹配优先级：staticKids > paramKid > catchAllKid。
+// 匹配优先级：staticKids > typedKids（按注册顺序）> paramKid > catchAllKid，
+// 某分支走到底未命中时回溯尝试下一优先级。
 //
 // 写端节点：host != nil；可在该节点上调用注册 API。
 // 快照节点：host == nil；只读，注册 API 会 panic（防止误改快照）。
@@ -70,9 +82,14 @@ type RouterGroup struct {
 
 	parent      *RouterGroup
 	staticKids  map[string]*RouterGroup
+	typedKids   []*RouterGroup
 	paramKid    *RouterGroup
 	catchAllKid *RouterGroup
 
+	// 仅 kindParam：参数名与可选约束。
+	paramName  string
+	constraint *paramConstraint
+
 	method      map[string]HandlerFunc
 	options     map[string]HandlerOpt
 	middlewares []middleware
@@ -322,6 +339,20 @@ func dryRunValidate(start *RouterGroup, segs []string) error {
 			if !isValidParamName(seg) {
 				return fmt.Errorf("非法参数段: %q", seg)
 			}
+			if _, spec := splitParamSegment(seg); spec != "" {
+				if _, err := parseParamConstraint(spec); err != nil {
+					return err
+				}
+				if node != nil {
+					kid, err := node.findTypedKid(seg)
+					if err != nil {
+						return err
+					}
+					node = kid
+				}
+				i++
+				continue
+			}
 			if node != nil && node.paramKid != nil {
 				if node.paramKid.segments[0] != seg {
 					return fmt.Errorf("地址泛匹配重复: 已有 %s, 试图新增 %s",
@@ -349,7 +380,7 @@ func dryRunValidate(start *RouterGroup, segs []string) error {
 }
 
 func isValidParamName(seg string) bool {
-	// 形如 "#name" 或 "#..."；"#" 只是前缀，参数名至少 1 个非斜杠字符。
+	// 形如 "#name"、"#name:type" 或 "#..."；"#" 只是前缀，参数名至少 1 个非斜杠字符。
 	if len(seg) < 2 {
 		return false
 	}
@@ -361,7 +392,25 @@ func isValidParamName(seg string) bool {
 			return false
 		}
 	}
-	return true
+	name, spec := splitParamSegment(seg)
+	if name == "" {
+		return false
+	}
+	return !strings.Contains(seg, ":") || spec != ""
+}
+
+// findTypedKid 查找与 seg 完全一致的约束参数子节点；同约束但不同名字视为冲突。
+func (g *RouterGroup) findTypedKid(seg string) (*RouterGroup, error) {
+	_, spec := splitParamSegment(seg)
+	for _, kid := range g.typedKids {
+		if kid.segments[0] == seg {
+			return kid, nil
+		}
+		if kid.constraint.spec == spec {
+			return nil, fmt.Errorf("地址泛匹配重复: 已有 %s, 试图新增 %s", kid.segments[0], seg)
+		}
+	}
+	return nil, nil
 }
 
 // walkStaticEdge 沿 node 的静态子节点尽可能走完 segs；走不通返回 nil。
@@ -410,16 +459,42 @@ func (g *RouterGroup) position(segs []string) (*RouterGroup, error) {
 			node = node.catchAllKid
 			i++
 		case strings.HasPrefix(seg, paramPrefix):
+			name, spec := splitParamSegment(seg)
+			if spec != "" {
+				kid, err := node.findTypedKid(seg)
+				if err != nil {
+					return nil, err
+				}
+				if kid == nil {
+					constraint, err := parseParamConstraint(spec)
+					if err != nil {
+						return nil, err
+					}
+					kid = &RouterGroup{
+						kind:       kindParam,
+						segments:   []string{seg},
+						parent:     node,
+						paramName:  name,
+						constraint: constraint,
+						host:       g.host,
+					}
+					node.typedKids = append(node.typedKids, kid)
+				}
+				node = kid
+				i++
+				continue
+			}
 			if node.paramKid != nil {
 				if node.paramKid.segments[0] != seg {
 					return nil, errors.New("地址泛匹配重复")
 				}
 			} else {
 				node.paramKid = &RouterGroup{
-					kind:     kindParam,
-					segments: []string{seg},
-					parent:   node,
-					host:     g.host,
+					kind:      kindParam,
+					segments:  []string{seg},
+					parent:    node,
+					paramName: name,
+					host:      g.host,
 				}
 			}
 			node = node.paramKid
@@ -473,9 +548,11 @@ func (g *RouterGroup) upsertStatic(segs []string) *RouterGroup {
 		segments:    append([]string(nil), child.segments[common:]...),
 		parent:      child,
 		staticKids:  child.staticKids,
+		typedKids:   child.typedKids,
 		paramKid:    child.paramKid,
 		catchAllKid: child.catchAllKid,
 		method:      child.method,
+		options:     child.options,
 		middlewares: child.middlewares,
 		order:       child.order,
 		host:        child.host,
@@ -483,6 +560,9 @@ func (g *RouterGroup) upsertStatic(segs []string) *RouterGroup {
 	for _, c := range inner.staticKids {
 		c.parent = inner
 	}
+	for _, c := range inner.typedKids {
+		c.parent = inner
+	}
 	if inner.paramKid != nil {
 		inner.paramKid.parent = inner
 	}
@@ -491,9 +571,11 @@ func (g *RouterGroup) upsertStatic(segs []string) *RouterGroup {
 	}
 	child.segments = append([]string(nil), child.segments[:common]...)
 	child.staticKids = map[string]*RouterGroup{inner.segments[0]: inner}
+	child.typedKids = nil
 	child.paramKid = nil
 	child.catchAllKid = nil
 	child.method = nil
+	child.options = nil
 	child.middlewares = nil
 	child.order = 0
 
@@ -558,6 +640,16 @@ func (g *RouterGroup) handle(method, path string, handlerFunc HandlerFunc, opt .
 			panic(formatPanic(g, path, fmt.Errorf("该路由 method 重复: %s", method)))
 		}
 	}
+	if len(opt) > 0 && opt[0].Name != "" {
+		if err := host.checkName(g.findRoot(), leaf, opt[0].Name); err != nil {
+			panic(formatPanic(g, path, err))
+		}
+	}
+	if len(opt) > 0 && opt[0].Request != nil {
+		if err := CheckRules(opt[0].Request); err != nil {
+			panic(formatPanic(g, path, err))
+		}
+	}
 	if leaf.method == nil {
 		leaf.method = make(map[string]HandlerFunc, 4)
 	}
@@ -574,6 +666,36 @@ func (g *RouterGroup) handle(method, path string, handlerFunc HandlerFunc, opt .
 	host.publish(g.findRoot())
 }
 
+// checkName 确认路由名未被其它路径或其它路由树占用：所有虚拟主机与默认路由树共享 URLFor 的命名空间。
+// 必须在 mu.Lock 中调用。
+func (r *registry) checkName(root, leaf *RouterGroup, name string) error {
+	snap := r.snapshot.Load()
+	if snap == nil {
+		return nil
+	}
+	owner, where := r.main, "默认路由树"
+	prev, ok := snap.ownNames[name]
+	for _, h := range r.hosts {
+		if ok {
+			break
+		}
+		if h.table != nil {
+			prev, ok = h.table.names[name]
+			owner, where = h.root, "虚拟主机 "+h.pattern
+		}
+	}
+	if !ok {
+		return nil
+	}
+	if owner != root {
+		return fmt.Errorf("路由名重复: %s 已在%s中指向 %s", name, where, prev.completePath())
+	}
+	if prev.completePath() != leaf.completePath() {
+		return fmt.Errorf("路由名重复: %s 已指向 %s", name, prev.completePath())
+	}
+	return nil
+}
+
 // PathMatch 是公开 API：基于已发布的快照做匹配。当前 RouterGroup 不在
 // 任何 registry 下（裸节点 / 快照节点）时返回 (nil, nil, nil)。
 //
@@ -624,52 +746,10 @@ func (snap *routeSnapshot) match(path, method string) (params map[string]string,
 	}
 
 	segs := splitSegments(path)
-	node := snap.root
-	for i := 0; i < len(segs); {
-		if child, ok := node.staticKids[segs[i]]; ok {
-			n := len(child.segments)
-			if i+n <= len(segs) && segmentsEqual(segs[i:i+n], child.segments) {
-				node = child
-				i += n
-				continue
-			}
-		}
-		if node.paramKid != nil {
-			if params == nil {
-				params = make(map[string]string, 4)
-			}
-			params[node.paramKid.segments[0][1:]] = segs[i]
-			node = node.paramKid
-			i++
-			continue
-		}
-		if node.catchAllKid != nil {
-			if params == nil {
-				params = make(map[string]string, 4)
-			}
-			params[paramPrefix] = strings.Join(segs[i:], "/")
-			node = node.catchAllKid
-			leaf = node
-			handle = node.method[method]
-			if handle == nil {
-				handle = node.method[ANY]
-			}
-			if handle == nil {
-				allowed = methodSet(node.method)
-			}
-			return
-		}
+	node := matchDynamic(snap.root, segs, 0, &params)
+	if node == nil {
 		return nil, nil, nil, nil
 	}
-	// segs 已耗尽但当前节点尚未承载任何 method 时，回落到 catchAll，
-	// 让 /#... 这种兜底路由也能匹配 / 或 /api 这类落空目录。
-	if len(node.method) == 0 && node.catchAllKid != nil {
-		if params == nil {
-			params = make(map[string]string, 4)
-		}
-		params[paramPrefix] = ""
-		node = node.catchAllKid
-	}
 	leaf = node
 	handle = node.method[method]
 	if handle == nil {
@@ -681,12 +761,123 @@ func (snap *routeSnapshot) match(path, method string) (params map[string]string,
 	return
 }
 
-// publish 深拷贝写端树为只读快照并 atomic 替换。整个过程必须在 mu.Lock 中调用。
+// matchDynamic 从 segs[i:] 开始在 node 下深度优先匹配，按 static > typed > param > catchAll
+// 的优先级尝试，分支走到底未命中（无节点或节点无任何 method）时回溯到下一优先级。
+// 命中返回叶子节点，params 按需分配并只保留命中路径上的参数。
+func matchDynamic(node *RouterGroup, segs []string, i int, params *map[string]string) *RouterGroup {
+	if i == len(segs) {
+		if len(node.method) > 0 {
+			return node
+		}
+		// segs 已耗尽但当前节点尚未承载任何 method 时，回落到 catchAll，
+		// 让 /#... 这种兜底路由也能匹配 / 或 /api 这类落空目录。
+		if node.catchAllKid != nil {
+			setParam(params, paramPrefix, "")
+			return node.catchAllKid
+		}
+		return nil
+	}
+	if child, ok := node.staticKids[segs[i]]; ok {
+		n := len(child.segments)
+		if i+n <= len(segs) && segmentsEqual(segs[i:i+n], child.segments) {
+			if leaf := matchDynamic(child, segs, i+n, params); leaf != nil {
+				return leaf
+			}
+		}
+	}
+	for _, kid := range node.typedKids {
+		if !kid.constraint.check(segs[i]) {
+			continue
+		}
+		prev, had := (*params)[kid.paramName]
+		setParam(params, kid.paramName, segs[i])
+		if leaf := matchDynamic(kid, segs, i+1, params); leaf != nil {
+			return leaf
+		}
+		restoreParam(*params, kid.paramName, prev, had)
+	}
+	if node.paramKid != nil {
+		prev, had := (*params)[node.paramKid.paramName]
+		setParam(params, node.paramKid.paramName, segs[i])
+		if leaf := matchDynamic(node.paramKid, segs, i+1, params); leaf != nil {
+			return leaf
+		}
+		restoreParam(*params, node.paramKid.paramName, prev, had)
+	}
+	if node.catchAllKid != nil {
+		setParam(params, paramPrefix, strings.Join(segs[i:], "/"))
+		return node.catchAllKid
+	}
+	return nil
+}
+
+func setParam(params *map[string]string, key, value string) {
+	if *params == nil {
+		*params = make(map[string]string, 4)
+	}
+	(*params)[key] = value
+}
+
+// restoreParam 回溯时恢复 key 在尝试前的取值；上层同名参数不会因下层分支失败而丢失。
+func restoreParam(params map[string]string, key, prev string, had bool) {
+	if had {
+		params[key] = prev
+	} else {
+		delete(params, key)
+	}
+}
+
+// publish 深拷贝 writeRoot 所在路由树为只读快照，与其余路由树的现有快照合并后 atomic 替换。
+// 整个过程必须在 mu.Lock 中调用。
 func (r *registry) publish(writeRoot *RouterGroup) {
+	table := buildTable(writeRoot)
+	next := &routeSnapshot{}
+	if prev := r.snapshot.Load(); prev != nil {
+		*next = *prev
+	}
+
+	if writeRoot == r.main || r.main == nil {
+		next.root, next.flatRoutes, next.hasDynamic, next.ownNames = table.root, table.flatRoutes, table.hasDynamic, table.names
+	} else {
+		next.exactHosts = make(map[string]*routeSnapshot, len(next.exactHosts))
+		next.hostPatterns = nil
+		for _, h := range r.hosts {
+			if h.root == writeRoot {
+				h.table = table
+			}
+			if h.table == nil {
+				continue
+			}
+			if h.exact() {
+				next.exactHosts[h.pattern] = h.table
+			} else {
+				next.hostPatterns = append(next.hostPatterns, h)
+			}
+		}
+		sortHostPatterns(next.hostPatterns)
+	}
+
+	next.names = make(map[string]*RouterGroup, len(next.ownNames))
+	for _, h := range r.hosts {
+		if h.table != nil {
+			for k, v := range h.table.names {
+				next.names[k] = v
+			}
+		}
+	}
+	for k, v := range next.ownNames {
+		next.names[k] = v
+	}
+	r.snapshot.Store(next)
+}
+
+// buildTable 为单棵路由树生成不含虚拟主机信息的快照。
+func buildTable(writeRoot *RouterGroup) *routeSnapshot {
 	nodeMap := make(map[*RouterGroup]*RouterGroup, 64)
 	newRoot := cloneNode(writeRoot, nil, nodeMap)
 
 	flatRoutes := make(map[string]*RouterGroup)
+	names := make(map[string]*RouterGroup)
 	var hasDynamic bool
 	var walk func(n *RouterGroup, dyn bool)
 	walk = func(n *RouterGroup, dyn bool) {
@@ -697,9 +888,17 @@ func (r *registry) publish(writeRoot *RouterGroup) {
 		if len(n.method) > 0 && !dyn {
 			flatRoutes[normalizePathKey(n.completePath())] = n
 		}
+		for _, opt := range n.options {
+			if opt.Name != "" {
+				names[opt.Name] = n
+			}
+		}
 		for _, c := range n.staticKids {
 			walk(c, dyn)
 		}
+		for _, c := range n.typedKids {
+			walk(c, dyn)
+		}
 		if n.paramKid != nil {
 			walk(n.paramKid, dyn)
 		}
@@ -709,25 +908,28 @@ func (r *registry) publish(writeRoot *RouterGroup) {
 	}
 	walk(newRoot, false)
 
-	r.snapshot.Store(&routeSnapshot{
+	return &routeSnapshot{
 		root:       newRoot,
 		flatRoutes: flatRoutes,
 		hasDynamic: hasDynamic,
-	})
+		names:      names,
+	}
 }
 
-// cloneNode 深拷贝节点（含 staticKids/paramKid/catchAllKid 子树），重新建立 parent 链。
+// cloneNode 深拷贝节点（含 staticKids/typedKids/paramKid/catchAllKid 子树），重新建立 parent 链。
 // 新节点 host 设为 nil，禁止运行期被改写。
 func cloneNode(orig, newParent *RouterGroup, m map[*RouterGroup]*RouterGroup) *RouterGroup {
 	if orig == nil {
 		return nil
 	}
 	n := &RouterGroup{
-		kind:     orig.kind,
-		segments: append([]string(nil), orig.segments...),
-		parent:   newParent,
-		order:    orig.order,
-		host:     nil,
+		kind:       orig.kind,
+		segments:   append([]string(nil), orig.segments...),
+		parent:     newParent,
+		paramName:  orig.paramName,
+		constraint: orig.constraint,
+		order:      orig.order,
+		host:       nil,
 	}
 	if orig.method != nil {
 		n.method = make(map[string]HandlerFunc, len(orig.method))
@@ -735,6 +937,12 @@ func cloneNode(orig, newParent *RouterGroup, m map[*RouterGroup]*RouterGroup) *R
 			n.method[k] = v
 		}
 	}
+	if orig.options != nil {
+		n.options = make(map[string]HandlerOpt, len(orig.options))
+		for k, v := range orig.options {
+			n.options[k] = v
+		}
+	}
 	if len(orig.middlewares) > 0 {
 		n.middlewares = append([]middleware(nil), orig.middlewares...)
 	}
@@ -745,6 +953,12 @@ func cloneNode(orig, newParent *RouterGroup, m map[*RouterGroup]*RouterGroup) *R
 			n.staticKids[k] = cloneNode(child, n, m)
 		}
 	}
+	if len(orig.typedKids) > 0 {
+		n.typedKids = make([]*RouterGroup, len(orig.typedKids))
+		for i, child := range orig.typedKids {
+			n.typedKids[i] = cloneNode(child, n, m)
+		}
+	}
 	if orig.paramKid != nil {
 		n.paramKid = cloneNode(orig.paramKid, n, m)
 	}
@@ -768,7 +982,7 @@ func (g *RouterGroup) BottomNodeList() []*RouterGroup {
 }
 
 func (g *RouterGroup) walkLeaves(out *[]*RouterGroup) {
-	if len(g.staticKids) == 0 && g.paramKid == nil && g.catchAllKid == nil {
+	if len(g.staticKids) == 0 && len(g.typedKids) == 0 && g.paramKid == nil && g.catchAllKid == nil {
 		if g.parent != nil {
 			*out = append(*out, g)
 		}
@@ -777,6 +991,9 @@ func (g *RouterGroup) walkLeaves(out *[]*RouterGroup) {
 	for _, c := range g.staticKids {
 		c.walkLeaves(out)
 	}
+	for _, c := range g.typedKids {
+		c.walkLeaves(out)
+	}
 	if g.paramKid != nil {
 		g.paramKid.walkLeaves(out)
 	}
@@ -836,6 +1053,9 @@ func (g *RouterGroup) walkAll(fn func(*RouterGroup)) {
 	for _, c := range g.staticKids {
 		c.walkAll(fn)
 	}
+	for _, c := range g.typedKids {
+		c.walkAll(fn)
+	}
 	if g.paramKid != nil {
 		g.paramKid.walkAll(fn)
 	}
@@ -0,0 +1,397 @@
+package sse
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+type BrokerOption struct {
+	// HistorySize 每个 topic 保留的最近事件数，用于 Last-Event-ID 断线补发；0 走默认 100，<0 不保留。
+	HistorySize int
+	// PingInterval 心跳间隔；0 走默认 15s，<0 关闭。
+	PingInterval time.Duration
+	// BufferSize 每个订阅者每个 topic 的缓冲事件数；0 走默认 16。
+	BufferSize int
+	// SendTimeout 单个订阅者缓冲满时发布方的最长等待，超时丢弃该订阅者的这条事件并计入 Dropped；0 走默认 50ms。
+	SendTimeout time.Duration
+}
+
+const (
+	defaultHistorySize  = 100
+	defaultPingInterval = 15 * time.Second
+	defaultBufferSize   = 16
+	defaultSendTimeout  = 50 * time.Millisecond
+)
+
+// Broker 管理多个 SSE 连接的按 topic 扇出。
+// Publish 未指定 ID 时自动分配全局递增 ID；每个 topic 保留有限历史，
+// 客户端携带 Last-Event-ID 重连时补发其后的事件。
+// 没有订阅者且没有历史的 topic 会被回收，客户端通过 ?topic= 订阅任意名称不会让 topic 无限增长。
+type Broker struct {
+	opt BrokerOption
+
+	// mu 保护 topics；需要同时持有 topic.mu 时先取 mu
+	mu      sync.Mutex
+	topics  map[string]*topic
+	seq     uint64
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+type topic struct {
+	// mu 保护订阅者集合与历史，保证订阅时取到的历史与之后的实时流之间不重不漏；
+	// 投递在锁外进行，慢订阅者不会阻塞新的订阅
+	mu      sync.Mutex
+	subs    map[*subscriber]struct{}
+	history []record
+	removed chan struct{}
+	closed  bool
+}
+
+// subscriber 一个连接的订阅，多个 topic 共用同一个 ch。
+type subscriber struct {
+	ch   chan record
+	done chan struct{}
+}
+
+type record struct {
+	seq   uint64
+	event Event
+}
+
+func NewBroker(opts ...BrokerOption) *Broker {
+	opt := BrokerOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.HistorySize == 0 {
+		opt.HistorySize = defaultHistorySize
+	}
+	if opt.PingInterval == 0 {
+		opt.PingInterval = defaultPingInterval
+	}
+	if opt.BufferSize <= 0 {
+		opt.BufferSize = defaultBufferSize
+	}
+	if opt.SendTimeout <= 0 {
+		opt.SendTimeout = defaultSendTimeout
+	}
+	return &Broker{
+		opt:    opt,
+		topics: map[string]*topic{},
+		done:   make(chan struct{}),
+	}
+}
+
+// lockTopic 取出（必要时创建）topic 并持有其锁返回；Broker 已关闭时返回 nil。
+func (b *Broker) lockTopic(name string) *topic {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	t, ok := b.topics[name]
+	if !ok {
+		t = &topic{subs: map[*subscriber]struct{}{}, removed: make(chan struct{})}
+		b.topics[name] = t
+	}
+	t.mu.Lock()
+	return t
+}
+
+// Publish 向 topic 发布事件，返回事件 ID（event.ID 为空时自动分配）。Broker 关闭后调用无效果。
+// 事件依次投递给当时的订阅者，缓冲满的订阅者最多等待 SendTimeout，超时丢弃并计入 Dropped。
+// 并发调用 Publish 时同一 topic 的实时投递顺序不确定，补发的历史始终按发布顺序。
+func (b *Broker) Publish(topicName string, event Event) string {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return event.ID
+	}
+	b.seq++
+	seq := b.seq
+	b.mu.Unlock()
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(seq, 10)
+	}
+
+	t := b.lockTopic(topicName)
+	if t == nil {
+		return event.ID
+	}
+	rec := record{seq: seq, event: event}
+	if b.opt.HistorySize > 0 {
+		if len(t.history) >= b.opt.HistorySize {
+			copy(t.history, t.history[1:])
+			t.history = t.history[:len(t.history)-1]
+		}
+		t.history = append(t.history, rec)
+	}
+	subs := make([]*subscriber, 0, len(t.subs))
+	for sub := range t.subs {
+		subs = append(subs, sub)
+	}
+	t.mu.Unlock()
+	if len(subs) == 0 && b.opt.HistorySize <= 0 {
+		b.release(topicName, t)
+	}
+
+	b.deliver(subs, rec)
+	return event.ID
+}
+
+// deliver 在锁外把 rec 投递给 subs。
+func (b *Broker) deliver(subs []*subscriber, rec record) {
+	timer := time.NewTimer(b.opt.SendTimeout)
+	defer timer.Stop()
+	for _, sub := range subs {
+		select {
+		case sub.ch <- rec:
+			continue
+		case <-sub.done:
+			continue
+		default:
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(b.opt.SendTimeout)
+		select {
+		case sub.ch <- rec:
+		case <-sub.done:
+		case <-timer.C:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped 返回因订阅者缓冲满、超过 SendTimeout 而丢弃的事件数。
+func (b *Broker) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Subscribers 返回 topic 当前的订阅连接数。
+func (b *Broker) Subscribers(topicName string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t, ok := b.topics[topicName]
+	if !ok {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.subs)
+}
+
+// Topics 返回当前存在的 topic 数。
+func (b *Broker) Topics() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.topics)
+}
+
+// release 在 topic 没有订阅者也没有历史时回收它。
+func (b *Broker) release(name string, t *topic) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.subs) == 0 && len(t.history) == 0 && b.topics[name] == t {
+		delete(b.topics, name)
+	}
+}
+
+// close 断开 topic 的全部订阅。
+func (t *topic) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.closed {
+		t.closed = true
+		t.subs = nil
+		close(t.removed)
+	}
+}
+
+// RemoveTopic 断开 topic 的全部订阅并丢弃其历史。
+func (b *Broker) RemoveTopic(topicName string) {
+	b.mu.Lock()
+	t, ok := b.topics[topicName]
+	delete(b.topics, topicName)
+	b.mu.Unlock()
+	if ok {
+		t.close()
+	}
+}
+
+// Close 关闭 Broker，所有 Serve 返回。
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	close(b.done)
+	topics := b.topics
+	b.topics = map[string]*topic{}
+	b.mu.Unlock()
+	for _, t := range topics {
+		t.close()
+	}
+}
+
+// Handler 返回订阅 topics 的 handler；topics 为空时取查询参数 ?topic=a&topic=b。
+func (b *Broker) Handler(topics ...string) web.HandlerFunc {
+	return func(c *web.Context) {
+		ts := topics
+		if len(ts) == 0 {
+			ts = c.Request.URL.Query()["topic"]
+		}
+		if len(ts) == 0 {
+			http.Error(c.Writer, "missing topic", http.StatusBadRequest)
+			return
+		}
+		_ = b.Serve(c, ts...)
+	}
+}
+
+// Serve 将当前请求升级为 SSE 流并订阅 topics，阻塞直到客户端断开、请求 ctx 取消
+// （含 GOweb 优雅关停）或 Broker 关闭；写失败时返回错误。
+func (b *Broker) Serve(c *web.Context, topics ...string) error {
+	conn, err := NewConn(c)
+	if err != nil {
+		return err
+	}
+	events, removed, replay, cancel := b.subscribe(topics, conn.LastEventID())
+	defer cancel()
+
+	for _, rec := range replay {
+		if err := conn.SendEvent(rec.event); err != nil {
+			return err
+		}
+	}
+
+	var ping <-chan time.Time
+	if b.opt.PingInterval > 0 {
+		ticker := time.NewTicker(b.opt.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	for {
+		select {
+		case rec := <-events:
+			if err := conn.SendEvent(rec.event); err != nil {
+				return err
+			}
+		case <-ping:
+			if err := conn.Ping(); err != nil {
+				return err
+			}
+		case <-c.Done():
+			return nil
+		case <-removed:
+			return nil
+		case <-b.done:
+			return nil
+		}
+	}
+}
+
+// subscribe 订阅多个 topic 并合并为一个 channel，同时取出 lastID 之后的历史（按 seq 排序）。
+// 任一 topic 被移除时 removed 关闭。
+func (b *Broker) subscribe(topics []string, lastID string) (events <-chan record, removed <-chan struct{}, replay []record, cancel func()) {
+	sub := &subscriber{ch: make(chan record, b.opt.BufferSize*len(topics)), done: make(chan struct{})}
+	gone := make(chan struct{})
+	var (
+		histories [][]record
+		joined    = map[string]*topic{}
+		wg        sync.WaitGroup
+		goneOnce  sync.Once
+	)
+	for _, name := range topics {
+		if _, ok := joined[name]; ok {
+			continue
+		}
+		t := b.lockTopic(name)
+		if t == nil {
+			goneOnce.Do(func() { close(gone) })
+			break
+		}
+		t.subs[sub] = struct{}{}
+		if lastID != "" {
+			histories = append(histories, append([]record(nil), t.history...))
+		}
+		t.mu.Unlock()
+		joined[name] = t
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-t.removed:
+				// 被 RemoveTopic / Close 移除
+				goneOnce.Do(func() { close(gone) })
+			case <-sub.done:
+			}
+		}()
+	}
+
+	cancel = func() {
+		close(sub.done)
+		wg.Wait()
+		for name, t := range joined {
+			t.mu.Lock()
+			delete(t.subs, sub)
+			t.mu.Unlock()
+			b.release(name, t)
+		}
+	}
+	return sub.ch, gone, replayAfter(histories, lastID), cancel
+}
+
+// replayAfter 在各 topic 历史中定位 lastID 对应的 seq，返回其后的事件；
+// 找不到时若 lastID 为自动分配的数字 ID 则按数值比较，否则补发全部缓存。
+func replayAfter(histories [][]record, lastID string) []record {
+	if lastID == "" {
+		return nil
+	}
+	var after uint64
+	found := false
+	for _, h := range histories {
+		for _, rec := range h {
+			if rec.event.ID == lastID {
+				after, found = rec.seq, true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		if n, err := strconv.ParseUint(lastID, 10, 64); err == nil {
+			after = n
+		}
+	}
+
+	var out []record
+	for _, h := range histories {
+		for _, rec := range h {
+			if rec.seq > after {
+				out = append(out, rec)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].seq < out[j].seq })
+	return out
+}
@@ -0,0 +1,230 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+func brokerServer(t *testing.T, b *Broker) *httptest.Server {
+	g := web.New()
+	g.GET("/events", b.Handler())
+	srv := httptest.NewServer(g)
+	t.Cleanup(srv.Close)
+	t.Cleanup(b.Close)
+	return srv
+}
+
+type sseClient struct {
+	resp *http.Response
+	r    *bufio.Reader
+}
+
+func connect(t *testing.T, srv *httptest.Server, query, lastID string) *sseClient {
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/events?"+query, nil)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return &sseClient{resp: resp, r: bufio.NewReader(resp.Body)}
+}
+
+// next 读取下一个以空行结尾的块，并去掉结尾空行。
+func (c *sseClient) next(t *testing.T) string {
+	t.Helper()
+	var b strings.Builder
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v (got %q)", err, b.String())
+		}
+		if line == "\n" {
+			return b.String()
+		}
+		b.WriteString(line)
+	}
+}
+
+func waitSubscribers(t *testing.T, b *Broker, topic string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Subscribers(topic) != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Subscribers(%q) = %d, want %d", topic, b.Subscribers(topic), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBrokerPublishFanOut(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1})
+	srv := brokerServer(t, b)
+
+	c1 := connect(t, srv, "topic=news", "")
+	c2 := connect(t, srv, "topic=news&topic=sport", "")
+	waitSubscribers(t, b, "news", 2)
+	waitSubscribers(t, b, "sport", 1)
+
+	if id := b.Publish("news", Event{Event: "update", Data: "hello"}); id != "1" {
+		t.Fatalf("Publish() id = %q, want 1", id)
+	}
+	b.Publish("sport", Event{ID: "custom", Data: "goal"})
+
+	if got := c1.next(t); got != "id: 1\nevent: update\ndata: hello\n" {
+		t.Fatalf("c1 got %q", got)
+	}
+	// 不同 topic 的实时事件之间不保证顺序
+	got := map[string]bool{c2.next(t): true, c2.next(t): true}
+	if !got["id: 1\nevent: update\ndata: hello\n"] || !got["id: custom\ndata: goal\n"] {
+		t.Fatalf("c2 got %v", got)
+	}
+}
+
+func TestBrokerReplayAfterLastEventID(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1, HistorySize: 3})
+	srv := brokerServer(t, b)
+
+	b.Publish("a", Event{Data: "1"})
+	b.Publish("b", Event{Data: "2"})
+	b.Publish("a", Event{ID: "x", Data: "3"})
+	b.Publish("b", Event{Data: "4"})
+
+	// 自定义 ID 按其发布位置定位，两个 topic 的补发按发布顺序合并
+	c := connect(t, srv, "topic=a&topic=b", "x")
+	if got := c.next(t); got != "id: 4\ndata: 4\n" {
+		t.Fatalf("replay got %q", got)
+	}
+
+	c = connect(t, srv, "topic=a&topic=b", "1")
+	for _, want := range []string{"id: 2\ndata: 2\n", "id: x\ndata: 3\n", "id: 4\ndata: 4\n"} {
+		if got := c.next(t); got != want {
+			t.Fatalf("replay got %q, want %q", got, want)
+		}
+	}
+
+	// 补发之后继续接收实时事件
+	waitSubscribers(t, b, "a", 2)
+	b.Publish("a", Event{Data: "5"})
+	if got := c.next(t); got != "id: 5\ndata: 5\n" {
+		t.Fatalf("live got %q", got)
+	}
+}
+
+func TestBrokerHistoryBounded(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1, HistorySize: 2})
+	srv := brokerServer(t, b)
+
+	for range 5 {
+		b.Publish("a", Event{Data: "d"})
+	}
+	c := connect(t, srv, "topic=a", "1")
+	for _, want := range []string{"id: 4\ndata: d\n", "id: 5\ndata: d\n"} {
+		if got := c.next(t); got != want {
+			t.Fatalf("replay got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestBrokerPing(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: 10 * time.Millisecond})
+	srv := brokerServer(t, b)
+
+	c := connect(t, srv, "topic=a", "")
+	if got := c.next(t); got != ": ping\n" {
+		t.Fatalf("got %q, want ping", got)
+	}
+}
+
+func TestBrokerCloseEndsStreams(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1})
+	srv := brokerServer(t, b)
+
+	c := connect(t, srv, "topic=a", "")
+	waitSubscribers(t, b, "a", 1)
+	b.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadAll(c.r)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed after Broker.Close")
+	}
+}
+
+func TestBrokerHandlerRequiresTopic(t *testing.T) {
+	b := NewBroker()
+	srv := brokerServer(t, b)
+
+	c := connect(t, srv, "", "")
+	if c.resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", c.resp.StatusCode)
+	}
+}
+
+func TestBrokerReleasesIdleTopics(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1})
+	srv := brokerServer(t, b)
+
+	c := connect(t, srv, "topic=x&topic=y", "")
+	waitSubscribers(t, b, "x", 1)
+	if n := b.Topics(); n != 2 {
+		t.Fatalf("Topics() = %d, want 2", n)
+	}
+	b.Publish("y", Event{Data: "kept"})
+	c.resp.Body.Close()
+	waitSubscribers(t, b, "x", 0)
+
+	// x 没有订阅者也没有历史被回收，y 保留历史供补发
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Topics() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Topics() = %d, want 1", b.Topics())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBrokerSlowSubscriberDoesNotBlockSubscribe(t *testing.T) {
+	b := NewBroker(BrokerOption{PingInterval: -1, BufferSize: 1, SendTimeout: 200 * time.Millisecond})
+	t.Cleanup(b.Close)
+
+	// 不读取的订阅者：缓冲 1 条，之后每次投递都等满 SendTimeout
+	_, _, _, cancel := b.subscribe([]string{"a"}, "")
+	defer cancel()
+	b.Publish("a", Event{Data: "fill"})
+
+	published := make(chan struct{})
+	go func() {
+		b.Publish("a", Event{Data: "blocked"})
+		close(published)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	start := time.Now()
+	_, _, _, cancel2 := b.subscribe([]string{"a"}, "")
+	cancel2()
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Fatalf("subscribe blocked %v behind a slow delivery", d)
+	}
+	<-published
+	if b.Dropped() != 1 {
+		t.Fatalf("Dropped() = %d, want 1", b.Dropped())
+	}
+}
@@ -51,6 +51,12 @@ func NewConn(ctx *web.Context, deadline ...time.Time) (*Conn, error) {
 	ctx.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 	flusher.Flush()
 
+	// 优雅关停时取消请求 ctx，handler 观察 ctx.Done() 退出；请求结束时同步注销。
+	// 捕获 cancel 而不是 ctx.Stop：Context 在请求结束后会被回收复用。
+	if ctx.Context != nil {
+		ctx.OnFinish(ctx.TrackConn(ctx.CancelFunc()))
+	}
+
 	return &Conn{ctx, flusher}, nil
 }
 
@@ -1,6 +1,7 @@
 package sse
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -167,6 +168,48 @@ func TestNewConnSetsSSEHeaders(t *testing.T) {
 	}
 }
 
+func TestNewConnUntracksOnRequestEnd(t *testing.T) {
+	g := web.New()
+	g.GET("/events", func(ctx *web.Context) {
+		if _, err := NewConn(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+	g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+
+	// handler 返回时已同步注销，关停不用等待，也不会再调用已回收 Context 的取消函数
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestNewConnStoppedByShutdown(t *testing.T) {
+	g := web.New()
+	started := make(chan struct{})
+	g.GET("/events", func(ctx *web.Context) {
+		if _, err := NewConn(ctx); err != nil {
+			t.Error(err)
+		}
+		close(started)
+		<-ctx.Done()
+	})
+	done := make(chan struct{})
+	go func() {
+		g.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events", nil))
+		close(done)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := g.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	<-done
+}
+
 type stringResponseWriter struct {
 	header   http.Header
 	body     string
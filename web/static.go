@@ -0,0 +1,191 @@
+package web
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type StaticOption struct {
+	// Index 目录索引文件；空走默认 index.html。
+	Index string
+	// SPA 未命中文件时回落到根目录的 Index（带扩展名的资源请求仍 404），用于前端路由。
+	SPA bool
+	// Precompressed 按 Accept-Encoding 优先返回同目录的 .br / .gz 预压缩文件。
+	Precompressed bool
+	// MaxAge 非空时下发 Cache-Control: public, max-age；Index 始终为 no-cache。
+	MaxAge time.Duration
+}
+
+const defaultStaticIndex = "index.html"
+
+// Static 将本地目录 root 挂载到 path 下，见 StaticFS。
+func (g *RouterGroup) Static(path, root string, opts ...StaticOption) {
+	g.StaticFS(path, os.DirFS(root), opts...)
+}
+
+// StaticFS 将 fsys（可为 embed.FS，需先 fs.Sub 到资源目录）挂载到 path + "/#..."，注册 GET（HEAD 自动复用）。
+// 基于 http.ServeContent：支持 Range / If-Range、ETag / If-None-Match、Last-Modified / If-Modified-Since；
+// embed.FS 没有修改时间，ETag 改用内容哈希。目录请求补全结尾 "/" 后返回 Index，不提供目录列表。
+func (g *RouterGroup) StaticFS(path string, fsys fs.FS, opts ...StaticOption) {
+	opt := StaticOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Index == "" {
+		opt.Index = defaultStaticIndex
+	}
+	s := &staticServer{fsys: fsys, opt: opt}
+	if opt.MaxAge > 0 {
+		s.cacheControl = "public, max-age=" + strconv.Itoa(int(opt.MaxAge/time.Second))
+	}
+	g.GET(strings.TrimSuffix(path, "/")+"/"+catchAllSegment, s.serve)
+}
+
+type staticServer struct {
+	fsys         fs.FS
+	opt          StaticOption
+	cacheControl string
+	// etags 缓存无修改时间文件的内容哈希
+	etags sync.Map
+}
+
+func (s *staticServer) serve(c *Context) {
+	name := path.Clean("/" + c.GetUrlPathParam(paramPrefix))[1:]
+	if name == "" {
+		name = "."
+	}
+
+	// requested 为补全 index 前的路径，SPA 回落按它判断是否像静态资源
+	requested := name
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() {
+		if !strings.HasSuffix(c.Request.URL.Path, "/") {
+			redirectSlash(c)
+			return
+		}
+		name = path.Join(name, s.opt.Index)
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() {
+		if s.opt.SPA && path.Ext(requested) == "" {
+			if info, err = fs.Stat(s.fsys, s.opt.Index); err == nil && !info.IsDir() {
+				s.serveFile(c, s.opt.Index, info)
+				return
+			}
+		}
+		http.NotFound(c.Writer, c.Request)
+		return
+	}
+	s.serveFile(c, name, info)
+}
+
+func redirectSlash(c *Context) {
+	u := *c.Request.URL
+	u.Path += "/"
+	http.Redirect(c.Writer, c.Request, u.String(), http.StatusMovedPermanently)
+}
+
+func (s *staticServer) serveFile(c *Context, name string, info fs.FileInfo) {
+	h := c.Writer.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		h.Set("Content-Type", ctype)
+	}
+	if path.Base(name) == s.opt.Index {
+		h.Set("Cache-Control", "no-cache")
+	} else if s.cacheControl != "" {
+		h.Set("Cache-Control", s.cacheControl)
+	}
+
+	if s.opt.Precompressed {
+		h.Add("Vary", "Accept-Encoding")
+		if enc, sibling, sinfo := s.precompressed(c.Request, name); sibling != "" {
+			h.Set("Content-Encoding", enc)
+			name, info = sibling, sinfo
+		}
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		http.NotFound(c.Writer, c.Request)
+		return
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(c.Writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	etag, err := s.etag(name, info, content)
+	if err != nil {
+		http.Error(c.Writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	h.Set("ETag", etag)
+	http.ServeContent(c.Writer, c.Request, name, info.ModTime(), content)
+}
+
+// precompressed 按客户端支持顺序 br > gzip 查找预压缩文件。
+func (s *staticServer) precompressed(r *http.Request, name string) (encoding, sibling string, info fs.FileInfo) {
+	accept := r.Header.Get("Accept-Encoding")
+	for _, c := range [...]struct{ enc, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(accept, c.enc) {
+			continue
+		}
+		if fi, err := fs.Stat(s.fsys, name+c.ext); err == nil && !fi.IsDir() {
+			return c.enc, name + c.ext, fi
+		}
+	}
+	return "", "", nil
+}
+
+func acceptsEncoding(header, enc string) bool {
+	for _, part := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(token), enc) {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// etag 有修改时间时取 mtime+size，否则取内容 sha1（按文件名缓存）。
+func (s *staticServer) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if !info.ModTime().IsZero() {
+		return `"` + strconv.FormatInt(info.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36) + `"`, nil
+	}
+	if v, ok := s.etags.Load(name); ok {
+		return v.(string), nil
+	}
+	h := sha1.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+	s.etags.Store(name, etag)
+	return etag, nil
+}
@@ -0,0 +1,112 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func staticFS() fstest.MapFS {
+	mod := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return fstest.MapFS{
+		"index.html":       {Data: []byte("<html>root</html>")},
+		"app.js":           {Data: []byte("console.log(1)"), ModTime: mod},
+		"app.js.gz":        {Data: []byte("gzipped"), ModTime: mod},
+		"app.js.br":        {Data: []byte("brotli"), ModTime: mod},
+		"docs/index.html":  {Data: []byte("docs")},
+		"empty/readme.txt": {Data: []byte("x")},
+	}
+}
+
+func doStatic(g *GOweb, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStaticFS(t *testing.T) {
+	g := New()
+	g.StaticFS("/assets", staticFS(), StaticOption{MaxAge: time.Hour})
+
+	rec := doStatic(g, "/assets/app.js", nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1)" {
+		t.Fatalf("code=%d body=%q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Last-Modified") == "" || rec.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Fatalf("headers = %v", rec.Header())
+	}
+	etag := rec.Header().Get("ETag")
+
+	if rec := doStatic(g, "/assets/app.js", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Fatalf("conditional code=%d", rec.Code)
+	}
+	rec = doStatic(g, "/assets/app.js", map[string]string{"Range": "bytes=0-6"})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "console" {
+		t.Fatalf("range code=%d body=%q", rec.Code, rec.Body.String())
+	}
+
+	// 目录：补 "/" 重定向，再返回 index；无 index 的目录 404，不列目录。
+	if rec := doStatic(g, "/assets/docs", nil); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/assets/docs/" {
+		t.Fatalf("redirect code=%d loc=%q", rec.Code, rec.Header().Get("Location"))
+	}
+	rec = doStatic(g, "/assets/docs/", nil)
+	if rec.Body.String() != "docs" || rec.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("index body=%q headers=%v", rec.Body.String(), rec.Header())
+	}
+	// 无修改时间（embed.FS）时 ETag 取内容哈希。
+	if rec.Header().Get("ETag") == "" {
+		t.Fatal("missing content etag")
+	}
+	if rec := doStatic(g, "/assets/empty/", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("dir without index code=%d", rec.Code)
+	}
+	if rec := doStatic(g, "/assets/../static_test.go", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("traversal code=%d", rec.Code)
+	}
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	g := New()
+	g.StaticFS("/", staticFS(), StaticOption{Precompressed: true})
+
+	cases := []struct{ accept, enc, body string }{
+		{"gzip, br", "br", "brotli"},
+		{"gzip", "gzip", "gzipped"},
+		{"br;q=0, gzip", "gzip", "gzipped"},
+		{"", "", "console.log(1)"},
+	}
+	for _, tc := range cases {
+		rec := doStatic(g, "/app.js", map[string]string{"Accept-Encoding": tc.accept})
+		if rec.Header().Get("Content-Encoding") != tc.enc || rec.Body.String() != tc.body {
+			t.Fatalf("accept=%q enc=%q body=%q", tc.accept, rec.Header().Get("Content-Encoding"), rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/javascript; charset=utf-8" {
+			t.Fatalf("content-type = %q", ct)
+		}
+	}
+}
+
+func TestStaticSPA(t *testing.T) {
+	g := New()
+	g.GET("/api/ping", func(c *Context) { c.WriteString("pong") })
+	g.StaticFS("/", staticFS(), StaticOption{SPA: true})
+
+	if rec := doStatic(g, "/api/ping", nil); rec.Body.String() != "pong" {
+		t.Fatalf("api body=%q", rec.Body.String())
+	}
+	// 真实存在但没有 index 的目录同样回落到根 index
+	for _, p := range []string{"/", "/users/42", "/empty/"} {
+		if rec := doStatic(g, p, nil); rec.Code != http.StatusOK || rec.Body.String() != "<html>root</html>" {
+			t.Fatalf("%s code=%d body=%q", p, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := doStatic(g, "/missing.js", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("missing asset code=%d", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var ErrRouteNotFound = errors.New("[web] named route not found")
+
+// URLFor 按 HandlerOpt.Name 反向生成路径：`#name` / `#name:type` 取 params[name]，
+// `#...` 取 params["#"]，各段做 PathEscape。缺参或不满足约束时返回错误，未用到的参数忽略。
+// 路由名在默认路由树与各虚拟主机间唯一；虚拟主机路由返回相对该主机的路径，不含主机名。
+func (g *GOweb) URLFor(name string, params map[string]string) (string, error) {
+	if g.host == nil {
+		return "", ErrRouteNotFound
+	}
+	snap := g.host.snapshot.Load()
+	if snap == nil {
+		return "", ErrRouteNotFound
+	}
+	leaf, ok := snap.names[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+	return buildURL(leaf, params)
+}
+
+// MustURLFor 同 URLFor，出错时 panic；适合模板或启动期常量拼接。
+func (g *GOweb) MustURLFor(name string, params map[string]string) string {
+	u, err := g.URLFor(name, params)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+func buildURL(leaf *RouterGroup, params map[string]string) (string, error) {
+	var chain []*RouterGroup
+	for n := leaf; n.parent != nil; n = n.parent {
+		chain = append(chain, n)
+	}
+	if len(chain) == 0 {
+		return "/", nil
+	}
+	var sb strings.Builder
+	for i := len(chain) - 1; i >= 0; i-- {
+		n := chain[i]
+		switch n.kind {
+		case kindParam:
+			v, ok := params[n.paramName]
+			if !ok || v == "" {
+				return "", fmt.Errorf("[web] URLFor %s: missing param %q", leaf.completePath(), n.paramName)
+			}
+			if n.constraint != nil && !n.constraint.check(v) {
+				return "", fmt.Errorf("[web] URLFor %s: param %q=%q does not satisfy %s",
+					leaf.completePath(), n.paramName, v, n.constraint.spec)
+			}
+			sb.WriteByte('/')
+			sb.WriteString(url.PathEscape(v))
+		case kindCatchAll:
+			v, ok := params[paramPrefix]
+			if !ok {
+				return "", fmt.Errorf("[web] URLFor %s: missing param %q", leaf.completePath(), paramPrefix)
+			}
+			for _, seg := range splitSegments(v) {
+				sb.WriteByte('/')
+				sb.WriteString(url.PathEscape(seg))
+			}
+		default:
+			for _, seg := range n.segments {
+				sb.WriteByte('/')
+				sb.WriteString(url.PathEscape(seg))
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return "/", nil
+	}
+	return sb.String(), nil
+}
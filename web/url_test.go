@@ -0,0 +1,105 @@
+package web
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestURLFor(t *testing.T) {
+	g := New()
+	g.GET("/", func(ctx *Context) {}, HandlerOpt{Name: "home"})
+	g.GET("/users/#id:int", func(ctx *Context) {}, HandlerOpt{Name: "user"})
+	api := g.Grep("/api")
+	api.GET("/files/#...", func(ctx *Context) {}, HandlerOpt{Name: "file"})
+	g.GET("/tags/#tag", func(ctx *Context) {}, HandlerOpt{Name: "tag"})
+
+	cases := []struct {
+		name   string
+		params map[string]string
+		want   string
+	}{
+		{"home", nil, "/"},
+		{"user", map[string]string{"id": "42", "extra": "x"}, "/users/42"},
+		{"file", map[string]string{"#": "a b/c"}, "/api/files/a%20b/c"},
+		{"tag", map[string]string{"tag": "go/lang"}, "/tags/go%2Flang"},
+	}
+	for _, c := range cases {
+		got, err := g.URLFor(c.name, c.params)
+		if err != nil || got != c.want {
+			t.Fatalf("URLFor(%s) = %q, %v; want %q", c.name, got, err, c.want)
+		}
+	}
+}
+
+func TestURLForErrors(t *testing.T) {
+	g := New()
+	g.GET("/users/#id:int", func(ctx *Context) {}, HandlerOpt{Name: "user"})
+
+	if _, err := g.URLFor("nope", nil); !errors.Is(err, ErrRouteNotFound) {
+		t.Fatalf("unknown name: %v", err)
+	}
+	if _, err := g.URLFor("user", nil); err == nil {
+		t.Fatal("expected missing param error")
+	}
+	if _, err := g.URLFor("user", map[string]string{"id": "abc"}); err == nil {
+		t.Fatal("expected constraint error")
+	}
+}
+
+func TestURLForSurvivesEdgeSplit(t *testing.T) {
+	g := New()
+	g.GET("/api/v1/users", func(ctx *Context) {}, HandlerOpt{Name: "users"})
+	g.GET("/api/v2", func(ctx *Context) {})
+	if got, err := g.URLFor("users", nil); err != nil || got != "/api/v1/users" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	for _, info := range g.List() {
+		if info.Path == "/api/v1/users" && info.Option.Name != "users" {
+			t.Fatalf("option lost after split: %+v", info)
+		}
+	}
+}
+
+func TestDuplicateRouteName(t *testing.T) {
+	g := New()
+	g.GET("/a", func(ctx *Context) {}, HandlerOpt{Name: "n"})
+	// 同一路径不同方法可复用同名。
+	g.POST("/a", func(ctx *Context) {}, HandlerOpt{Name: "n"})
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate name")
+		}
+	}()
+	g.GET("/b", func(ctx *Context) {}, HandlerOpt{Name: "n"})
+}
+
+func TestDuplicateRouteNameAcrossHosts(t *testing.T) {
+	expectPanic := func(name string, register func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("%s: expected panic on duplicate name", name)
+			}
+		}()
+		register()
+	}
+	g := New()
+	g.Host("a.test").GET("/home", func(ctx *Context) {}, HandlerOpt{Name: "home"})
+	// 同名同路径，但位于另一虚拟主机
+	expectPanic("other host", func() {
+		g.Host("b.test").GET("/home", func(ctx *Context) {}, HandlerOpt{Name: "home"})
+	})
+	expectPanic("main tree", func() {
+		g.GET("/home", func(ctx *Context) {}, HandlerOpt{Name: "home"})
+	})
+	g.GET("/index", func(ctx *Context) {}, HandlerOpt{Name: "index"})
+	expectPanic("host after main", func() {
+		g.Host("#tenant.test").GET("/index", func(ctx *Context) {}, HandlerOpt{Name: "index"})
+	})
+
+	// 同一主机内同路径不同方法仍可复用
+	g.Host("a.test").POST("/home", func(ctx *Context) {}, HandlerOpt{Name: "home"})
+	if u, err := g.URLFor("home", nil); err != nil || u != "/home" {
+		t.Fatalf("URLFor = %q, %v", u, err)
+	}
+}
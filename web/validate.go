@@ -0,0 +1,334 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationErrors 是 Bind / Validate 的字段级错误集合，可直接序列化为 400 响应体。
+type ValidationErrors []*FieldError
+
+// FieldError 描述单个字段的绑定或校验失败。
+//
+// Tag 为失败的规则名（required/min/max/len/regex/oneof），绑定阶段类型转换失败时为 "type"。
+type FieldError struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+	Param string `json:"param,omitempty"`
+	Value any    `json:"-"`
+	Err   error  `json:"-"`
+}
+
+func (e *FieldError) Error() string {
+	if e.Tag == "type" {
+		return fmt.Sprintf("%s: cannot convert %q to %s: %v", e.Field, e.Value, e.Param, e.Err)
+	}
+	if e.Param != "" {
+		return fmt.Sprintf("%s: failed on %s=%s", e.Field, e.Tag, e.Param)
+	}
+	return fmt.Sprintf("%s: failed on %s", e.Field, e.Tag)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+func (es ValidationErrors) Error() string {
+	parts := make([]string, len(es))
+	for i, e := range es {
+		parts[i] = e.Error()
+	}
+	return "[web] validation: " + strings.Join(parts, "; ")
+}
+
+// Rule 是 `validate` tag 中的一条规则，regex / oneof 的参数在解析期预编译。
+type Rule struct {
+	// Name 规则名：required/omitempty/min/max/len/regex/oneof
+	Name string
+	// Param = 之后的原始参数
+	Param string
+	// Num min/max/len 的数值参数
+	Num float64
+	// Regexp regex 编译后的正则
+	Regexp *regexp.Regexp
+	// OneOf oneof 的候选值
+	OneOf []string
+}
+
+// ErrInvalidRule 标记非法的 `validate` tag（编程错误），由 ParseRules / CheckRules / Validate / Bind 返回。
+var ErrInvalidRule = errors.New("[web] invalid validate tag")
+
+// ParseRules 解析字段的 `validate:"required,min=1,max=10,oneof=a b,regex=^x"`，owner 为字段所属 struct，用于错误信息。
+// regex 的参数可能含逗号，因此 regex 必须写在最后，其后整段都视为正则。
+// 非法规则返回包装 ErrInvalidRule 的错误。供 web/openapi 等需要与 Validate 保持一致语义的包复用。
+func ParseRules(owner reflect.Type, sf reflect.StructField) ([]Rule, error) {
+	tag := sf.Tag.Get("validate")
+	if tag == "" || tag == "-" {
+		return nil, nil
+	}
+	fail := func(format string, a ...any) ([]Rule, error) {
+		return nil, fmt.Errorf("%w: %s.%s: %s", ErrInvalidRule, owner.Name(), sf.Name, fmt.Sprintf(format, a...))
+	}
+	var rules []Rule
+	for tag != "" {
+		var item string
+		if strings.HasPrefix(tag, "regex=") {
+			item, tag = tag, ""
+		} else {
+			item, tag, _ = strings.Cut(tag, ",")
+		}
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(item, "=")
+		r := Rule{Name: name, Param: param}
+		switch name {
+		case "required", "omitempty":
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fail("%s 参数必须是数字: %q", name, param)
+			}
+			r.Num = n
+		case "regex":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return fail("非法正则 %q: %v", param, err)
+			}
+			r.Regexp = re
+		case "oneof":
+			r.OneOf = strings.Fields(param)
+			if len(r.OneOf) == 0 {
+				return fail("oneof 缺少候选值")
+			}
+		default:
+			return fail("未知规则 %q", name)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+var rulesErrCache sync.Map // reflect.Type -> error
+
+// CheckRules 检查 v 的类型（含嵌套 struct、struct 切片与 map 值）中的 `validate` tag，
+// 用于在启动期而非首个请求时发现拼写错误。注册路由时 HandlerOpt.Request 非 nil 会自动检查，出错 panic。
+func CheckRules(v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if err, ok := rulesErrCache.Load(t); ok {
+		err, _ := err.(error)
+		return err
+	}
+	err := checkRules(t, make(map[reflect.Type]bool))
+	rulesErrCache.Store(t, err)
+	return err
+}
+
+func checkRules(t reflect.Type, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	if err := structMetaOf(t).err; err != nil {
+		return err
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		ft := sf.Type
+		for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array || ft.Kind() == reflect.Map {
+			ft = ft.Elem()
+		}
+		if !sf.IsExported() || !isNestedStruct(ft) {
+			continue
+		}
+		if err := checkRules(ft, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate 按 `validate` tag 校验 struct（递归进入嵌套 struct、struct 切片与 map 值）。
+// v 可以是 struct 或其指针；全部通过返回 nil，tag 非法返回 ErrInvalidRule，否则返回 ValidationErrors。
+func Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	if err := CheckRules(v); err != nil {
+		return err
+	}
+	var errs ValidationErrors
+	validateStruct(rv, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *ValidationErrors) {
+	meta := structMetaOf(rv.Type())
+	for i := range meta.fields {
+		f := &meta.fields[i]
+		fv := rv.Field(f.index)
+		name := prefix + f.label
+		for _, r := range f.rules {
+			if r.Name == "omitempty" {
+				if fv.IsZero() {
+					break
+				}
+				continue
+			}
+			if !r.check(fv) {
+				*errs = append(*errs, &FieldError{Field: name, Tag: r.Name, Param: r.Param, Value: safeInterface(fv)})
+				// 同一字段只报第一条失败规则。
+				break
+			}
+		}
+		validateNested(fv, f.childPrefix(prefix), name, errs)
+	}
+}
+
+// validateNested 递归进入 struct / *struct / []struct / map[...]struct。
+func validateNested(fv reflect.Value, structPrefix, name string, errs *ValidationErrors) {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		if isNestedStruct(fv.Type()) {
+			validateStruct(fv, structPrefix, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if !elemMayNest(fv.Type().Elem()) {
+			return
+		}
+		for i := 0; i < fv.Len(); i++ {
+			idx := name + "[" + strconv.Itoa(i) + "]"
+			validateNested(fv.Index(i), idx+".", idx, errs)
+		}
+	case reflect.Map:
+		if !elemMayNest(fv.Type().Elem()) {
+			return
+		}
+		iter := fv.MapRange()
+		for iter.Next() {
+			idx := name + "[" + fmt.Sprint(iter.Key().Interface()) + "]"
+			validateNested(iter.Value(), idx+".", idx, errs)
+		}
+	}
+}
+
+func elemMayNest(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface || isNestedStruct(t)
+}
+
+func safeInterface(v reflect.Value) any {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+func (r *Rule) check(v reflect.Value) bool {
+	if r.Name == "required" {
+		return !isEmptyValue(v)
+	}
+	for v.Kind() == reflect.Pointer {
+		// nil 指针交给 required 判定，其余规则视为通过。
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	switch r.Name {
+	case "min", "max", "len":
+		n, ok := measure(v)
+		if !ok {
+			return true
+		}
+		switch r.Name {
+		case "min":
+			return n >= r.Num
+		case "max":
+			return n <= r.Num
+		default:
+			return n == r.Num
+		}
+	case "regex":
+		if v.Kind() != reflect.String {
+			return true
+		}
+		return r.Regexp.MatchString(v.String())
+	case "oneof":
+		s := scalarString(v)
+		for _, o := range r.OneOf {
+			if s == o {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// measure 数值类型取值本身，字符串取 rune 数，容器取长度。
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Chan:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+func scalarString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	}
+	return fmt.Sprint(safeInterface(v))
+}
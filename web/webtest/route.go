@@ -0,0 +1,63 @@
+package webtest
+
+import (
+	"maps"
+	"net/http"
+)
+
+// RouteCase 一条路由匹配用例，见 Client.ExpectRoutes。
+type RouteCase struct {
+	// Method 为空时为 GET；Host 为空时为 example.com。
+	Method string
+	Host   string
+	Path   string
+	// Pattern 期望命中的注册路径模式，如 /users/#id:int；为空表示期望不命中。
+	Pattern string
+	// Params / HostParams 非 nil 时要求完全相等。
+	Params     map[string]string
+	HostParams map[string]string
+}
+
+// ExpectRoutes 逐条断言路由匹配结果，适合表驱动的路由表测试。
+func (c *Client) ExpectRoutes(cases ...RouteCase) {
+	c.t.Helper()
+	for i, rc := range cases {
+		method := rc.Method
+		if method == "" {
+			method = http.MethodGet
+		}
+		req := c.NewRequest(method, rc.Path)
+		if rc.Host != "" {
+			req.Host(rc.Host)
+		}
+		m, ok := req.Match()
+		name := method + " " + req.host + rc.Path
+		if rc.Pattern == "" {
+			if ok {
+				c.t.Errorf("case %d %s: matched %s, want no match", i, name, m.Path)
+			}
+			continue
+		}
+		if !ok {
+			c.t.Errorf("case %d %s: no match (allowed %v), want %s", i, name, m.Allowed, rc.Pattern)
+			continue
+		}
+		if m.Path != rc.Pattern {
+			c.t.Errorf("case %d %s: matched %s, want %s", i, name, m.Path, rc.Pattern)
+		}
+		if rc.Params != nil && !equalParams(m.Params, rc.Params) {
+			c.t.Errorf("case %d %s: params = %v, want %v", i, name, m.Params, rc.Params)
+		}
+		if rc.HostParams != nil && !equalParams(m.HostParams, rc.HostParams) {
+			c.t.Errorf("case %d %s: host params = %v, want %v", i, name, m.HostParams, rc.HostParams)
+		}
+	}
+}
+
+// equalParams 把 nil 与空 map 视为相等。
+func equalParams(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return maps.Equal(a, b)
+}
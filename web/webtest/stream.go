@@ -0,0 +1,247 @@
+package webtest
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web/sse"
+)
+
+const defaultStreamTimeout = 2 * time.Second
+
+// Stream 在后台 goroutine 中执行请求，逐事件读取 SSE 响应。
+// 在首次 Flush / Write 或 handler 返回后返回，此时状态码与响应头已确定。
+// 测试结束时自动 Close。
+func (r *Request) Stream() *Stream {
+	t := r.client.t
+	t.Helper()
+	ctx, cancel := context.WithCancel(r.ctx)
+	r.ctx = ctx
+	req := r.build()
+
+	pr, pw := io.Pipe()
+	w := &streamWriter{header: http.Header{}, pw: pw, ready: make(chan struct{})}
+	s := &Stream{
+		t:       t,
+		w:       w,
+		pr:      pr,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		events:  make(chan sse.Event, 64),
+		timeout: defaultStreamTimeout,
+	}
+	go func() {
+		defer close(s.done)
+		defer pw.Close()
+		defer w.markReady()
+		r.client.engine.ServeHTTP(w, req)
+	}()
+	go s.read()
+	t.Cleanup(s.Close)
+
+	<-w.ready
+	return s
+}
+
+// Stream 正在进行的 SSE 响应。
+type Stream struct {
+	t       testing.TB
+	w       *streamWriter
+	pr      *io.PipeReader
+	cancel  context.CancelFunc
+	done    chan struct{}
+	stop    chan struct{}
+	once    sync.Once
+	events  chan sse.Event
+	timeout time.Duration
+
+	mu       sync.Mutex
+	comments []string
+}
+
+// Timeout 设置 Next / Expect* 等待单个事件的超时，默认 2s。
+func (s *Stream) Timeout(d time.Duration) *Stream {
+	s.timeout = d
+	return s
+}
+
+func (s *Stream) StatusCode() int { return s.w.statusCode() }
+
+// Header 返回首次 Flush / Write 时的响应头快照。
+func (s *Stream) Header() http.Header { return s.w.sent }
+
+// Next 读取下一个事件；超时或流结束时返回 false。注释行（如心跳 ": ping"）不作为事件返回，见 Comments。
+func (s *Stream) Next() (sse.Event, bool) {
+	timer := time.NewTimer(s.timeout)
+	defer timer.Stop()
+	select {
+	case ev, ok := <-s.events:
+		return ev, ok
+	case <-timer.C:
+		return sse.Event{}, false
+	}
+}
+
+// ExpectEvent 断言下一个事件；want 中的零值字段不参与比较。
+func (s *Stream) ExpectEvent(want sse.Event) sse.Event {
+	s.t.Helper()
+	got, ok := s.Next()
+	if !ok {
+		s.t.Fatalf("webtest: no event within %v, want %+v", s.timeout, want)
+	}
+	if (want.ID != "" && got.ID != want.ID) ||
+		(want.Event != "" && got.Event != want.Event) ||
+		(want.Retry != 0 && got.Retry != want.Retry) ||
+		(want.Data != "" && got.Data != want.Data) {
+		s.t.Errorf("event = %+v, want %+v", got, want)
+	}
+	return got
+}
+
+// ExpectData 断言下一个事件的 data。
+func (s *Stream) ExpectData(data string) sse.Event {
+	s.t.Helper()
+	return s.ExpectEvent(sse.Event{Data: data})
+}
+
+// ExpectEnd 断言流在超时前结束且没有更多事件。
+func (s *Stream) ExpectEnd() {
+	s.t.Helper()
+	if ev, ok := s.Next(); ok {
+		s.t.Errorf("unexpected event %+v, want end of stream", ev)
+	} else if !s.Done() {
+		s.t.Errorf("stream still open after %v", s.timeout)
+	}
+}
+
+// Comments 返回迄今收到的注释行（去掉前导 ": "）。
+func (s *Stream) Comments() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.comments...)
+}
+
+// Done 报告 handler 是否已返回。
+func (s *Stream) Done() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close 取消请求 ctx（模拟客户端断开）并等待 handler 返回；之后的写入返回错误。
+func (s *Stream) Close() {
+	s.once.Do(func() {
+		s.cancel()
+		close(s.stop)
+		s.pr.Close()
+	})
+	<-s.done
+}
+
+// read 按 SSE 规范解析字节流：空行分派事件，没有 data 字段的块不分派。
+func (s *Stream) read() {
+	defer close(s.events)
+	// 读端退出（含解析出错）时关闭管道，避免 handler 阻塞在写入上
+	defer s.pr.Close()
+	sc := bufio.NewScanner(s.pr)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	var (
+		ev   sse.Event
+		data []string
+	)
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" {
+			if data != nil {
+				ev.Data = strings.Join(data, "\n")
+				select {
+				case s.events <- ev:
+				case <-s.stop:
+					return
+				}
+			}
+			ev, data = sse.Event{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			s.mu.Lock()
+			s.comments = append(s.comments, strings.TrimPrefix(line[1:], " "))
+			s.mu.Unlock()
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Event = value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				ev.Retry = time.Duration(ms) * time.Millisecond
+			}
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// streamWriter 经 io.Pipe 把响应交给读取端，实现 http.Flusher。
+type streamWriter struct {
+	header http.Header
+	pw     *io.PipeWriter
+
+	mu     sync.Mutex
+	status int
+	once   sync.Once
+	ready  chan struct{}
+	sent   http.Header
+}
+
+func (w *streamWriter) Header() http.Header { return w.header }
+
+func (w *streamWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	if w.status == 0 {
+		w.status = code
+	}
+	w.mu.Unlock()
+}
+
+func (w *streamWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.markReady()
+	return w.pw.Write(b)
+}
+
+func (w *streamWriter) Flush() {
+	w.WriteHeader(http.StatusOK)
+	w.markReady()
+}
+
+func (w *streamWriter) markReady() {
+	w.once.Do(func() {
+		w.sent = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *streamWriter) statusCode() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
@@ -0,0 +1,313 @@
+// Package webtest 在内存中驱动 GOweb.ServeHTTP，提供链式请求构造、响应断言、
+// SSE 逐事件读取与路由匹配断言，便于编写表驱动的 handler / 路由测试。
+package webtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const defaultHost = "example.com"
+
+// Client 绑定一个 GOweb 与 testing.TB；断言失败经 t.Errorf 报告，构造请求失败经 t.Fatalf 终止。
+// 响应中的 Set-Cookie 会保存并在后续请求中按域名 / 路径回传。
+type Client struct {
+	t      testing.TB
+	engine *web.GOweb
+	header http.Header
+	jar    *cookiejar.Jar
+}
+
+func New(t testing.TB, g *web.GOweb) *Client {
+	jar, _ := cookiejar.New(nil)
+	return &Client{t: t, engine: g, header: http.Header{}, jar: jar}
+}
+
+// SetHeader 设置之后每个请求都携带的默认请求头。
+func (c *Client) SetHeader(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+func (c *Client) GET(path string) *Request    { return c.NewRequest(http.MethodGet, path) }
+func (c *Client) POST(path string) *Request   { return c.NewRequest(http.MethodPost, path) }
+func (c *Client) PUT(path string) *Request    { return c.NewRequest(http.MethodPut, path) }
+func (c *Client) PATCH(path string) *Request  { return c.NewRequest(http.MethodPatch, path) }
+func (c *Client) DELETE(path string) *Request { return c.NewRequest(http.MethodDelete, path) }
+func (c *Client) HEAD(path string) *Request   { return c.NewRequest(http.MethodHead, path) }
+
+// OPTIONS 对应 HTTP OPTIONS 请求。
+func (c *Client) OPTIONS(path string) *Request { return c.NewRequest(http.MethodOptions, path) }
+
+// NewRequest 构造请求；path 可带查询串，也可以是带 Host 的绝对 URL。
+func (c *Client) NewRequest(method, path string) *Request {
+	u, err := url.Parse(path)
+	if err != nil {
+		c.t.Fatalf("webtest: parse %q: %v", path, err)
+	}
+	host := u.Host
+	if host == "" {
+		host = defaultHost
+	}
+	return &Request{
+		client: c,
+		method: method,
+		url:    u,
+		host:   host,
+		header: c.header.Clone(),
+		ctx:    context.Background(),
+	}
+}
+
+// Request 链式构造的请求，调用 Do / Stream / Match 执行。
+type Request struct {
+	client  *Client
+	method  string
+	url     *url.URL
+	host    string
+	header  http.Header
+	body    io.Reader
+	cookies []*http.Cookie
+	ctx     context.Context
+	remote  string
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// Query 追加查询参数。
+func (r *Request) Query(key, value string) *Request {
+	q := r.url.Query()
+	q.Add(key, value)
+	r.url.RawQuery = q.Encode()
+	return r
+}
+
+// Host 设置请求 Host，用于虚拟主机路由。
+func (r *Request) Host(host string) *Request {
+	r.host = host
+	return r
+}
+
+// RemoteAddr 设置直连地址，默认 192.0.2.1:1234。
+func (r *Request) RemoteAddr(addr string) *Request {
+	r.remote = addr
+	return r
+}
+
+func (r *Request) Cookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+func (r *Request) BasicAuth(username, password string) *Request {
+	req := http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	r.header.Set("Authorization", req.Header.Get("Authorization"))
+	return r
+}
+
+// Context 设置请求 ctx，可用于模拟客户端取消。
+func (r *Request) Context(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// Body 设置原始 body 与 Content-Type。
+func (r *Request) Body(contentType string, body io.Reader) *Request {
+	r.body = body
+	if contentType != "" {
+		r.header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func (r *Request) Text(s string) *Request {
+	return r.Body("text/plain; charset=utf-8", strings.NewReader(s))
+}
+
+func (r *Request) JSON(v any) *Request {
+	data, err := json.Marshal(v)
+	if err != nil {
+		r.client.t.Fatalf("webtest: marshal json: %v", err)
+	}
+	return r.Body("application/json", bytes.NewReader(data))
+}
+
+func (r *Request) Form(values url.Values) *Request {
+	return r.Body("application/x-www-form-urlencoded", strings.NewReader(values.Encode()))
+}
+
+// Encode 用指定编解码器编码 body，Content-Type 取其首个媒体类型。
+func (r *Request) Encode(codec web.Codec, v any) *Request {
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, v); err != nil {
+		r.client.t.Fatalf("webtest: encode body: %v", err)
+	}
+	return r.Body(codec.MediaTypes()[0], &buf)
+}
+
+// build 生成 *http.Request 并附上 cookie jar 中的 cookie。
+func (r *Request) build() *http.Request {
+	target := r.url.RequestURI()
+	req := httptest.NewRequest(r.method, target, r.body).WithContext(r.ctx)
+	req.Host = r.host
+	req.Header = r.header.Clone()
+	if r.remote != "" {
+		req.RemoteAddr = r.remote
+	}
+	for _, ck := range r.client.jar.Cookies(r.jarURL()) {
+		req.AddCookie(ck)
+	}
+	for _, ck := range r.cookies {
+		req.AddCookie(ck)
+	}
+	return req
+}
+
+func (r *Request) jarURL() *url.URL {
+	return &url.URL{Scheme: "http", Host: r.host, Path: r.url.Path}
+}
+
+// Do 同步执行请求并返回响应。
+func (r *Request) Do() *Response {
+	r.client.t.Helper()
+	rec := httptest.NewRecorder()
+	r.client.engine.ServeHTTP(rec, r.build())
+	resp := rec.Result()
+	r.client.jar.SetCookies(r.jarURL(), resp.Cookies())
+	return &Response{t: r.client.t, Response: resp, body: rec.Body.Bytes()}
+}
+
+// Match 返回该请求将命中的路由，不执行 handler。
+func (r *Request) Match() (web.RouteMatch, bool) {
+	return r.client.engine.Match(r.method, r.host, r.url.Path)
+}
+
+// Response 已完成的响应；Expect* 断言失败时经 t.Errorf 报告并返回自身以便继续链式调用。
+type Response struct {
+	*http.Response
+	t    testing.TB
+	body []byte
+}
+
+func (r *Response) Body() []byte { return r.body }
+func (r *Response) Text() string { return string(r.body) }
+
+// DecodeJSON 将 body 解码到 v，失败时终止测试。
+func (r *Response) DecodeJSON(v any) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.body, v); err != nil {
+		r.t.Fatalf("webtest: decode json: %v; body = %q", err, r.body)
+	}
+}
+
+func (r *Response) ExpectStatus(code int) *Response {
+	r.t.Helper()
+	if r.StatusCode != code {
+		r.t.Errorf("status = %d, want %d; body = %q", r.StatusCode, code, truncate(r.body))
+	}
+	return r
+}
+
+// ExpectHeader 断言响应头的首个值。
+func (r *Response) ExpectHeader(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Header.Get(key); got != value {
+		r.t.Errorf("header %s = %q, want %q", key, got, value)
+	}
+	return r
+}
+
+func (r *Response) ExpectHeaderContains(key, substr string) *Response {
+	r.t.Helper()
+	if got := strings.Join(r.Header.Values(key), ", "); !strings.Contains(got, substr) {
+		r.t.Errorf("header %s = %q, want contains %q", key, got, substr)
+	}
+	return r
+}
+
+func (r *Response) ExpectNoHeader(key string) *Response {
+	r.t.Helper()
+	if got, ok := r.Header[http.CanonicalHeaderKey(key)]; ok {
+		r.t.Errorf("header %s = %q, want absent", key, got)
+	}
+	return r
+}
+
+func (r *Response) ExpectBody(body string) *Response {
+	r.t.Helper()
+	if string(r.body) != body {
+		r.t.Errorf("body = %q, want %q", truncate(r.body), body)
+	}
+	return r
+}
+
+func (r *Response) ExpectBodyContains(substr string) *Response {
+	r.t.Helper()
+	if !bytes.Contains(r.body, []byte(substr)) {
+		r.t.Errorf("body = %q, want contains %q", truncate(r.body), substr)
+	}
+	return r
+}
+
+// ExpectJSON 语义比较 JSON body：want 可以是任意可序列化的值或 JSON 字符串 / []byte，
+// 双方都解码为通用结构后比较，字段顺序与空白不影响结果。
+func (r *Response) ExpectJSON(want any) *Response {
+	r.t.Helper()
+	var wantData []byte
+	switch w := want.(type) {
+	case string:
+		wantData = []byte(w)
+	case []byte:
+		wantData = w
+	default:
+		data, err := json.Marshal(want)
+		if err != nil {
+			r.t.Fatalf("webtest: marshal want: %v", err)
+		}
+		wantData = data
+	}
+	var got, exp any
+	if err := json.Unmarshal(r.body, &got); err != nil {
+		r.t.Errorf("body is not JSON: %v; body = %q", err, truncate(r.body))
+		return r
+	}
+	if err := json.Unmarshal(wantData, &exp); err != nil {
+		r.t.Fatalf("webtest: want is not JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, exp) {
+		r.t.Errorf("json body = %s, want %s", compactJSON(r.body), compactJSON(wantData))
+	}
+	return r
+}
+
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
+
+func truncate(b []byte) string {
+	const limit = 512
+	if len(b) > limit {
+		return string(b[:limit]) + fmt.Sprintf("...(%d bytes)", len(b))
+	}
+	return string(b)
+}
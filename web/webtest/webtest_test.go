@@ -0,0 +1,164 @@
+package webtest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+	"github.com/Rehtt/Kit/web/sse"
+)
+
+type user struct {
+	ID   int    `json:"id" path:"id"`
+	Name string `json:"name" form:"name" validate:"required"`
+}
+
+func newEngine() *web.GOweb {
+	g := web.New()
+	g.POST("/users/#id:int", func(c *web.Context) {
+		var u user
+		if err := c.Bind(&u); err != nil {
+			c.WriteJSON(map[string]string{"error": err.Error()}, http.StatusBadRequest)
+			return
+		}
+		c.Writer.Header().Set("X-User", u.Name)
+		c.WriteJSON(u, http.StatusCreated)
+	}, web.HandlerOpt{Name: "createUser"})
+	g.GET("/login", func(c *web.Context) {
+		http.SetCookie(c.Writer, &http.Cookie{Name: "sid", Value: "s1", Path: "/"})
+	})
+	g.GET("/me", func(c *web.Context) {
+		ck, err := c.Request.Cookie("sid")
+		if err != nil {
+			c.Writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		c.WriteString(ck.Value + " " + c.Request.Header.Get("X-Tenant") + " " + c.Request.URL.Query().Get("q"))
+	})
+	g.Host("#tenant.example.org").GET("/files/#...", func(c *web.Context) {})
+	return g
+}
+
+func TestDoAndExpect(t *testing.T) {
+	c := New(t, newEngine())
+	c.POST("/users/7").JSON(map[string]string{"name": "alice"}).Do().
+		ExpectStatus(http.StatusCreated).
+		ExpectHeader("X-User", "alice").
+		ExpectHeaderContains("Content-Type", "application/json").
+		ExpectJSON(`{"name": "alice", "id": 7}`).
+		ExpectJSON(user{ID: 7, Name: "alice"})
+
+	c.POST("/users/8").Form(url.Values{"name": {"bob"}}).Do().
+		ExpectStatus(http.StatusCreated).
+		ExpectJSON(user{ID: 8, Name: "bob"})
+
+	var got map[string]string
+	c.POST("/users/9").Encode(web.XMLCodec, user{}).Do().
+		ExpectStatus(http.StatusBadRequest).
+		DecodeJSON(&got)
+	if !strings.Contains(got["error"], "required") {
+		t.Fatalf("error = %q", got["error"])
+	}
+}
+
+func TestCookieJarAndDefaults(t *testing.T) {
+	c := New(t, newEngine()).SetHeader("X-Tenant", "t1")
+	c.GET("/me").Do().ExpectStatus(http.StatusUnauthorized)
+	c.GET("/login").Do().ExpectStatus(http.StatusOK)
+	c.GET("/me").Query("q", "x y").Do().ExpectBody("s1 t1 x y")
+	// 不同主机不回传 cookie
+	c.GET("http://other.test/me").Do().ExpectStatus(http.StatusUnauthorized)
+}
+
+func TestExpectFailuresAreReported(t *testing.T) {
+	rec := &recordingTB{TB: t}
+	c := New(rec, newEngine())
+	c.GET("/login").Do().
+		ExpectStatus(http.StatusTeapot).
+		ExpectHeader("X-Missing", "v").
+		ExpectBody("nope")
+	if len(rec.errors) != 3 {
+		t.Fatalf("errors = %q, want 3", rec.errors)
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestExpectRoutes(t *testing.T) {
+	g := newEngine()
+	c := New(t, g)
+	c.ExpectRoutes(
+		RouteCase{Method: "POST", Path: "/users/12", Pattern: "/users/#id:int", Params: map[string]string{"id": "12"}},
+		RouteCase{Method: "POST", Path: "/users/abc"},
+		RouteCase{Path: "/login", Pattern: "/login", Params: map[string]string{}},
+		RouteCase{Method: "HEAD", Path: "/login", Pattern: "/login"},
+		RouteCase{
+			Host: "acme.example.org:8080", Path: "/files/a/b",
+			Pattern:    "/files/#...",
+			Params:     map[string]string{"#": "a/b"},
+			HostParams: map[string]string{"tenant": "acme"},
+		},
+		RouteCase{Host: "acme.example.org", Path: "/login"},
+	)
+
+	m, ok := c.GET("/users/1").Match()
+	if ok || strings.Join(m.Allowed, ",") != "POST" {
+		t.Fatalf("Match() = %+v, %v; want method mismatch with Allowed [POST]", m, ok)
+	}
+	m, ok = c.POST("/users/1").Match()
+	if !ok || m.Option.Name != "createUser" || m.Group == nil {
+		t.Fatalf("Match() = %+v, %v", m, ok)
+	}
+}
+
+func TestStreamSSE(t *testing.T) {
+	g := web.New()
+	broker := sse.NewBroker(sse.BrokerOption{PingInterval: 20 * time.Millisecond})
+	t.Cleanup(broker.Close)
+	g.GET("/events", broker.Handler("news"))
+	g.GET("/once", func(c *web.Context) {
+		conn, err := sse.NewConn(c)
+		if err != nil {
+			return
+		}
+		conn.SendEvent(sse.Event{Event: "greet", Data: "a\nb", Retry: time.Second})
+	})
+
+	c := New(t, g)
+	s := c.GET("/once").Stream()
+	if s.StatusCode() != http.StatusOK || !strings.HasPrefix(s.Header().Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("status %d header %v", s.StatusCode(), s.Header())
+	}
+	s.ExpectEvent(sse.Event{Event: "greet", Data: "a\nb", Retry: time.Second})
+	s.ExpectEnd()
+
+	s = c.GET("/events").Header("Last-Event-ID", "0").Stream()
+	for broker.Subscribers("news") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	broker.Publish("news", sse.Event{Data: "first"})
+	broker.Publish("news", sse.Event{Data: "second"})
+	s.ExpectData("first")
+	if ev := s.ExpectData("second"); ev.ID != "2" {
+		t.Fatalf("ID = %q, want 2", ev.ID)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(s.Comments()) == 0 {
+		t.Fatal("no keepalive comments received")
+	}
+	s.Close()
+	if !s.Done() || broker.Subscribers("news") != 0 {
+		t.Fatal("handler still running after Close")
+	}
+}
@@ -0,0 +1,107 @@
+package ws
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"strings"
+	"sync"
+)
+
+const defaultCompressionLevel = 1
+
+// 每条消息结尾被去掉的同步标记，解压时补回并追加一个空的 final 块
+const deflateTail = "\x00\x00\xff\xff\x01\x00\x00\xff\xff"
+
+var (
+	// 按级别 -2 ~ 9 分池，flate.Writer.Reset 不能改级别
+	flateWriterPools [12]sync.Pool
+	flateReaderPool  = sync.Pool{New: func() any { return flate.NewReader(nil) }}
+)
+
+func validCompressionLevel(level int) bool {
+	return level >= flate.HuffmanOnly && level <= flate.BestCompression
+}
+
+// compressData 每条消息独立压缩（no_context_takeover），并去掉结尾的 0x00 0x00 0xff 0xff。
+func compressData(data []byte, level int) ([]byte, error) {
+	pool := &flateWriterPools[level-flate.HuffmanOnly]
+	var buf bytes.Buffer
+	fw, _ := pool.Get().(*flate.Writer)
+	if fw == nil {
+		var err error
+		if fw, err = flate.NewWriter(&buf, level); err != nil {
+			return nil, err
+		}
+	} else {
+		fw.Reset(&buf)
+	}
+	defer pool.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	return out[:len(out)-4], nil
+}
+
+// decompressData 解压一条完整消息；limit > 0 时超限返回 1009。
+func decompressData(data []byte, limit int64) ([]byte, error) {
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(fr)
+	src := io.MultiReader(bytes.NewReader(data), strings.NewReader(deflateTail))
+	if err := fr.(flate.Resetter).Reset(src, nil); err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = fr
+	if limit > 0 {
+		r = io.LimitReader(fr, limit+1)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &protocolError{CloseInvalidFramePayloadData, "invalid deflate data"}
+	}
+	if limit > 0 && int64(len(out)) > limit {
+		return nil, &protocolError{CloseMessageTooBig, "message too big"}
+	}
+	return out, nil
+}
+
+// negotiateDeflate 从 Sec-WebSocket-Extensions 中挑选可接受的 permessage-deflate 报价。
+// 只支持双方 no_context_takeover、窗口 15 位，返回应答给客户端的扩展串。
+func negotiateDeflate(header []string) (string, bool) {
+	for _, value := range header {
+		for _, offer := range strings.Split(value, ",") {
+			params := strings.Split(offer, ";")
+			if strings.TrimSpace(params[0]) != "permessage-deflate" {
+				continue
+			}
+			if acceptDeflateParams(params[1:]) {
+				return "permessage-deflate; server_no_context_takeover; client_no_context_takeover", true
+			}
+		}
+	}
+	return "", false
+}
+
+func acceptDeflateParams(params []string) bool {
+	for _, p := range params {
+		name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.TrimSpace(name) {
+		case "server_no_context_takeover", "client_no_context_takeover", "client_max_window_bits":
+		case "server_max_window_bits":
+			// compress/flate 固定 32KB 窗口
+			if value != "15" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
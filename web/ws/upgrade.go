@@ -0,0 +1,187 @@
+package ws
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+type UpgradeOption struct {
+	// Subprotocols 服务端支持的子协议，按客户端声明顺序取第一个命中项。
+	Subprotocols []string
+	// CheckOrigin 校验 Origin；nil 时要求 Origin 缺失或与 Host 同源。
+	CheckOrigin func(r *http.Request) bool
+	// EnableCompression 客户端提供 permessage-deflate 时启用压缩。
+	EnableCompression bool
+	// ReadLimit 单条消息最大字节数；0 走默认 32MB，<0 不限。
+	ReadLimit int64
+	// WriteQueueSize 写队列长度；0 走默认 16。
+	WriteQueueSize int
+	// HandshakeTimeout 写出 101 响应的超时；0 不限。
+	HandshakeTimeout time.Duration
+}
+
+const (
+	defaultReadLimit      = 32 << 20
+	defaultWriteQueueSize = 16
+)
+
+// Upgrade 将请求升级为 WebSocket 连接。
+// 握手失败时已写出对应的 HTTP 错误响应（400/403/405/426），返回包装 ErrBadHandshake 的错误。
+// c.Writer 上已设置的响应头（如 Set-Cookie、X-Request-ID）会随 101 一并发出。
+// 连接登记到 GOweb 的关停跟踪，Shutdown 时以 1001 关闭。
+func Upgrade(c *web.Context, opts ...UpgradeOption) (*Conn, error) {
+	opt := UpgradeOption{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	r := c.Request
+
+	if r.Method != http.MethodGet {
+		return nil, handshakeError(c, http.StatusMethodNotAllowed, "method not GET")
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") {
+		return nil, handshakeError(c, http.StatusBadRequest, "missing Connection: upgrade")
+	}
+	if !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, handshakeError(c, http.StatusBadRequest, "missing Upgrade: websocket")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		c.Writer.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, handshakeError(c, http.StatusUpgradeRequired, "unsupported version")
+	}
+	checkOrigin := opt.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+	if !checkOrigin(r) {
+		return nil, handshakeError(c, http.StatusForbidden, "origin not allowed")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		return nil, handshakeError(c, http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+
+	subprotocol := selectSubprotocol(r.Header, opt.Subprotocols)
+	var extension string
+	var compress bool
+	if opt.EnableCompression {
+		extension, compress = negotiateDeflate(r.Header.Values("Sec-WebSocket-Extensions"))
+	}
+
+	netConn, brw, err := http.NewResponseController(c.Writer).Hijack()
+	if err != nil {
+		return nil, handshakeError(c, http.StatusInternalServerError, err.Error())
+	}
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: ")
+	b.WriteString(acceptKey(key))
+	b.WriteString("\r\n")
+	if subprotocol != "" {
+		b.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if extension != "" {
+		b.WriteString("Sec-WebSocket-Extensions: " + extension + "\r\n")
+	}
+	for k, vs := range c.Writer.Header() {
+		switch http.CanonicalHeaderKey(k) {
+		case "Upgrade", "Connection", "Sec-Websocket-Accept", "Sec-Websocket-Protocol",
+			"Sec-Websocket-Extensions", "Content-Length", "Content-Type":
+			continue
+		}
+		for _, v := range vs {
+			b.WriteString(k + ": " + strings.NewReplacer("\r", "", "\n", "").Replace(v) + "\r\n")
+		}
+	}
+	b.WriteString("\r\n")
+
+	// 清掉 http.Server 设置的读写超时，后续由 Conn 自行管理
+	netConn.SetDeadline(time.Time{})
+	if opt.HandshakeTimeout > 0 {
+		netConn.SetWriteDeadline(time.Now().Add(opt.HandshakeTimeout))
+	}
+	if _, err := netConn.Write([]byte(b.String())); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	netConn.SetWriteDeadline(time.Time{})
+
+	queueSize := opt.WriteQueueSize
+	if queueSize <= 0 {
+		queueSize = defaultWriteQueueSize
+	}
+	conn := newConn(netConn, brw.Reader, true, compress, queueSize)
+	conn.subprotocol = subprotocol
+	// GOweb 优雅关停时以 1001 发起关闭握手
+	conn.setUntrack(c.TrackConn(func() { conn.CloseWithCode(CloseGoingAway, "server shutdown") }))
+	switch {
+	case opt.ReadLimit == 0:
+		conn.readLimit = defaultReadLimit
+	case opt.ReadLimit > 0:
+		conn.readLimit = opt.ReadLimit
+	}
+	return conn, nil
+}
+
+// IsWebSocketUpgrade 判断请求是否为 WebSocket 升级请求。
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+func handshakeError(c *web.Context, status int, reason string) error {
+	http.Error(c.Writer, http.StatusText(status), status)
+	return fmt.Errorf("%w: %s", ErrBadHandshake, reason)
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func selectSubprotocol(h http.Header, supported []string) string {
+	for _, value := range h.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(value, ",") {
+			p = strings.TrimSpace(p)
+			for _, s := range supported {
+				if p == s {
+					return s
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
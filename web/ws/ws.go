@@ -0,0 +1,603 @@
+package ws
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// 消息类型，取值即 RFC 6455 opcode。
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+
+	continuationFrame = 0
+)
+
+// 关闭状态码，见 RFC 6455 7.4.1。
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseMandatoryExtension      = 1010
+	CloseInternalServerErr       = 1011
+)
+
+const (
+	maxControlPayload = 125
+	// closeTimeout 发出关闭帧后等待对端回应的最长时间
+	closeTimeout = 5 * time.Second
+	// pongTimeout 自动回复 pong 时的写超时
+	pongTimeout = time.Second
+)
+
+var (
+	ErrBadHandshake = errors.New("ws: bad handshake")
+	// ErrCloseSent 已发出关闭帧后再写数据。
+	ErrCloseSent = errors.New("ws: close sent")
+	ErrClosed    = errors.New("ws: use of closed connection")
+)
+
+// CloseError 对端关闭或协议错误导致的关闭，Code 为关闭状态码。
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	s := "ws: close " + strconv.Itoa(e.Code)
+	if e.Text != "" {
+		s += " " + e.Text
+	}
+	return s
+}
+
+// IsCloseError 判断 err 是否为指定状态码之一的 *CloseError；codes 为空时只判断类型。
+func IsCloseError(err error, codes ...int) bool {
+	var ce *CloseError
+	if !errors.As(err, &ce) {
+		return false
+	}
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if ce.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Conn 是升级后的 WebSocket 连接。
+//
+// ReadMessage 只允许一个 goroutine 调用；WriteMessage / WriteControl / Close 可并发调用，
+// 写请求经由每连接的写队列串行发出，控制帧优先于数据帧。
+// Conn 不持有 web.Context，handler 返回后仍可继续使用。
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	isServer    bool
+	subprotocol string
+
+	// compress 是否协商了 permessage-deflate
+	compress      bool
+	writeCompress atomic.Bool
+	compressLevel atomic.Int32
+
+	readLimit   int64
+	readErr     error
+	pingHandler func(appData string) error
+	pongHandler func(appData string) error
+
+	// writeDeadline UnixNano，0 表示不限
+	writeDeadline atomic.Int64
+	queue         chan *writeRequest
+	control       chan *writeRequest
+	closeSent     atomic.Bool
+	closeReceived atomic.Bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+	// untrack 注销 GOweb 关停跟踪
+	untrack atomic.Pointer[func()]
+}
+
+type writeRequest struct {
+	op   int
+	data []byte
+	err  chan error
+}
+
+func newConn(conn net.Conn, br *bufio.Reader, isServer, compress bool, queueSize int) *Conn {
+	if br == nil {
+		br = bufio.NewReader(conn)
+	}
+	c := &Conn{
+		conn:     conn,
+		br:       br,
+		isServer: isServer,
+		compress: compress,
+		queue:    make(chan *writeRequest, queueSize),
+		control:  make(chan *writeRequest, 4),
+		done:     make(chan struct{}),
+	}
+	c.writeCompress.Store(compress)
+	c.compressLevel.Store(defaultCompressionLevel)
+	c.pingHandler = c.defaultPingHandler
+	go c.writeLoop()
+	return c
+}
+
+// Subprotocol 返回握手协商出的子协议。
+func (c *Conn) Subprotocol() string { return c.subprotocol }
+
+func (c *Conn) LocalAddr() net.Addr  { return c.conn.LocalAddr() }
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetReadLimit 限制单条消息（解压后）的最大字节数，超限以 1009 关闭连接；<=0 不限。
+func (c *Conn) SetReadLimit(limit int64) { c.readLimit = limit }
+
+func (c *Conn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetWriteDeadline 同时约束排队等待与实际写出；零值表示不限。
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	if t.IsZero() {
+		c.writeDeadline.Store(0)
+	} else {
+		c.writeDeadline.Store(t.UnixNano())
+	}
+	return nil
+}
+
+// SetPingHandler 设置收到 ping 时的回调，在 ReadMessage 所在 goroutine 中调用；nil 恢复默认（回复 pong）。
+func (c *Conn) SetPingHandler(h func(appData string) error) {
+	if h == nil {
+		h = c.defaultPingHandler
+	}
+	c.pingHandler = h
+}
+
+// SetPongHandler 设置收到 pong 时的回调，常用于延长读超时。
+func (c *Conn) SetPongHandler(h func(appData string) error) { c.pongHandler = h }
+
+// EnableWriteCompression 开关后续消息的压缩；未协商 permessage-deflate 时无效。
+func (c *Conn) EnableWriteCompression(enable bool) { c.writeCompress.Store(enable && c.compress) }
+
+// SetCompressionLevel 设置 flate 压缩级别（-2 ~ 9）。
+func (c *Conn) SetCompressionLevel(level int) error {
+	if !validCompressionLevel(level) {
+		return errors.New("ws: invalid compression level")
+	}
+	c.compressLevel.Store(int32(level))
+	return nil
+}
+
+func (c *Conn) defaultPingHandler(appData string) error {
+	err := c.WriteControl(PongMessage, []byte(appData), time.Now().Add(pongTimeout))
+	if errors.Is(err, ErrCloseSent) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return nil
+	}
+	return err
+}
+
+// WriteMessage 将一条完整消息放入写队列并等待写出。
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return errors.New("ws: invalid message type " + strconv.Itoa(messageType))
+	}
+	return c.enqueue(c.queue, &writeRequest{op: messageType, data: data}, c.deadline())
+}
+
+// WriteControl 发送 ping / pong / close 控制帧，插队于数据帧之前。
+func (c *Conn) WriteControl(messageType int, data []byte, deadline time.Time) error {
+	if messageType != PingMessage && messageType != PongMessage && messageType != CloseMessage {
+		return errors.New("ws: invalid control type " + strconv.Itoa(messageType))
+	}
+	if len(data) > maxControlPayload {
+		return errors.New("ws: control payload too large")
+	}
+	return c.enqueue(c.control, &writeRequest{op: messageType, data: data}, deadline)
+}
+
+// CloseWithCode 发起关闭握手：发送关闭帧后继续由 ReadMessage 读到对端的关闭帧再断开，
+// 若 5 秒内未完成握手则强制断开。
+func (c *Conn) CloseWithCode(code int, text string) error {
+	err := c.WriteControl(CloseMessage, FormatCloseMessage(code, text), time.Now().Add(closeTimeout))
+	if c.closeReceived.Load() {
+		c.closeNet()
+		return err
+	}
+	timer := time.AfterFunc(closeTimeout, func() { c.closeNet() })
+	go func() {
+		<-c.done
+		timer.Stop()
+	}()
+	return err
+}
+
+// Close 尽力发送 1000 关闭帧后立即断开底层连接；需要完整握手时使用 CloseWithCode。
+func (c *Conn) Close() error {
+	if !c.closeSent.Load() {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(CloseNormalClosure, ""), time.Now().Add(time.Second))
+	}
+	return c.closeNet()
+}
+
+// FormatCloseMessage 构造关闭帧负载；CloseNoStatusReceived 表示不带状态码。
+func FormatCloseMessage(code int, text string) []byte {
+	if code == CloseNoStatusReceived {
+		return []byte{}
+	}
+	buf := make([]byte, 2+len(text))
+	binary.BigEndian.PutUint16(buf, uint16(code))
+	copy(buf[2:], text)
+	return buf
+}
+
+func (c *Conn) closeNet() error {
+	err := ErrClosed
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+		if untrack := c.untrack.Load(); untrack != nil {
+			(*untrack)()
+		}
+	})
+	return err
+}
+
+// setUntrack 登记注销函数；连接已关闭则立即注销。
+func (c *Conn) setUntrack(untrack func()) {
+	c.untrack.Store(&untrack)
+	select {
+	case <-c.done:
+		untrack()
+	default:
+	}
+}
+
+func (c *Conn) deadline() time.Time {
+	if d := c.writeDeadline.Load(); d != 0 {
+		return time.Unix(0, d)
+	}
+	return time.Time{}
+}
+
+func (c *Conn) enqueue(ch chan *writeRequest, req *writeRequest, deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	req.err = make(chan error, 1)
+	select {
+	case ch <- req:
+	case <-c.done:
+		return ErrClosed
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	}
+	select {
+	case err := <-req.err:
+		return err
+	case <-c.done:
+		return ErrClosed
+	}
+}
+
+func (c *Conn) writeLoop() {
+	for {
+		var req *writeRequest
+		select {
+		case req = <-c.control:
+		default:
+			select {
+			case req = <-c.control:
+			case req = <-c.queue:
+			case <-c.done:
+				return
+			}
+		}
+		if c.closeSent.Load() {
+			req.err <- ErrCloseSent
+			continue
+		}
+		err := c.writeFrame(req.op, req.data)
+		if err == nil && req.op == CloseMessage {
+			c.closeSent.Store(true)
+		}
+		req.err <- err
+	}
+}
+
+func (c *Conn) writeFrame(op int, data []byte) error {
+	b0 := byte(op) | 0x80
+	if (op == TextMessage || op == BinaryMessage) && c.writeCompress.Load() {
+		var err error
+		if data, err = compressData(data, int(c.compressLevel.Load())); err != nil {
+			return err
+		}
+		b0 |= 0x40
+	}
+
+	buf := make([]byte, 0, 14+len(data))
+	buf = append(buf, b0)
+	var b1 byte
+	if !c.isServer {
+		b1 = 0x80
+	}
+	switch n := len(data); {
+	case n <= 125:
+		buf = append(buf, b1|byte(n))
+	case n <= 0xffff:
+		buf = append(buf, b1|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, b1|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	if c.isServer {
+		buf = append(buf, data...)
+	} else {
+		// 客户端必须加掩码
+		var key [4]byte
+		if _, err := rand.Read(key[:]); err != nil {
+			return err
+		}
+		buf = append(buf, key[:]...)
+		start := len(buf)
+		buf = append(buf, data...)
+		maskBytes(key, buf[start:])
+	}
+
+	if err := c.conn.SetWriteDeadline(c.deadline()); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+type frameHeader struct {
+	fin    bool
+	rsv1   bool
+	op     int
+	length int64
+	mask   [4]byte
+}
+
+// protocolError 读到违反协议的帧，以 code 关闭连接。
+type protocolError struct {
+	code int
+	msg  string
+}
+
+func (e *protocolError) Error() string { return "ws: " + e.msg }
+
+func (c *Conn) readHeader() (h frameHeader, err error) {
+	var b [8]byte
+	if _, err = io.ReadFull(c.br, b[:2]); err != nil {
+		return
+	}
+	h.fin = b[0]&0x80 != 0
+	h.rsv1 = b[0]&0x40 != 0
+	h.op = int(b[0] & 0x0f)
+	masked := b[1]&0x80 != 0
+	h.length = int64(b[1] & 0x7f)
+
+	if b[0]&0x30 != 0 {
+		return h, &protocolError{CloseProtocolError, "unexpected reserved bits"}
+	}
+	switch h.op {
+	case continuationFrame, TextMessage, BinaryMessage:
+		if h.rsv1 && (!c.compress || h.op == continuationFrame) {
+			return h, &protocolError{CloseProtocolError, "unexpected rsv1"}
+		}
+	case CloseMessage, PingMessage, PongMessage:
+		if h.rsv1 || !h.fin || h.length > maxControlPayload {
+			return h, &protocolError{CloseProtocolError, "invalid control frame"}
+		}
+	default:
+		return h, &protocolError{CloseProtocolError, "unknown opcode " + strconv.Itoa(h.op)}
+	}
+	if masked != c.isServer {
+		return h, &protocolError{CloseProtocolError, "bad mask bit"}
+	}
+
+	switch h.length {
+	case 126:
+		if _, err = io.ReadFull(c.br, b[:2]); err != nil {
+			return
+		}
+		h.length = int64(binary.BigEndian.Uint16(b[:2]))
+	case 127:
+		if _, err = io.ReadFull(c.br, b[:8]); err != nil {
+			return
+		}
+		if b[0]&0x80 != 0 {
+			return h, &protocolError{CloseProtocolError, "invalid payload length"}
+		}
+		h.length = int64(binary.BigEndian.Uint64(b[:8]))
+	}
+	if masked {
+		_, err = io.ReadFull(c.br, h.mask[:])
+	}
+	return
+}
+
+// ReadMessage 读取下一条完整消息，分片会被拼接、控制帧在内部处理。
+// 对端关闭返回 *CloseError；任何错误之后连接不可再读。
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	if c.readErr != nil {
+		return 0, nil, c.readErr
+	}
+	messageType, data, err = c.readMessage()
+	if err != nil {
+		c.readErr = c.handleReadError(err)
+		return 0, nil, c.readErr
+	}
+	return
+}
+
+func (c *Conn) readMessage() (int, []byte, error) {
+	var (
+		messageType int
+		compressed  bool
+		data        []byte
+	)
+	for {
+		h, err := c.readHeader()
+		if err != nil {
+			return 0, nil, err
+		}
+		if h.op >= CloseMessage {
+			payload, err := c.readPayload(h)
+			if err != nil {
+				return 0, nil, err
+			}
+			if err := c.handleControl(h.op, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+
+		if h.op == continuationFrame {
+			if messageType == 0 {
+				return 0, nil, &protocolError{CloseProtocolError, "unexpected continuation frame"}
+			}
+		} else {
+			if messageType != 0 {
+				return 0, nil, &protocolError{CloseProtocolError, "expected continuation frame"}
+			}
+			messageType = h.op
+			compressed = h.rsv1
+		}
+		if c.readLimit > 0 && int64(len(data))+h.length > c.readLimit {
+			return 0, nil, &protocolError{CloseMessageTooBig, "message too big"}
+		}
+		payload, err := c.readPayload(h)
+		if err != nil {
+			return 0, nil, err
+		}
+		data = append(data, payload...)
+		if h.fin {
+			break
+		}
+	}
+
+	if compressed {
+		var err error
+		if data, err = decompressData(data, c.readLimit); err != nil {
+			return 0, nil, err
+		}
+	}
+	if messageType == TextMessage && !utf8.Valid(data) {
+		return 0, nil, &protocolError{CloseInvalidFramePayloadData, "invalid utf8"}
+	}
+	return messageType, data, nil
+}
+
+func (c *Conn) readPayload(h frameHeader) ([]byte, error) {
+	payload := make([]byte, h.length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if c.isServer {
+		maskBytes(h.mask, payload)
+	}
+	return payload, nil
+}
+
+func (c *Conn) handleControl(op int, payload []byte) error {
+	switch op {
+	case PingMessage:
+		return c.pingHandler(string(payload))
+	case PongMessage:
+		if c.pongHandler != nil {
+			return c.pongHandler(string(payload))
+		}
+		return nil
+	}
+
+	// 关闭帧
+	ce := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return &protocolError{CloseProtocolError, "invalid close payload"}
+	case len(payload) >= 2:
+		ce.Code = int(binary.BigEndian.Uint16(payload))
+		ce.Text = string(payload[2:])
+		if !validCloseCode(ce.Code) {
+			return &protocolError{CloseProtocolError, "invalid close code " + strconv.Itoa(ce.Code)}
+		}
+		if !utf8.ValidString(ce.Text) {
+			return &protocolError{CloseInvalidFramePayloadData, "invalid utf8 close reason"}
+		}
+	}
+	c.closeReceived.Store(true)
+	if !c.closeSent.Load() {
+		_ = c.WriteControl(CloseMessage, FormatCloseMessage(ce.Code, ""), time.Now().Add(time.Second))
+	}
+	return ce
+}
+
+func (c *Conn) handleReadError(err error) error {
+	var pe *protocolError
+	switch {
+	case errors.As(err, &pe):
+		if !c.closeSent.Load() {
+			_ = c.WriteControl(CloseMessage, FormatCloseMessage(pe.code, pe.msg), time.Now().Add(time.Second))
+		}
+		c.closeNet()
+		return &CloseError{Code: pe.code, Text: pe.msg}
+	case IsCloseError(err):
+		c.closeNet()
+		return err
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		c.closeNet()
+		return &CloseError{Code: CloseAbnormalClosure, Text: err.Error()}
+	}
+	return err
+}
+
+func validCloseCode(code int) bool {
+	switch {
+	case code >= 1000 && code <= 1003, code >= 1007 && code <= 1011:
+		return true
+	case code >= 3000 && code <= 4999:
+		return true
+	}
+	return false
+}
+
+func maskBytes(key [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= key[i&3]
+	}
+}
@@ -0,0 +1,259 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Rehtt/Kit/web"
+)
+
+// dial 手写客户端握手，返回客户端侧 Conn 与 101 响应。
+func dial(t *testing.T, srv *httptest.Server, header http.Header) (*Conn, *http.Response) {
+	t.Helper()
+	nc, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("GET", srv.URL+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if err := req.Write(nc); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(nc)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		nc.Close()
+		return nil, resp
+	}
+	compress := strings.Contains(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+	c := newConn(nc, br, false, compress, 4)
+	t.Cleanup(func() { c.Close() })
+	return c, resp
+}
+
+func echoServer(t *testing.T, opt UpgradeOption) *httptest.Server {
+	_, srv := echoEngine(t, opt)
+	return srv
+}
+
+func echoEngine(t *testing.T, opt UpgradeOption) (*web.GOweb, *httptest.Server) {
+	g := web.New()
+	g.GET("/ws", func(c *web.Context) {
+		conn, err := Upgrade(c, opt)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			ty, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(ty, data); err != nil {
+				return
+			}
+		}
+	})
+	srv := httptest.NewServer(g)
+	t.Cleanup(srv.Close)
+	return g, srv
+}
+
+func TestEcho(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{Subprotocols: []string{"chat"}})
+	c, resp := dial(t, srv, http.Header{"Sec-Websocket-Protocol": {"foo, chat"}})
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("accept = %q", got)
+	}
+	if resp.Header.Get("Sec-WebSocket-Protocol") != "chat" {
+		t.Fatalf("protocol = %q", resp.Header.Get("Sec-WebSocket-Protocol"))
+	}
+
+	big := bytes.Repeat([]byte("x"), 70000)
+	for _, msg := range []struct {
+		ty   int
+		data []byte
+	}{{TextMessage, []byte("hello")}, {BinaryMessage, big}} {
+		if err := c.WriteMessage(msg.ty, msg.data); err != nil {
+			t.Fatal(err)
+		}
+		ty, data, err := c.ReadMessage()
+		if err != nil || ty != msg.ty || !bytes.Equal(data, msg.data) {
+			t.Fatalf("ty=%d len=%d err=%v", ty, len(data), err)
+		}
+	}
+}
+
+func TestFragmentationAndPing(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{})
+	c, _ := dial(t, srv, nil)
+
+	var pong string
+	c.SetPongHandler(func(data string) error { pong = data; return nil })
+
+	// 分片消息中间插入 ping，服务端应先回 pong 再回拼接后的消息。
+	writeRaw(t, c, TextMessage, false, "hel")
+	writeRaw(t, c, PingMessage, true, "p1")
+	writeRaw(t, c, continuationFrame, false, "lo ")
+	writeRaw(t, c, continuationFrame, true, "world")
+
+	ty, data, err := c.ReadMessage()
+	if err != nil || ty != TextMessage || string(data) != "hello world" {
+		t.Fatalf("ty=%d data=%q err=%v", ty, data, err)
+	}
+	if pong != "p1" {
+		t.Fatalf("pong = %q", pong)
+	}
+}
+
+func TestCompression(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{EnableCompression: true})
+	c, resp := dial(t, srv, http.Header{"Sec-Websocket-Extensions": {"permessage-deflate; client_max_window_bits"}})
+	if !c.compress {
+		t.Fatalf("extensions = %q", resp.Header.Get("Sec-WebSocket-Extensions"))
+	}
+	msg := strings.Repeat("compress me ", 1000)
+	for i := 0; i < 2; i++ {
+		if err := c.WriteMessage(TextMessage, []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+		_, data, err := c.ReadMessage()
+		if err != nil || string(data) != msg {
+			t.Fatalf("len=%d err=%v", len(data), err)
+		}
+	}
+}
+
+func TestCloseHandshake(t *testing.T) {
+	got := make(chan error, 1)
+	g := web.New()
+	g.GET("/ws", func(c *web.Context) {
+		conn, err := Upgrade(c)
+		if err != nil {
+			return
+		}
+		_, _, err = conn.ReadMessage()
+		got <- err
+	})
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	c, _ := dial(t, srv, nil)
+	if err := c.CloseWithCode(CloseGoingAway, "bye"); err != nil {
+		t.Fatal(err)
+	}
+	// 客户端读到服务端回显的关闭帧。
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+		t.Fatalf("client err = %v", err)
+	}
+	var ce *CloseError
+	if err := <-got; !errors.As(err, &ce) || ce.Code != CloseGoingAway || ce.Text != "bye" {
+		t.Fatalf("server err = %v", err)
+	}
+	if err := c.WriteMessage(TextMessage, []byte("x")); err == nil {
+		t.Fatal("write after close should fail")
+	}
+}
+
+func TestShutdownClosesConn(t *testing.T) {
+	g, srv := echoEngine(t, UpgradeOption{})
+	c, _ := dial(t, srv, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- g.Shutdown(ctx) }()
+
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseGoingAway) {
+		t.Fatalf("client err = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{ReadLimit: 10})
+
+	c, _ := dial(t, srv, nil)
+	c.WriteMessage(TextMessage, bytes.Repeat([]byte("a"), 11))
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseMessageTooBig) {
+		t.Fatalf("err = %v", err)
+	}
+
+	c, _ = dial(t, srv, nil)
+	writeRaw(t, c, TextMessage, true, "\xff\xfe")
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseInvalidFramePayloadData) {
+		t.Fatalf("err = %v", err)
+	}
+
+	c, _ = dial(t, srv, nil)
+	writeRaw(t, c, continuationFrame, true, "x")
+	if _, _, err := c.ReadMessage(); !IsCloseError(err, CloseProtocolError) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestWriteDeadline(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{})
+	c, _ := dial(t, srv, nil)
+	c.SetWriteDeadline(time.Now().Add(-time.Second))
+	if err := c.WriteMessage(TextMessage, []byte("x")); err == nil {
+		t.Fatal("expected deadline error")
+	}
+}
+
+func TestBadHandshake(t *testing.T) {
+	srv := echoServer(t, UpgradeOption{})
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("code = %d", resp.StatusCode)
+	}
+
+	if _, resp := dial(t, srv, http.Header{"Sec-Websocket-Version": {"8"}}); resp.StatusCode != http.StatusUpgradeRequired {
+		t.Fatalf("code = %d", resp.StatusCode)
+	}
+	if _, resp := dial(t, srv, http.Header{"Origin": {"http://evil.com"}}); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("code = %d", resp.StatusCode)
+	}
+}
+
+// writeRaw 绕过写队列直接写一帧（客户端掩码），用于构造分片与异常帧。
+func writeRaw(t *testing.T, c *Conn, op int, fin bool, payload string) {
+	t.Helper()
+	b0 := byte(op)
+	if fin {
+		b0 |= 0x80
+	}
+	key := [4]byte{1, 2, 3, 4}
+	buf := []byte{b0, 0x80 | byte(len(payload))}
+	buf = append(buf, key[:]...)
+	data := []byte(payload)
+	maskBytes(key, data)
+	buf = append(buf, data...)
+	if _, err := c.conn.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+}